@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"happy-place-2/internal/game"
 	"happy-place-2/internal/maps"
 )
 
@@ -20,11 +22,15 @@ func main() {
 
 	switch cmd {
 	case "validate":
-		if len(args) != 1 {
-			fmt.Fprintln(os.Stderr, "Usage: maptools validate <maps-dir>")
+		if len(args) < 1 || len(args) > 2 {
+			fmt.Fprintln(os.Stderr, "Usage: maptools validate <maps-dir> [enemies-dir]")
 			os.Exit(1)
 		}
-		os.Exit(runValidate(args[0]))
+		enemiesDir := defaultEnemiesDir(args[0])
+		if len(args) == 2 {
+			enemiesDir = args[1]
+		}
+		os.Exit(runValidate(args[0], enemiesDir))
 	case "viz":
 		if len(args) != 1 {
 			fmt.Fprintln(os.Stderr, "Usage: maptools viz <map-file>")
@@ -37,6 +43,12 @@ func main() {
 			os.Exit(1)
 		}
 		runStats(args[0])
+	case "encounters":
+		if len(args) != 1 {
+			fmt.Fprintln(os.Stderr, "Usage: maptools encounters <map-file>")
+			os.Exit(1)
+		}
+		runEncounters(args[0])
 	case "all":
 		if len(args) != 1 {
 			fmt.Fprintln(os.Stderr, "Usage: maptools all <maps-dir>")
@@ -54,15 +66,18 @@ func printUsage() {
 	fmt.Fprintln(os.Stderr, `Usage: maptools <command> <path>
 
 Commands:
-  validate <maps-dir>   Validate all maps in directory
+  validate <maps-dir>   Validate all maps in directory, plus the enemy
+                        catalog (default: enemies/ next to <maps-dir>)
+                        and the items/ and quests/ catalogs next to it
   viz      <map-file>   Render map as colored ASCII art
   stats    <map-file>   Show tile distribution and walkable %
+  encounters <map-file> Show which tiles can start a fight, per table
   all      <maps-dir>   Run validate + viz + stats for all maps`)
 }
 
 // --- validate ---
 
-func runValidate(dir string) int {
+func runValidate(dir, enemiesDir string) int {
 	allMaps, err := maps.LoadMaps(dir)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "FAIL: %v\n", err)
@@ -73,53 +88,25 @@ func runValidate(dir string) int {
 	for name, m := range allMaps {
 		fmt.Printf("Validating %q...\n", name)
 
-		// Check spawn is walkable
-		if !m.IsWalkable(m.SpawnX, m.SpawnY) {
-			fmt.Printf("  ERROR: spawn (%d,%d) is not walkable\n", m.SpawnX, m.SpawnY)
+		for _, err := range m.Validate(allMaps) {
+			fmt.Printf("  ERROR: %v\n", err)
 			errors++
 		}
 
-		// Check all tile indices are in legend range
-		for y, row := range m.Tiles {
-			for x, idx := range row {
-				if idx < 0 || idx >= len(m.Legend) {
-					fmt.Printf("  ERROR: tile (%d,%d) index %d out of legend range [0..%d]\n", x, y, idx, len(m.Legend)-1)
-					errors++
-				}
-			}
-		}
-
-		// Check portal positions are in bounds and walkable
-		for _, p := range m.Portals {
-			if p.X < 0 || p.X >= m.Width || p.Y < 0 || p.Y >= m.Height {
-				fmt.Printf("  ERROR: portal at (%d,%d) is out of bounds\n", p.X, p.Y)
-				errors++
-			}
-			// Check target position is walkable in target map
-			if tm, ok := allMaps[p.TargetMap]; ok {
-				if p.TargetX < 0 || p.TargetX >= tm.Width || p.TargetY < 0 || p.TargetY >= tm.Height {
-					fmt.Printf("  ERROR: portal at (%d,%d) targets out-of-bounds (%d,%d) in %q\n", p.X, p.Y, p.TargetX, p.TargetY, p.TargetMap)
-					errors++
-				} else if !tm.IsWalkable(p.TargetX, p.TargetY) {
-					fmt.Printf("  ERROR: portal at (%d,%d) targets non-walkable tile (%d,%d) in %q\n", p.X, p.Y, p.TargetX, p.TargetY, p.TargetMap)
-					errors++
-				}
-			}
-		}
-
-		// Check interaction positions
+		// Warn about interactions that are valid but probably mistakes
 		for _, inter := range m.Interactions {
-			if inter.X < 0 || inter.X >= m.Width || inter.Y < 0 || inter.Y >= m.Height {
-				fmt.Printf("  ERROR: interaction at (%d,%d) is out of bounds\n", inter.X, inter.Y)
-				errors++
-			}
 			if inter.Text == "" {
 				fmt.Printf("  WARN: interaction at (%d,%d) has empty text\n", inter.X, inter.Y)
 			}
+			switch inter.Type {
+			case maps.InteractionSign, maps.InteractionInn, maps.InteractionQuest:
+			default:
+				fmt.Printf("  WARN: interaction at (%d,%d) has unknown type %q (treated as a sign)\n", inter.X, inter.Y, inter.Type)
+			}
 		}
 
 		if errors == 0 {
-			fmt.Printf("  OK (%dx%d, %d portals, %d interactions)\n", m.Width, m.Height, len(m.Portals), len(m.Interactions))
+			fmt.Printf("  OK (%dx%d, %d portals, %d interactions, %d NPCs)\n", m.Width, m.Height, len(m.Portals), len(m.Interactions), len(m.NPCs))
 		}
 	}
 
@@ -128,6 +115,151 @@ func runValidate(dir string) int {
 		return 1
 	}
 	fmt.Printf("\nAll %d maps valid\n", len(allMaps))
+	enemies, code := validateEnemies(enemiesDir, allMaps)
+	if code != 0 {
+		return code
+	}
+	assetsDir := filepath.Dir(filepath.Clean(enemiesDir))
+	items, code := validateItems(filepath.Join(assetsDir, "items"), enemies, allMaps)
+	if code != 0 {
+		return code
+	}
+	return validateQuests(filepath.Join(assetsDir, "quests"), allMaps, enemies, items)
+}
+
+// defaultEnemiesDir returns the enemies directory that sits next to a maps
+// directory, e.g. assets/maps → assets/enemies.
+func defaultEnemiesDir(mapsDir string) string {
+	return filepath.Join(filepath.Dir(filepath.Clean(mapsDir)), "enemies")
+}
+
+// validateEnemies loads the enemy catalog, lists every entry, and checks
+// that map encounter tables only reference known enemies.
+func validateEnemies(dir string, allMaps map[string]*maps.Map) (map[string]game.EnemyDef, int) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		fmt.Printf("\nNo enemies directory at %s, skipping\n", dir)
+		return nil, 0
+	}
+
+	enemies, err := game.LoadEnemies(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "FAIL: %v\n", err)
+		return nil, 1
+	}
+
+	ids := make([]string, 0, len(enemies))
+	for id := range enemies {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	fmt.Println()
+	for _, id := range ids {
+		e := enemies[id]
+		fmt.Printf("Enemy %q (%s): HP %d  ATK %d  DEF %d  EXP %d  %s, %d frame(s)\n",
+			id, e.Name, e.MaxHP, e.Attack, e.Defense, e.EXP, e.Behavior, len(e.Art))
+	}
+
+	errors := 0
+	for name, m := range allMaps {
+		for i, t := range m.Encounters {
+			for _, e := range t.Enemies {
+				if _, ok := enemies[e.ID]; !ok {
+					fmt.Printf("  ERROR: %q encounter %d references unknown enemy %q\n", name, i, e.ID)
+					errors++
+				}
+			}
+		}
+	}
+	if errors > 0 {
+		fmt.Printf("\n%d error(s) found\n", errors)
+		return nil, 1
+	}
+	fmt.Printf("\nAll %d enemies valid\n", len(enemies))
+	return enemies, 0
+}
+
+// validateItems loads the item catalog, lists every entry, and checks that
+// enemy loot tables and breakable tiles only reference known items.
+func validateItems(dir string, enemies map[string]game.EnemyDef, allMaps map[string]*maps.Map) (map[string]game.ItemDef, int) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		fmt.Printf("\nNo items directory at %s, skipping\n", dir)
+		return nil, 0
+	}
+
+	items, err := game.LoadItems(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "FAIL: %v\n", err)
+		return nil, 1
+	}
+
+	ids := make([]string, 0, len(items))
+	for id := range items {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	fmt.Println()
+	for _, id := range ids {
+		it := items[id]
+		if _, gear := it.Slot(); gear {
+			fmt.Printf("Item %q (%s): %s, %s\n", id, it.Name, it.Kind, it.Bonus)
+			continue
+		}
+		fmt.Printf("Item %q (%s): %s, stack %d\n", id, it.Name, it.Kind, it.MaxStack)
+	}
+
+	errs := append(game.CheckLoot(enemies, items), game.CheckTiles(allMaps, items)...)
+	for _, err := range errs {
+		fmt.Printf("  ERROR: %v\n", err)
+	}
+	if len(errs) > 0 {
+		fmt.Printf("\n%d error(s) found\n", len(errs))
+		return nil, 1
+	}
+	fmt.Printf("\nAll %d items valid\n", len(items))
+	return items, 0
+}
+
+// validateQuests loads the quest catalog, lists every entry, and checks
+// that quests, dialogue and interactions only reference things that exist.
+func validateQuests(dir string, allMaps map[string]*maps.Map, enemies map[string]game.EnemyDef, items map[string]game.ItemDef) int {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		fmt.Printf("\nNo quests directory at %s, skipping\n", dir)
+		return 0
+	}
+
+	quests, err := game.LoadQuests(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "FAIL: %v\n", err)
+		return 1
+	}
+
+	ids := make([]string, 0, len(quests))
+	for id := range quests {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	fmt.Println()
+	for _, id := range ids {
+		q := quests[id]
+		kinds := make([]string, len(q.Objectives))
+		for i, o := range q.Objectives {
+			kinds[i] = o.Kind
+		}
+		fmt.Printf("Quest %q (%s): %s, %d EXP, %d reward item(s)\n", id, q.Name, strings.Join(kinds, " → "), q.RewardEXP, len(q.RewardItems))
+	}
+
+	errs := game.CheckQuests(quests, allMaps, enemies, items)
+	for _, err := range errs {
+		fmt.Printf("  ERROR: %v\n", err)
+	}
+	if len(errs) > 0 {
+		fmt.Printf("\n%d error(s) found\n", len(errs))
+		return 1
+	}
+	fmt.Printf("\nAll %d quests valid\n", len(quests))
 	return 0
 }
 
@@ -155,14 +287,24 @@ func runViz(path string) {
 		fmt.Println()
 	}
 
-	// Mark spawn, portals, and interactions
+	// Mark spawn, portals, interactions and NPCs
 	fmt.Printf("\nSpawn: (%d,%d)\n", m.SpawnX, m.SpawnY)
 	for _, p := range m.Portals {
-		fmt.Printf("Portal: (%d,%d) → %s (%d,%d)\n", p.X, p.Y, p.TargetMap, p.TargetX, p.TargetY)
+		arrow, lock := "→", ""
+		if p.TwoWay {
+			arrow = "↔"
+		}
+		if p.Locked() {
+			lock = " (locked)"
+		}
+		fmt.Printf("Portal: (%d,%d) %s %s (%d,%d)%s\n", p.X, p.Y, arrow, p.TargetMap, p.TargetX, p.TargetY, lock)
 	}
 	for _, inter := range m.Interactions {
 		fmt.Printf("Interaction: (%d,%d) [%s] %q\n", inter.X, inter.Y, inter.Type, inter.Text)
 	}
+	for _, n := range m.NPCs {
+		fmt.Printf("NPC: (%d,%d) %s %q, %d waypoint(s), %d dialogue node(s)\n", n.X, n.Y, n.ID, n.Name, len(n.Path), len(n.Dialogue.Nodes))
+	}
 }
 
 // --- stats ---
@@ -216,6 +358,7 @@ func runStats(path string) {
 	fmt.Printf("\nWalkable:      %d/%d (%.1f%%)\n", walkable, total, float64(walkable)/float64(total)*100)
 	fmt.Printf("Portals:       %d\n", len(m.Portals))
 	fmt.Printf("Interactions:  %d\n", len(m.Interactions))
+	fmt.Printf("NPCs:          %d\n", len(m.NPCs))
 }
 
 // --- all ---
@@ -229,7 +372,7 @@ func runAll(dir string) int {
 
 	// Run validate first
 	fmt.Println("=== VALIDATE ===")
-	code := runValidate(dir)
+	code := runValidate(dir, defaultEnemiesDir(dir))
 	if code != 0 {
 		return code
 	}
@@ -244,7 +387,105 @@ func runAll(dir string) int {
 		runViz(path)
 		fmt.Printf("\n=== STATS: %s ===\n", entry.Name())
 		runStats(path)
+		fmt.Printf("\n=== ENCOUNTERS: %s ===\n", entry.Name())
+		runEncounters(path)
 	}
 
 	return 0
 }
+
+// --- encounters ---
+
+// runEncounters draws the map with each walkable tile marked by the
+// encounter table that covers it (1-9, then a-z) and summarizes each table.
+func runEncounters(path string) {
+	m, err := maps.LoadMap(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	tables := m.Encounters
+	usingDefault := len(tables) == 0
+	if usingDefault {
+		tables = []maps.EncounterTable{game.DefaultEncounters}
+	}
+	index := make(map[*maps.EncounterTable]int, len(tables))
+	for i := range tables {
+		index[&tables[i]] = i
+	}
+
+	fmt.Printf("%s (%dx%d)\n", m.Name, m.Width, m.Height)
+
+	counts := make([]int, len(tables))
+	walkable, covered := 0, 0
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			tile := m.TileAt(x, y)
+			if tile.Walkable {
+				walkable++
+			}
+			t := encounterFor(m, tables, usingDefault, x, y)
+			if t == nil {
+				fmt.Print("\033[90m", string(tile.Char), "\033[0m")
+				continue
+			}
+			i := index[t]
+			counts[i]++
+			covered++
+			fmt.Print("\033[91m", string(tableMarker(i)), "\033[0m")
+		}
+		fmt.Println()
+	}
+
+	fmt.Println()
+	if usingDefault {
+		fmt.Println("No encounter tables — using the game default:")
+	}
+	for i, t := range tables {
+		area := "whole map"
+		if t.Region != nil {
+			area = fmt.Sprintf("region (%d,%d) %dx%d", t.Region.X, t.Region.Y, t.Region.W, t.Region.H)
+		}
+		enemies := "any enemy"
+		if len(t.Enemies) > 0 {
+			parts := make([]string, len(t.Enemies))
+			for j, e := range t.Enemies {
+				parts[j] = fmt.Sprintf("%s×%d", e.ID, e.Weight)
+			}
+			enemies = strings.Join(parts, " ")
+		}
+		g := t.Group
+		group := fmt.Sprintf("%d..%d, +%d/player", g.Min, g.Max, g.PerPlayer)
+		if g.LevelStep > 0 {
+			group += fmt.Sprintf(", +1 per %d levels", g.LevelStep)
+		}
+		fmt.Printf("  %c  %-24s %3d%%  on %-16s %5d tiles  [%s]  group %s\n",
+			tableMarker(i), area, t.Chance, strings.Join(t.Tiles, ","), counts[i], enemies, group)
+	}
+
+	pct := 0.0
+	if walkable > 0 {
+		pct = float64(covered) / float64(walkable) * 100
+	}
+	fmt.Printf("\nCovered: %d of %d walkable tiles (%.1f%%)\n", covered, walkable, pct)
+}
+
+// encounterFor mirrors the game's table lookup for one tile.
+func encounterFor(m *maps.Map, tables []maps.EncounterTable, usingDefault bool, x, y int) *maps.EncounterTable {
+	if !usingDefault {
+		return m.EncounterAt(x, y)
+	}
+	if tables[0].Covers(x, y, m.TileAt(x, y).Name) {
+		return &tables[0]
+	}
+	return nil
+}
+
+// tableMarker returns the character used for table i in the coverage map.
+func tableMarker(i int) rune {
+	if i < 9 {
+		return rune('1' + i)
+	}
+	return rune('a' + i - 9)
+}
@@ -1,31 +1,70 @@
 package main
 
 import (
+	"context"
 	"crypto/ed25519"
 	"crypto/rand"
 	"encoding/pem"
+	"errors"
+	"flag"
 	"log"
+	"net"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"crypto/x509"
 
+	"happy-place-2/internal/config"
 	"happy-place-2/internal/game"
 	"happy-place-2/internal/maps"
 	"happy-place-2/internal/server"
+	"happy-place-2/internal/store"
 )
 
+// Asset files and directories, relative to the configured assets directory.
 const (
-	defaultAddr = ":2222"
-	hostKeyPath = "host_key"
-	mapsDir     = "assets/maps"
-	defaultMap  = "Town Square"
+	enemiesDir      = "enemies"
+	itemsDir        = "items"
+	questsDir       = "quests"
+	progressionPath = "progression.json"
+	nameFilterPath  = "blocked_names.txt"
+)
+
+const (
+	mapWatchInterval  = 2 * time.Second  // how often the maps directory is polled for edits
+	shutdownCountdown = 10 * time.Second // warning shown to players before a restart
+	shutdownGrace     = 5 * time.Second  // extra time for sessions to close before forcing
 )
 
 func main() {
 	log.SetFlags(log.Ltime | log.Lshortfile)
 
+	// Settings come from defaults, config.json, HAPPY_* variables and flags
+	cfg, sources, err := config.Load(os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+	for _, line := range cfg.Describe(sources) {
+		log.Printf("Config: %s", line)
+	}
+	timing := game.NewTiming(game.Tuning{
+		TickRate:             cfg.TickRate,
+		CombatTurnTimeout:    cfg.Combat.TurnTimeout,
+		CombatEnemyActDelay:  cfg.Combat.EnemyActDelay,
+		CombatTransition:     cfg.Combat.Transition,
+		CombatCoopTransition: cfg.Combat.CoopTransition,
+		CombatResultDelay:    cfg.Combat.ResultDelay,
+		CombatReconnectGrace: cfg.Combat.ReconnectGrace,
+	})
+	mapsDir := cfg.MapsDir
+
 	// Generate host key if it doesn't exist
-	if err := ensureHostKey(hostKeyPath); err != nil {
+	if err := ensureHostKey(cfg.HostKey); err != nil {
 		log.Fatalf("Host key error: %v", err)
 	}
 
@@ -40,24 +79,144 @@ func main() {
 		log.Printf("Map loaded: %s (%dx%d, %d portals)", name, m.Width, m.Height, len(m.Portals))
 	}
 
+	// Load the enemy catalog
+	enemies, err := game.LoadEnemies(cfg.Asset(enemiesDir))
+	if err != nil {
+		log.Printf("Could not load enemies from %s: %v — using default enemies", cfg.Asset(enemiesDir), err)
+		enemies = game.DefaultEnemies()
+	}
+	log.Printf("Enemies loaded: %d", len(enemies))
+
+	// Load the item catalog
+	items, err := game.LoadItems(cfg.Asset(itemsDir))
+	if err != nil {
+		log.Printf("Could not load items from %s: %v — no loot will drop", cfg.Asset(itemsDir), err)
+	}
+	for _, err := range game.CheckLoot(enemies, items) {
+		log.Printf("Loot: %v — skipping", err)
+	}
+	for _, err := range game.CheckTiles(allMaps, items) {
+		log.Printf("Tiles: %v", err)
+	}
+	log.Printf("Items loaded: %d", len(items))
+
+	// Load the quest catalog
+	quests, err := game.LoadQuests(cfg.Asset(questsDir))
+	if err != nil {
+		log.Printf("Could not load quests from %s: %v — no quests will be offered", cfg.Asset(questsDir), err)
+	}
+	for _, err := range game.CheckQuests(quests, allMaps, enemies, items) {
+		log.Printf("Quests: %v", err)
+	}
+	log.Printf("Quests loaded: %d", len(quests))
+
+	// Load the EXP curve and level-up growth
+	progression, err := game.LoadProgression(cfg.Asset(progressionPath))
+	if err != nil {
+		log.Printf("Could not load progression from %s: %v — using default curve", cfg.Asset(progressionPath), err)
+		progression = game.DefaultProgression()
+	}
+	log.Printf("Progression: max level %d, %d EXP to reach Lv 2", progression.MaxLevel, progression.EXPForLevel(2))
+
+	// Open player persistence
+	var playerStore store.Store
+	fileStore, err := store.NewFileStore(cfg.DataDir)
+	if err != nil {
+		log.Printf("Could not open data directory %s: %v — progress will not be saved", cfg.DataDir, err)
+		playerStore = store.NewMemoryStore()
+	} else {
+		playerStore = fileStore
+	}
+
 	// Create game world and loop
-	world := game.NewWorld(allMaps, defaultMap)
-	gameLoop := game.NewGameLoop(world)
+	world := game.NewWorld(allMaps, enemies, items, quests, progression, cfg.DefaultMap)
+	world.DefaultEncounters.Chance = cfg.EncounterChance
+	gameLoop := game.NewGameLoop(world, playerStore, game.LoopOptions{Timing: timing})
 
 	// Start game loop in background
 	go gameLoop.Run()
-	defer gameLoop.Stop()
 
-	// Start SSH server (blocks)
-	listenAddr := defaultAddr
-	if port := os.Getenv("PORT"); port != "" {
-		listenAddr = ":" + port
+	// Reload maps whenever a file in the maps directory changes
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	go maps.Watch(watchCtx, mapsDir, mapWatchInterval, func() {
+		reloadMaps(gameLoop, mapsDir)
+	})
+
+	// Start SSH server in background
+	opts := server.Options{
+		Admins:                cfg.Admins,
+		MapsDir:               mapsDir,
+		MaxPlayers:            cfg.MaxPlayers,
+		MaxSessionsPerIP:      cfg.MaxSessionsPerIP,
+		MaxSessionsPerAccount: cfg.MaxSessionsPerAccount,
+		ConnRateLimit:         cfg.ConnRateLimit,
+		ConnRateWindow:        time.Duration(cfg.ConnRateWindow * float64(time.Second)),
+		DuplicateLogin:        server.DuplicatePolicy(cfg.DuplicateLogin),
 	}
-	sshServer := server.NewSSHServer(listenAddr, hostKeyPath, gameLoop)
-	log.Printf("Starting Happy Place 2 — connect with: ssh -p %s YourName@localhost", listenAddr[1:])
-	if err := sshServer.Start(); err != nil {
+	if filter, n, err := server.LoadWordFilter(cfg.Asset(nameFilterPath)); err != nil {
+		log.Printf("Could not load blocked names from %s: %v — names will not be filtered", cfg.Asset(nameFilterPath), err)
+	} else {
+		opts.NameFilter = filter
+		log.Printf("Blocked names loaded: %d", n)
+	}
+	sshServer, err := server.NewSSHServer(cfg.Addr, cfg.HostKey, gameLoop, playerStore, opts)
+	if err != nil {
+		gameLoop.Stop()
 		log.Fatalf("SSH server error: %v", err)
 	}
+	_, port, _ := net.SplitHostPort(cfg.Addr)
+	log.Printf("Starting Happy Place 2 — connect with: ssh -p %s YourName@localhost", port)
+	sshErr := make(chan error, 1)
+	go func() {
+		sshErr <- sshServer.Start()
+	}()
+
+	// Run until the SSH server fails or we're asked to stop
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	select {
+	case err := <-sshErr:
+		gameLoop.Stop()
+		log.Fatalf("SSH server error: %v", err)
+	case <-ctx.Done():
+	}
+	stopSignals() // a second signal kills the process immediately
+
+	log.Printf("Shutting down — %s countdown", shutdownCountdown)
+	shutdown(sshServer, gameLoop)
+	log.Println("Shutdown complete")
+}
+
+// shutdown drains the server in order: stop accepting connections, count
+// down and settle fights, save everyone, close sessions, then stop the loop.
+func shutdown(sshServer *server.SSHServer, gameLoop *game.GameLoop) {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownCountdown+shutdownGrace)
+	defer cancel()
+
+	// Closes the listener right away, then waits for sessions to end
+	sshDone := make(chan error, 1)
+	go func() {
+		sshDone <- sshServer.Shutdown(ctx)
+	}()
+
+	if err := gameLoop.Shutdown(ctx, shutdownCountdown); err != nil {
+		log.Printf("Game loop drain: %v", err)
+	}
+	if err := <-sshDone; err != nil {
+		log.Printf("SSH shutdown: %v", err)
+	}
+	gameLoop.Stop()
+}
+
+// reloadMaps swaps in the maps from disk, keeping the current ones if the
+// new set fails to load or validate.
+func reloadMaps(gameLoop *game.GameLoop, mapsDir string) {
+	relocated, err := gameLoop.ReloadMaps(mapsDir)
+	if err != nil {
+		log.Printf("Map reload failed, keeping current maps: %v", err)
+		return
+	}
+	log.Printf("Maps reloaded from %s (%d player(s) moved to spawn)", mapsDir, relocated)
 }
 
 func ensureHostKey(path string) error {
@@ -0,0 +1,357 @@
+// Package config assembles the server's settings from defaults, an
+// optional JSON file, environment variables and command-line flags, in
+// that order of precedence.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DefaultFile is read when no config file is named; it may be absent.
+const DefaultFile = "config.json"
+
+// envPrefix starts every environment variable, e.g. HAPPY_TICK_RATE.
+const envPrefix = "HAPPY_"
+
+// Config is the server's effective configuration. Times are in seconds.
+type Config struct {
+	Addr       string `json:"addr"`
+	HostKey    string `json:"host_key"`
+	AssetsDir  string `json:"assets_dir"` // enemies, items, quests, progression.json, blocked_names.txt
+	MapsDir    string `json:"maps_dir"`   // defaults to <assets_dir>/maps
+	DataDir    string `json:"data_dir"`   // saved players, accounts and bans
+	DefaultMap string `json:"default_map"`
+
+	TickRate        int    `json:"tick_rate"`        // ticks per second
+	EncounterChance int    `json:"encounter_chance"` // percent per tall_grass step on maps without tables
+	Combat          Combat `json:"combat"`
+
+	MaxPlayers            int      `json:"max_players"`
+	MaxSessionsPerIP      int      `json:"max_sessions_per_ip"`
+	MaxSessionsPerAccount int      `json:"max_sessions_per_account"`
+	ConnRateLimit         int      `json:"conn_rate_limit"`  // new connections per address per window
+	ConnRateWindow        float64  `json:"conn_rate_window"` // seconds
+	DuplicateLogin        string   `json:"duplicate_login"`  // kick, reject or allow
+	Admins                []string `json:"admins"`           // account names or SHA256: key fingerprints
+}
+
+// Combat holds the fight pacing, in seconds.
+type Combat struct {
+	TurnTimeout    float64 `json:"turn_timeout"`    // auto-defend after this long
+	EnemyActDelay  float64 `json:"enemy_act_delay"` // pause between enemy actions
+	Transition     float64 `json:"transition"`      // screen flash for the player who triggered the fight
+	CoopTransition float64 `json:"coop_transition"` // shorter flash for players pulled in
+	ResultDelay    float64 `json:"result_delay"`    // victory/defeat screen
+	ReconnectGrace float64 `json:"reconnect_grace"` // a dropped player's place is held this long
+}
+
+// Default returns the built-in configuration.
+func Default() *Config {
+	return &Config{
+		Addr:            ":2222",
+		HostKey:         "host_key",
+		AssetsDir:       "assets",
+		DataDir:         "data",
+		DefaultMap:      "Town Square",
+		TickRate:        20,
+		EncounterChance: 15,
+		Combat: Combat{
+			TurnTimeout:    15,
+			EnemyActDelay:  1,
+			Transition:     1,
+			CoopTransition: 0.5,
+			ResultDelay:    3,
+			ReconnectGrace: 60,
+		},
+		MaxPlayers:            50,
+		MaxSessionsPerIP:      5,
+		MaxSessionsPerAccount: 2,
+		ConnRateLimit:         10,
+		ConnRateWindow:        60,
+		DuplicateLogin:        "kick",
+	}
+}
+
+// Asset returns the path of a file or directory inside the assets directory.
+func (c *Config) Asset(name string) string {
+	return filepath.Join(c.AssetsDir, name)
+}
+
+// Load builds the effective configuration from args (without the program
+// name) and the environment. The config file is named by -config or
+// HAPPY_CONFIG; otherwise config.json is used if it exists. Sources reports
+// where each setting that isn't a default came from.
+func Load(args []string, getenv func(string) string) (*Config, Sources, error) {
+	cfg := Default()
+	sources := make(Sources)
+	settings := settingsOf(cfg)
+
+	// Flags are applied last, but must be parsed first to find -config
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	path := fs.String("config", "", "config file (JSON); default "+DefaultFile+" if present")
+	var flagged []func() error
+	for _, s := range settings {
+		fs.Func(s.flagName(), s.usage, func(v string) error {
+			if err := s.check(v); err != nil {
+				return err
+			}
+			flagged = append(flagged, func() error {
+				sources[s.key] = "flag"
+				return s.set(v)
+			})
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, nil, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	file, required := *path, true
+	if file == "" {
+		file = getenv(envPrefix + "CONFIG")
+	}
+	if file == "" {
+		file, required = DefaultFile, false
+	}
+	if err := loadFile(cfg, file, required, settings, sources); err != nil {
+		return nil, nil, err
+	}
+
+	// PORT and ADMINS predate the HAPPY_ variables and still work
+	if port := getenv("PORT"); port != "" && getenv(envPrefix+"ADDR") == "" {
+		cfg.Addr = ":" + port
+		sources["addr"] = "env PORT"
+	}
+	if admins := getenv("ADMINS"); admins != "" && getenv(envPrefix+"ADMINS") == "" {
+		for _, s := range settings {
+			if s.key == "admins" {
+				if err := s.set(admins); err != nil {
+					return nil, nil, fmt.Errorf("ADMINS: %w", err)
+				}
+			}
+		}
+		sources["admins"] = "env ADMINS"
+	}
+	for _, s := range settings {
+		if v := getenv(s.envName()); v != "" {
+			if err := s.set(v); err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", s.envName(), err)
+			}
+			sources[s.key] = "env"
+		}
+	}
+	for _, apply := range flagged {
+		if err := apply(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if cfg.MapsDir == "" {
+		cfg.MapsDir = cfg.Asset("maps")
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, nil, err
+	}
+	return cfg, sources, nil
+}
+
+// loadFile overlays a JSON config file on cfg. Unknown keys are errors, so
+// typos don't silently fall back to defaults.
+func loadFile(cfg *Config, path string, required bool, settings []setting, sources Sources) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.DisallowUnknownFields()
+	before := snapshot(settings)
+	if err := dec.Decode(cfg); err != nil {
+		return fmt.Errorf("parse config %s: %w", path, err)
+	}
+	for i, s := range settings {
+		if s.String() != before[i] {
+			sources[s.key] = "file " + path
+		}
+	}
+	return nil
+}
+
+func (c *Config) validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	check(c.Addr != "", "addr is empty")
+	check(c.DefaultMap != "", "default_map is empty")
+	check(c.TickRate >= 1 && c.TickRate <= 1000, "tick_rate %d is outside 1–1000", c.TickRate)
+	check(c.EncounterChance >= 0 && c.EncounterChance <= 100, "encounter_chance %d is outside 0–100", c.EncounterChance)
+	for _, t := range []struct {
+		name string
+		secs float64
+	}{
+		{"combat.turn_timeout", c.Combat.TurnTimeout},
+		{"combat.enemy_act_delay", c.Combat.EnemyActDelay},
+		{"combat.transition", c.Combat.Transition},
+		{"combat.coop_transition", c.Combat.CoopTransition},
+		{"combat.result_delay", c.Combat.ResultDelay},
+		{"combat.reconnect_grace", c.Combat.ReconnectGrace},
+	} {
+		check(t.secs > 0, "%s must be positive", t.name)
+	}
+	check(c.MaxPlayers >= 0 && c.MaxSessionsPerIP >= 0 && c.MaxSessionsPerAccount >= 0 && c.ConnRateLimit >= 0,
+		"session and connection limits can't be negative (0 turns a limit off)")
+	check(c.ConnRateWindow > 0, "conn_rate_window must be positive")
+	switch c.DuplicateLogin {
+	case "kick", "reject", "allow":
+	default:
+		errs = append(errs, fmt.Errorf("duplicate_login %q is not kick, reject or allow", c.DuplicateLogin))
+	}
+	return errors.Join(errs...)
+}
+
+// Sources maps a setting's key to where its value came from: "flag",
+// "env", "env PORT", "env ADMINS" or "file <path>". Settings left at their default are
+// absent.
+type Sources map[string]string
+
+// Describe lists every setting as "key = value", noting the source of any
+// that aren't defaults, for logging at startup.
+func (c *Config) Describe(sources Sources) []string {
+	settings := settingsOf(c)
+	lines := make([]string, len(settings))
+	for i, s := range settings {
+		lines[i] = fmt.Sprintf("%s = %s", s.key, s.String())
+		if src, ok := sources[s.key]; ok {
+			lines[i] += " (" + src + ")"
+		}
+	}
+	return lines
+}
+
+// setting is one configurable field, addressed by its JSON key path.
+type setting struct {
+	key   string        // e.g. "combat.turn_timeout"
+	usage string        // flag help
+	field reflect.Value // addressable field in the Config
+}
+
+// settingsOf lists every field of c with a JSON key, recursing into
+// nested structs, so flags and environment variables cover the same
+// settings as the file.
+func settingsOf(c *Config) []setting {
+	var out []setting
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			key := prefix + strings.Split(f.Tag.Get("json"), ",")[0]
+			if f.Type.Kind() == reflect.Struct {
+				walk(v.Field(i), key+".")
+				continue
+			}
+			out = append(out, setting{key: key, usage: usageFor(key, f.Type), field: v.Field(i)})
+		}
+	}
+	walk(reflect.ValueOf(c).Elem(), "")
+	return out
+}
+
+func usageFor(key string, t reflect.Type) string {
+	switch {
+	case strings.HasPrefix(key, "combat.") || key == "conn_rate_window":
+		return key + " in seconds"
+	case t.Kind() == reflect.Slice:
+		return key + ", comma-separated"
+	}
+	return key
+}
+
+// flagName turns "combat.turn_timeout" into "combat-turn-timeout".
+func (s setting) flagName() string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(s.key)
+}
+
+// envName turns "combat.turn_timeout" into "HAPPY_COMBAT_TURN_TIMEOUT".
+func (s setting) envName() string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(s.key, ".", "_"))
+}
+
+// check reports whether v would parse, without changing the setting.
+func (s setting) check(v string) error {
+	tmp := reflect.New(s.field.Type()).Elem()
+	return setting{key: s.key, field: tmp}.set(v)
+}
+
+// set parses v into the field.
+func (s setting) set(v string) error {
+	switch s.field.Kind() {
+	case reflect.String:
+		s.field.SetString(v)
+	case reflect.Int:
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a whole number", s.key, v)
+		}
+		s.field.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a number", s.key, v)
+		}
+		s.field.SetFloat(f)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		s.field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("%s: unsupported setting type %s", s.key, s.field.Type())
+	}
+	return nil
+}
+
+// String formats the field's current value.
+func (s setting) String() string {
+	switch s.field.Kind() {
+	case reflect.Slice:
+		items := s.field.Interface().([]string)
+		if len(items) == 0 {
+			return "(none)"
+		}
+		return strings.Join(items, ",")
+	case reflect.String:
+		if s.field.String() == "" {
+			return `""`
+		}
+	}
+	return fmt.Sprint(s.field.Interface())
+}
+
+func snapshot(settings []setting) []string {
+	out := make([]string, len(settings))
+	for i, s := range settings {
+		out[i] = s.String()
+	}
+	return out
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// env returns a getenv backed by vars.
+func env(vars map[string]string) func(string) string {
+	return func(k string) string { return vars[k] }
+}
+
+// writeConfig writes a config file and returns its path.
+func writeConfig(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPrecedence(t *testing.T) {
+	path := writeConfig(t, `{"tick_rate": 30, "max_players": 10, "encounter_chance": 5, "combat": {"turn_timeout": 20}}`)
+	cfg, sources, err := Load(
+		[]string{"-config", path, "-tick-rate", "50"},
+		env(map[string]string{"HAPPY_TICK_RATE": "40", "HAPPY_MAX_PLAYERS": "20"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		key       string
+		got, want any
+		source    string
+	}{
+		{"tick_rate", cfg.TickRate, 50, "flag"},
+		{"max_players", cfg.MaxPlayers, 20, "env"},
+		{"encounter_chance", cfg.EncounterChance, 5, "file " + path},
+		{"combat.turn_timeout", cfg.Combat.TurnTimeout, 20.0, "file " + path},
+	} {
+		if c.got != c.want || sources[c.key] != c.source {
+			t.Errorf("%s = %v from %q, want %v from %q", c.key, c.got, sources[c.key], c.want, c.source)
+		}
+	}
+	if _, ok := sources["addr"]; ok || cfg.Addr != Default().Addr {
+		t.Errorf("addr = %q from %q, want the default", cfg.Addr, sources["addr"])
+	}
+	if cfg.MapsDir != filepath.Join("assets", "maps") {
+		t.Errorf("maps_dir = %q, want it under assets_dir", cfg.MapsDir)
+	}
+}
+
+func TestConfigFile(t *testing.T) {
+	// The default file may be missing; a named one may not
+	if _, _, err := Load(nil, env(nil)); err != nil {
+		t.Fatalf("no config file: %v", err)
+	}
+	missing := filepath.Join(t.TempDir(), "missing.json")
+	if _, _, err := Load([]string{"-config", missing}, env(nil)); err == nil {
+		t.Fatal("a missing -config file was accepted")
+	}
+	if _, _, err := Load(nil, env(map[string]string{"HAPPY_CONFIG": missing})); err == nil {
+		t.Fatal("a missing HAPPY_CONFIG file was accepted")
+	}
+
+	// A misspelt key is an error, not a silent default
+	path := writeConfig(t, `{"tick_rat": 30}`)
+	_, _, err := Load([]string{"-config", path}, env(nil))
+	if err == nil || !strings.Contains(err.Error(), "tick_rat") {
+		t.Fatalf("unknown key: %v", err)
+	}
+}
+
+func TestLegacyVariables(t *testing.T) {
+	cfg, sources, err := Load(nil, env(map[string]string{"PORT": "2299", "ADMINS": "alice, SHA256:abc"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Addr != ":2299" || sources["addr"] != "env PORT" {
+		t.Errorf("addr = %q from %q, want :2299 from PORT", cfg.Addr, sources["addr"])
+	}
+	if want := []string{"alice", "SHA256:abc"}; !slices.Equal(cfg.Admins, want) || sources["admins"] != "env ADMINS" {
+		t.Errorf("admins = %v from %q, want %v from ADMINS", cfg.Admins, sources["admins"], want)
+	}
+
+	// The HAPPY_ variables win
+	cfg, sources, err = Load(nil, env(map[string]string{
+		"PORT": "2299", "HAPPY_ADDR": ":3000",
+		"ADMINS": "alice", "HAPPY_ADMINS": "bob",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Addr != ":3000" || sources["addr"] != "env" {
+		t.Errorf("addr = %q from %q, want :3000 from HAPPY_ADDR", cfg.Addr, sources["addr"])
+	}
+	if !slices.Equal(cfg.Admins, []string{"bob"}) || sources["admins"] != "env" {
+		t.Errorf("admins = %v from %q, want bob from HAPPY_ADMINS", cfg.Admins, sources["admins"])
+	}
+}
+
+func TestBadValues(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		args []string
+		env  map[string]string
+		want string
+	}{
+		{"flag not a number", []string{"-tick-rate", "fast"}, nil, "not a whole number"},
+		{"env not a number", nil, map[string]string{"HAPPY_COMBAT_TURN_TIMEOUT": "soon"}, "HAPPY_COMBAT_TURN_TIMEOUT"},
+		{"stray argument", []string{"extra"}, nil, "unexpected argument"},
+		{"tick rate", []string{"-tick-rate", "0"}, nil, "tick_rate 0 is outside"},
+		{"encounter chance", nil, map[string]string{"HAPPY_ENCOUNTER_CHANCE": "101"}, "encounter_chance 101"},
+		{"combat time", []string{"-combat-turn-timeout", "0"}, nil, "combat.turn_timeout must be positive"},
+		{"negative limit", []string{"-max-players", "-1"}, nil, "can't be negative"},
+		{"rate window", []string{"-conn-rate-window", "0"}, nil, "conn_rate_window must be positive"},
+		{"duplicate login", []string{"-duplicate-login", "ban"}, nil, `duplicate_login "ban"`},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			_, _, err := Load(c.args, env(c.env))
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Fatalf("error %v, want one mentioning %q", err, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Admin commands are requests: each runs whole on the loop goroutine
+// between ticks, like AddPlayer and RemovePlayer.
+
+// command runs an admin command on the loop goroutine and returns its error,
+// or errLoopStopped if the loop is gone.
+func (gl *GameLoop) command(fn func() error) error {
+	var err error
+	if stopped := gl.do(func() { err = fn() }); stopped != nil {
+		return stopped
+	}
+	return err
+}
+
+// onlinePlayer finds an online player by name for an admin command.
+func (gl *GameLoop) onlinePlayer(name string) (*Player, error) {
+	p := gl.playerByName(name)
+	if p == nil {
+		return nil, fmt.Errorf("no player named %q is online", name)
+	}
+	return p, nil
+}
+
+// Teleport moves a player to a walkable tile on any map. Players in a fight
+// can't be moved.
+func (gl *GameLoop) Teleport(name, mapName string, x, y int) error {
+	return gl.command(func() error {
+		p, err := gl.onlinePlayer(name)
+		if err != nil {
+			return err
+		}
+		return gl.teleport(p, mapName, x, y)
+	})
+}
+
+// TeleportToPlayer moves a player next to another online player, onto the
+// target's own tile if no neighbour is free.
+func (gl *GameLoop) TeleportToPlayer(name, target string) error {
+	return gl.command(func() error {
+		p, err := gl.onlinePlayer(name)
+		if err != nil {
+			return err
+		}
+		t, err := gl.onlinePlayer(target)
+		if err != nil {
+			return err
+		}
+		x, y := t.X, t.Y
+		for _, d := range [][2]int{{0, 1}, {0, -1}, {-1, 0}, {1, 0}} {
+			nx, ny := t.X+d[0], t.Y+d[1]
+			if gl.world.CanMoveTo(t.MapName, nx, ny) && gl.npcAt(t.MapName, nx, ny) == nil {
+				x, y = nx, ny
+				break
+			}
+		}
+		return gl.teleport(p, t.MapName, x, y)
+	})
+}
+
+func (gl *GameLoop) teleport(p *Player, mapName string, x, y int) error {
+	m := gl.world.GetMap(mapName)
+	switch {
+	case m == nil:
+		return fmt.Errorf("no map named %q", mapName)
+	case !m.IsWalkable(x, y):
+		return fmt.Errorf("(%d,%d) on %s is not walkable", x, y, mapName)
+	case p.FightID != 0:
+		return fmt.Errorf("%s is in a fight", p.Name)
+	}
+	p.MapName, p.X, p.Y = mapName, x, y
+	p.Talk = nil
+	p.PortalFade, p.FadingTo = 0, nil
+	gl.systemMessage(p, fmt.Sprintf("An admin moved you to %s (%d,%d).", mapName, x, y))
+	return nil
+}
+
+// GiveItem adds qty of an item to a player's inventory and returns how many
+// fit.
+func (gl *GameLoop) GiveItem(name, itemID string, qty int) (int, error) {
+	var got int
+	err := gl.command(func() error {
+		p, err := gl.onlinePlayer(name)
+		if err != nil {
+			return err
+		}
+		def, ok := gl.world.Items[itemID]
+		if !ok {
+			return fmt.Errorf("no item with id %q", itemID)
+		}
+		got = p.AddItem(def, qty)
+		if got > 0 {
+			gl.systemMessage(p, fmt.Sprintf("An admin gave you %d× %s.", got, def.Name))
+		}
+		return nil
+	})
+	return got, err
+}
+
+// GiveEXP awards EXP to a player and returns the levels it earned.
+func (gl *GameLoop) GiveEXP(name string, amount int) ([]LevelUp, error) {
+	var ups []LevelUp
+	err := gl.command(func() error {
+		p, err := gl.onlinePlayer(name)
+		if err != nil {
+			return err
+		}
+		gl.systemMessage(p, fmt.Sprintf("An admin gave you %d EXP.", amount))
+		ups = p.GainEXP(amount, gl.world.Progress)
+		for _, up := range ups {
+			gl.announceLevelUp(p, up)
+		}
+		return nil
+	})
+	return ups, err
+}
+
+// SpawnEncounter starts a fight for a player, pulling in others on the same
+// map as a normal encounter would. With no enemy IDs, enemies are rolled
+// from the table covering the player's tile (or the default table);
+// otherwise exactly the listed enemies appear.
+func (gl *GameLoop) SpawnEncounter(name string, enemyIDs []string) error {
+	return gl.command(func() error { return gl.spawnEncounter(name, enemyIDs) })
+}
+
+func (gl *GameLoop) spawnEncounter(name string, enemyIDs []string) error {
+	p, err := gl.onlinePlayer(name)
+	if err != nil {
+		return err
+	}
+	switch {
+	case p.FightID != 0:
+		return fmt.Errorf("%s is already in a fight", p.Name)
+	case p.Dead:
+		return fmt.Errorf("%s is dead", p.Name)
+	case gl.shuttingDown():
+		return fmt.Errorf("the server is shutting down")
+	}
+
+	if len(enemyIDs) == 0 {
+		table := gl.world.EncounterAt(p.MapName, p.X, p.Y)
+		if table == nil {
+			table = &gl.world.DefaultEncounters
+		}
+		gl.startEncounter(p, table)
+		return nil
+	}
+	defs := make([]EnemyDef, len(enemyIDs))
+	for i, id := range enemyIDs {
+		def, ok := gl.world.Enemies[id]
+		if !ok {
+			return fmt.Errorf("no enemy with id %q", id)
+		}
+		defs[i] = def
+	}
+	gl.startFight(p, func(int, int) []EnemyDef { return defs })
+	return nil
+}
+
+// Inspect describes a player's live state, one line per group of fields.
+func (gl *GameLoop) Inspect(name string) ([]string, error) {
+	var lines []string
+	err := gl.command(func() error {
+		var err error
+		lines, err = gl.inspect(name)
+		return err
+	})
+	return lines, err
+}
+
+func (gl *GameLoop) inspect(name string) ([]string, error) {
+	p, err := gl.onlinePlayer(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var inv []string
+	for _, s := range p.Inventory {
+		inv = append(inv, fmt.Sprintf("%d× %s", s.Qty, s.ID))
+	}
+	var equip []string
+	for slot, id := range p.Equipment {
+		if id != "" {
+			equip = append(equip, EquipSlot(slot).String()+"="+id)
+		}
+	}
+	var flags []string
+	for f := range p.Flags {
+		flags = append(flags, f)
+	}
+	sort.Strings(flags)
+	var quests []string
+	for _, q := range p.Quests {
+		state := fmt.Sprintf("stage %d", q.Stage)
+		if q.Done {
+			state = "done"
+		}
+		quests = append(quests, q.ID+" "+state)
+	}
+	var tags []string
+	if p.Guest {
+		tags = append(tags, "guest")
+	}
+	if p.Admin {
+		tags = append(tags, "admin")
+	}
+	if p.Dead {
+		tags = append(tags, "dead")
+	}
+	if p.FightID != 0 {
+		tags = append(tags, fmt.Sprintf("fight #%d", p.FightID))
+	}
+	if p.Talk != nil {
+		tags = append(tags, "talking to "+p.Talk.NPC.Def.ID)
+	}
+	if p.Disconnected {
+		tags = append(tags, fmt.Sprintf("away, forfeits in %ds", gl.timing.Secs(p.ReconnectTimer)))
+	}
+	gear := p.Gear.String()
+	if gear == "" {
+		gear = "-"
+	}
+	orNone := func(items []string) string {
+		if len(items) == 0 {
+			return "-"
+		}
+		return strings.Join(items, ", ")
+	}
+
+	return []string{
+		fmt.Sprintf("%s [%s] %s (%d,%d) · %s", p.Name, p.ID, p.MapName, p.X, p.Y, orNone(tags)),
+		fmt.Sprintf("Lv %d · EXP %d · %d pts · HP %d/%d STA %d/%d MP %d/%d",
+			p.Level, p.EXP, p.StatPoints, p.HP, p.MaxHP, p.Stamina, p.MaxStamina, p.MP, p.MaxMP),
+		fmt.Sprintf("ATK %d DEF %d · gear %s", p.Attack, p.Defense, gear),
+		"bag: " + orNone(inv),
+		"worn: " + orNone(equip),
+		"flags: " + orNone(flags),
+		"quests: " + orNone(quests),
+	}, nil
+}
+
+// Fights describes every active fight, one line each, in ID order.
+func (gl *GameLoop) Fights() []string {
+	var lines []string
+	gl.do(func() { lines = gl.fightLines() })
+	return lines
+}
+
+func (gl *GameLoop) fightLines() []string {
+	ids := make([]int, 0, len(gl.fights))
+	for id := range gl.fights {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	lines := make([]string, 0, len(ids))
+	for _, id := range ids {
+		f := gl.fights[id]
+		var players []string
+		for _, pid := range f.PlayerIDs {
+			if p, ok := gl.players[pid]; ok && p.Disconnected {
+				players = append(players, p.Name+" (away)")
+			} else if ok {
+				players = append(players, p.Name)
+			}
+		}
+		var enemies []string
+		for _, e := range f.Enemies {
+			enemies = append(enemies, fmt.Sprintf("%s %d/%d", e.Label, e.HP, e.Def.MaxHP))
+		}
+		lines = append(lines, fmt.Sprintf("#%d %s round %d, %s · %s vs %s",
+			f.ID, f.MapName, f.Round, f.Phase, strings.Join(players, ", "), strings.Join(enemies, ", ")))
+	}
+	return lines
+}
+
+// Broadcast sends a server announcement to every online player's chat.
+func (gl *GameLoop) Broadcast(text string) {
+	gl.do(func() {
+		for _, p := range gl.players {
+			gl.systemMessage(p, "[Server] "+text)
+		}
+	})
+}
@@ -0,0 +1,164 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChatChannel identifies where a chat message was sent.
+type ChatChannel int
+
+const (
+	ChatMap     ChatChannel = iota // everyone on the speaker's map
+	ChatGlobal                     // everyone online
+	ChatWhisper                    // a single named player
+	ChatParty                      // everyone in the speaker's fight
+	ChatSystem                     // feedback from the server
+)
+
+const (
+	MaxChatLen        = 200 // runes; longer messages are truncated
+	maxChatScrollback = 50  // messages kept per player
+	chatBurst         = 4   // messages that may be sent back to back
+)
+
+// ChatMessage is one line of chat as delivered to a player.
+type ChatMessage struct {
+	Tick    uint64
+	Channel ChatChannel
+	From    string // sender name, empty for system messages
+	To      string // whisper recipient name
+	Text    string
+}
+
+// processChat routes a line typed in chat mode. A leading /g, /w <name>,
+// /p or /m selects the channel; otherwise the party channel is used in a
+// fight and the map channel everywhere else.
+func (gl *GameLoop) processChat(p *Player, line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+
+	channel := ChatMap
+	if p.FightID != 0 {
+		channel = ChatParty
+	}
+	var to string
+	if strings.HasPrefix(line, "/") {
+		cmd, rest, _ := strings.Cut(line[1:], " ")
+		switch strings.ToLower(cmd) {
+		case "g", "global":
+			channel = ChatGlobal
+		case "m", "map":
+			channel = ChatMap
+		case "p", "party":
+			channel = ChatParty
+		case "w", "whisper":
+			channel = ChatWhisper
+			to, rest, _ = strings.Cut(strings.TrimSpace(rest), " ")
+		default:
+			gl.systemMessage(p, fmt.Sprintf("Unknown chat command /%s", cmd))
+			return
+		}
+		line = strings.TrimSpace(rest)
+		if line == "" {
+			return
+		}
+	}
+
+	if !p.takeChatToken() {
+		gl.systemMessage(p, "You're sending messages too fast.")
+		return
+	}
+	if r := []rune(line); len(r) > MaxChatLen {
+		line = string(r[:MaxChatLen])
+	}
+
+	msg := ChatMessage{Tick: gl.tickCount, Channel: channel, From: p.Name, Text: line}
+	switch channel {
+	case ChatMap:
+		for _, other := range gl.players {
+			if other.MapName == p.MapName {
+				other.addChat(msg)
+			}
+		}
+		p.Speech = line
+		p.SpeechTimer = gl.timing.SpeechDuration
+	case ChatGlobal:
+		for _, other := range gl.players {
+			other.addChat(msg)
+		}
+	case ChatParty:
+		fight, ok := gl.fights[p.FightID]
+		if !ok {
+			gl.systemMessage(p, "You're not in a battle.")
+			return
+		}
+		for _, pid := range fight.PlayerIDs {
+			if other, ok := gl.players[pid]; ok {
+				other.addChat(msg)
+			}
+		}
+	case ChatWhisper:
+		target := gl.playerByName(to)
+		if target == nil {
+			gl.systemMessage(p, fmt.Sprintf("No player named %q is online.", to))
+			return
+		}
+		msg.To = target.Name
+		target.addChat(msg)
+		if target != p {
+			p.addChat(msg)
+		}
+	}
+}
+
+// systemMessage delivers a server notice to one player's chat.
+func (gl *GameLoop) systemMessage(p *Player, text string) {
+	p.addChat(ChatMessage{Tick: gl.tickCount, Channel: ChatSystem, Text: text})
+}
+
+// playerByName finds an online player by display name, ignoring case.
+func (gl *GameLoop) playerByName(name string) *Player {
+	for _, p := range gl.players {
+		if strings.EqualFold(p.Name, name) {
+			return p
+		}
+	}
+	return nil
+}
+
+// addChat appends a message to the player's scrollback.
+func (p *Player) addChat(msg ChatMessage) {
+	p.Chat = append(p.Chat, msg)
+	if len(p.Chat) > maxChatScrollback {
+		p.Chat = p.Chat[len(p.Chat)-maxChatScrollback:]
+	}
+}
+
+// takeChatToken spends one message from the player's rate-limit bucket.
+func (p *Player) takeChatToken() bool {
+	if p.ChatTokens <= 0 {
+		return false
+	}
+	p.ChatTokens--
+	return true
+}
+
+// updateChat refills the rate-limit bucket and expires speech bubbles.
+func updateChat(p *Player, t *Timing) {
+	if p.ChatTokens < chatBurst {
+		p.ChatRefill++
+		if p.ChatRefill >= t.ChatRefillInterval {
+			p.ChatTokens++
+			p.ChatRefill = 0
+		}
+	}
+	if p.SpeechTimer > 0 {
+		p.SpeechTimer--
+		if p.SpeechTimer == 0 {
+			p.Speech = ""
+		}
+	}
+}
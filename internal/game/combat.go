@@ -1,5 +1,7 @@
 package game
 
+import "fmt"
+
 // CombatPhase tracks the current phase of a fight.
 type CombatPhase int
 
@@ -12,6 +14,23 @@ const (
 	PhaseDefeat                        // all players dead
 )
 
+// String returns the phase name used in admin listings.
+func (p CombatPhase) String() string {
+	switch p {
+	case PhaseTransition:
+		return "transition"
+	case PhasePlayerTurn:
+		return "player turn"
+	case PhaseEnemyTurn, PhaseEnemyActing:
+		return "enemy turn"
+	case PhaseVictory:
+		return "victory"
+	case PhaseDefeat:
+		return "defeat"
+	}
+	return fmt.Sprintf("phase %d", int(p))
+}
+
 // CombatState is the snapshot sent to the renderer for a player in combat.
 type CombatState struct {
 	Phase        CombatPhase
@@ -25,6 +44,8 @@ type CombatState struct {
 	Transitioning bool  // true if the viewer is still in transition
 	ViewerAction int    // selected action (1=Melee,2=Ranged,3=Magic, 0=none)
 	ViewerTarget int    // selected enemy target index
+	EXPGained    int      // EXP each surviving player earned (victory only)
+	LevelUps     []string // level-up announcements for the result screen
 }
 
 // EnemySnapshot is a read-only view of an enemy for rendering.
@@ -34,6 +55,8 @@ type EnemySnapshot struct {
 	MaxHP int
 	ID    int
 	Alive bool
+	Art   [][]string // animation frames from the enemy definition
+	Color [3]uint8
 }
 
 // CombatPlayerSnapshot is a read-only view of a player in combat.
@@ -45,6 +68,7 @@ type CombatPlayerSnapshot struct {
 	Alive   bool
 	Color   int
 	IsViewer bool
+	Away    bool // connection dropped; turns auto-defend until they return
 }
 
 // Fight manages the state of a single combat encounter.
@@ -61,13 +85,16 @@ type Fight struct {
 	EnemyTimer int      // ticks until next enemy acts
 	ResultTimer int     // ticks remaining on victory/defeat screen
 	Log        []string // battle log messages (most recent last)
+	EXPGained  int       // EXP awarded on victory
+	LevelUps   []LevelUp // levels gained on victory
+	timing     *Timing   // the owning loop's timing model
 }
 
 const maxLogLines = 6
 
-// NewFight creates a fight with enemies matching the player count.
-func NewFight(id int, mapName string, playerIDs []string) *Fight {
-	enemies := spawnEnemies(EnemyRat, len(playerIDs))
+// NewFight creates a fight against the given enemy types, paced by timing.
+func NewFight(id int, mapName string, playerIDs []string, defs []EnemyDef, timing *Timing) *Fight {
+	enemies := spawnEnemies(defs)
 	return &Fight{
 		ID:        id,
 		MapName:   mapName,
@@ -75,6 +102,7 @@ func NewFight(id int, mapName string, playerIDs []string) *Fight {
 		Phase:     PhaseTransition,
 		Enemies:   enemies,
 		PlayerIDs: playerIDs,
+		timing:    timing,
 	}
 }
 
@@ -158,7 +186,7 @@ func (f *Fight) NextPlayerTurn(players map[string]*Player) bool {
 		pid := f.PlayerIDs[idx]
 		if p, ok := players[pid]; ok && !p.Dead {
 			f.TurnIndex = idx
-			f.TurnTimer = CombatTurnTimeout
+			f.TurnTimer = f.timing.CombatTurnTimeout
 			p.CombatAction = 0
 			p.CombatTarget = 0
 			return true
@@ -187,14 +215,19 @@ func (f *Fight) StartPlayerPhase(players map[string]*Player) {
 func (f *Fight) StartEnemyPhase() {
 	f.Phase = PhaseEnemyTurn
 	f.EnemyIndex = 0
-	f.EnemyTimer = CombatEnemyActDelay
+	f.EnemyTimer = f.timing.CombatEnemyActDelay
 }
 
-// RemovePlayer removes a player from the fight (on disconnect).
+// RemovePlayer removes a player from the fight (on disconnect or forfeit).
+// TurnIndex keeps pointing at the last player to act, so NextPlayerTurn
+// picks up with whoever followed the removed player.
 func (f *Fight) RemovePlayer(playerID string) {
 	for i, pid := range f.PlayerIDs {
 		if pid == playerID {
 			f.PlayerIDs = append(f.PlayerIDs[:i], f.PlayerIDs[i+1:]...)
+			if i <= f.TurnIndex {
+				f.TurnIndex--
+			}
 			break
 		}
 	}
@@ -210,6 +243,8 @@ func (f *Fight) Snapshot(viewerID string, players map[string]*Player) *CombatSta
 			MaxHP: e.Def.MaxHP,
 			ID:    e.ID,
 			Alive: e.Alive(),
+			Art:   e.Def.Art,
+			Color: e.Def.Color,
 		}
 	}
 
@@ -227,6 +262,7 @@ func (f *Fight) Snapshot(viewerID string, players map[string]*Player) *CombatSta
 			Alive:    !p.Dead,
 			Color:    p.Color,
 			IsViewer: p.ID == viewerID,
+			Away:     p.Disconnected,
 		})
 	}
 
@@ -244,6 +280,11 @@ func (f *Fight) Snapshot(viewerID string, players map[string]*Player) *CombatSta
 		viewerTarget = p.CombatTarget
 	}
 
+	var levelUps []string
+	for _, l := range f.LevelUps {
+		levelUps = append(levelUps, fmt.Sprintf("%s reached Lv %d!", l.Name, l.Level))
+	}
+
 	return &CombatState{
 		Phase:         f.Phase,
 		Round:         f.Round,
@@ -256,6 +297,8 @@ func (f *Fight) Snapshot(viewerID string, players map[string]*Player) *CombatSta
 		Transitioning: transitioning,
 		ViewerAction:  viewerAction,
 		ViewerTarget:  viewerTarget,
+		EXPGained:     f.EXPGained,
+		LevelUps:      levelUps,
 	}
 }
 
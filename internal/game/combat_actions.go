@@ -13,13 +13,13 @@ const (
 
 // ResolveMelee resolves a melee attack. Returns damage, log message, and whether
 // the player had enough stamina.
-func ResolveMelee(attacker *Player, target *EnemyInstance) (int, string, bool) {
+func ResolveMelee(attacker *Player, target *EnemyInstance, rng *rand.Rand) (int, string, bool) {
 	if attacker.Stamina < MeleeCost {
 		return 0, "", false
 	}
 	attacker.Stamina -= MeleeCost
 
-	dmg := attacker.Attack + rand.Intn(3) - target.Def.Defense/2
+	dmg := attacker.MeleePower() + rng.Intn(3) - target.Def.Defense/2
 	if dmg < 1 {
 		dmg = 1
 	}
@@ -30,19 +30,20 @@ func ResolveMelee(attacker *Player, target *EnemyInstance) (int, string, bool) {
 
 	msg := fmt.Sprintf("%s slashes %s for %d damage!", attacker.Name, target.Label, dmg)
 	if !target.Alive() {
+		target.KilledBy = attacker.ID
 		msg += fmt.Sprintf(" %s defeated!", target.Label)
 	}
 	return dmg, msg, true
 }
 
 // ResolveRanged resolves a ranged attack. Weaker but cheaper than melee.
-func ResolveRanged(attacker *Player, target *EnemyInstance) (int, string, bool) {
+func ResolveRanged(attacker *Player, target *EnemyInstance, rng *rand.Rand) (int, string, bool) {
 	if attacker.Stamina < RangedCost {
 		return 0, "", false
 	}
 	attacker.Stamina -= RangedCost
 
-	dmg := attacker.Attack/2 + rand.Intn(3) - target.Def.Defense/2
+	dmg := attacker.RangedPower() + rng.Intn(3) - target.Def.Defense/2
 	if dmg < 1 {
 		dmg = 1
 	}
@@ -53,19 +54,20 @@ func ResolveRanged(attacker *Player, target *EnemyInstance) (int, string, bool)
 
 	msg := fmt.Sprintf("%s shoots %s for %d damage!", attacker.Name, target.Label, dmg)
 	if !target.Alive() {
+		target.KilledBy = attacker.ID
 		msg += fmt.Sprintf(" %s defeated!", target.Label)
 	}
 	return dmg, msg, true
 }
 
 // ResolveMagic resolves a magic attack. Strongest but costs MP.
-func ResolveMagic(attacker *Player, target *EnemyInstance) (int, string, bool) {
+func ResolveMagic(attacker *Player, target *EnemyInstance, rng *rand.Rand) (int, string, bool) {
 	if attacker.MP < MagicCost {
 		return 0, "", false
 	}
 	attacker.MP -= MagicCost
 
-	dmg := attacker.Attack*2 + rand.Intn(4) - target.Def.Defense/3
+	dmg := attacker.MagicPower() + rng.Intn(4) - target.Def.Defense/3
 	if dmg < 1 {
 		dmg = 1
 	}
@@ -76,6 +78,7 @@ func ResolveMagic(attacker *Player, target *EnemyInstance) (int, string, bool) {
 
 	msg := fmt.Sprintf("%s casts a spell on %s for %d damage!", attacker.Name, target.Label, dmg)
 	if !target.Alive() {
+		target.KilledBy = attacker.ID
 		msg += fmt.Sprintf(" %s defeated!", target.Label)
 	}
 	return dmg, msg, true
@@ -87,9 +90,9 @@ func ResolveDefend(player *Player) string {
 	return fmt.Sprintf("%s braces for impact!", player.Name)
 }
 
-// ResolveEnemyAttack resolves an enemy attacking a random living player.
-func ResolveEnemyAttack(enemy *EnemyInstance, target *Player) (int, string) {
-	dmg := enemy.Def.Attack + rand.Intn(3) - target.Defense/2
+// ResolveEnemyAttack resolves an enemy attacking the player it chose.
+func ResolveEnemyAttack(enemy *EnemyInstance, target *Player, rng *rand.Rand) (int, string) {
+	dmg := enemy.Def.Attack + rng.Intn(3) - target.DefensePower()/2
 	if dmg < 1 {
 		dmg = 1
 	}
@@ -104,7 +107,7 @@ func ResolveEnemyAttack(enemy *EnemyInstance, target *Player) (int, string) {
 		target.HP = 0
 	}
 
-	msg := fmt.Sprintf("%s bites %s for %d damage!", enemy.Label, target.Name, dmg)
+	msg := fmt.Sprintf("%s %s %s for %d damage!", enemy.Label, enemy.Def.AttackVerb, target.Name, dmg)
 	if target.Defending {
 		msg += " (Defended!)"
 	}
@@ -0,0 +1,215 @@
+package game
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// arena has one tall grass tile right of the spawn that always starts a
+// fight against enemyID.
+func arena(enemyID string) mapSpec {
+	return mapSpec{
+		Name: "Arena",
+		Rows: []string{
+			"#####",
+			"#.,.#",
+			"#...#",
+			"#####",
+		},
+		SpawnX: 1, SpawnY: 1,
+		Encounters: []map[string]any{encounterOn(enemyID)},
+	}
+}
+
+// startFight walks the player into the arena's grass and waits out the
+// transition, leaving them at the start of their first turn.
+func (h *harness) startFight(id string) *CombatState {
+	h.t.Helper()
+	h.press(id, ActionRight)
+	h.press(id, ActionRight)
+	for i := 0; i < h.timing.CombatTransitionLen && h.combat(id).Phase == PhaseTransition; i++ {
+		h.step(1)
+	}
+	c := h.combat(id)
+	if c.Phase != PhasePlayerTurn || c.CurrentTurn != id {
+		h.t.Fatalf("phase %v turn %q after the transition, want %s's turn", c.Phase, c.CurrentTurn, id)
+	}
+	return c
+}
+
+// lastLog returns the newest line of the player's battle log.
+func (h *harness) lastLog(id string) string {
+	h.t.Helper()
+	log := h.combat(id).Log
+	if len(log) == 0 {
+		h.t.Fatal("battle log is empty")
+	}
+	return log[len(log)-1]
+}
+
+func TestCombatRound(t *testing.T) {
+	h := newHarness(t, 1, arena("post"))
+	id := h.join("alice")
+	c := h.startFight(id)
+	if c.Round != 1 || c.TurnTimer != h.timing.CombatTurnTimeout {
+		t.Fatalf("round %d timer %d at the first turn", c.Round, c.TurnTimer)
+	}
+
+	// Defending ends the turn; the post attacks in the same tick, then
+	// pauses before the round ends
+	h.press(id, ActionDefend)
+	c = h.combat(id)
+	if c.Phase != PhaseEnemyActing || len(c.Log) != 2 {
+		t.Fatalf("phase %v log %v after defending, want the enemy acting", c.Phase, c.Log)
+	}
+	if !strings.Contains(c.Log[0], "braces") || !strings.Contains(c.Log[1], "leans on") {
+		t.Fatalf("log %v after defending", c.Log)
+	}
+	if hp := h.self(id).HP; hp != DefaultHP-1 {
+		t.Fatalf("HP %d after a defended 1-attack hit, want %d", hp, DefaultHP-1)
+	}
+	h.step(h.timing.CombatEnemyActDelay)
+	c = h.combat(id)
+	if c.Phase != PhasePlayerTurn || c.Round != 2 || c.CurrentTurn != id {
+		t.Fatalf("phase %v round %d turn %q, want alice's turn in round 2", c.Phase, c.Round, c.CurrentTurn)
+	}
+}
+
+func TestCombatTurnTimeout(t *testing.T) {
+	h := newHarness(t, 1, arena("post"))
+	id := h.join("alice")
+	h.startFight(id)
+
+	h.step(h.timing.CombatTurnTimeout - 1)
+	if phase := h.combat(id).Phase; phase != PhasePlayerTurn {
+		t.Fatalf("phase %v a tick before the timeout, want player turn", phase)
+	}
+	h.step(1)
+	if log := h.lastLog(id); !strings.Contains(log, "(timeout)") {
+		t.Fatalf("log %q after the turn timer ran out", log)
+	}
+}
+
+func TestCombatActionNeedsSelectionAndResources(t *testing.T) {
+	h := newHarness(t, 1, arena("post"))
+	id := h.join("alice")
+	h.startFight(id)
+
+	// Confirm does nothing until an action is picked
+	h.press(id, ActionConfirm)
+	if c := h.combat(id); c.Phase != PhasePlayerTurn || len(c.Log) != 0 {
+		t.Fatalf("phase %v log %v after confirming nothing", c.Phase, c.Log)
+	}
+
+	h.press(id, ActionDebugPage3) // magic
+	if a := h.combat(id).ViewerAction; a != 3 {
+		t.Fatalf("selected action %d, want magic", a)
+	}
+	h.press(id, ActionConfirm)
+	if mp := h.self(id).MP; mp != DefaultMP-MagicCost {
+		t.Fatalf("MP %d after a spell, want %d", mp, DefaultMP-MagicCost)
+	}
+	if enemy := h.combat(id).Enemies[0]; enemy.HP >= enemy.MaxHP {
+		t.Fatalf("post at %d/%d HP after a spell", enemy.HP, enemy.MaxHP)
+	}
+}
+
+func TestCombatVictory(t *testing.T) {
+	h := newHarness(t, 1, arena("dummy"))
+	id := h.join("alice")
+	h.startFight(id)
+
+	h.press(id, ActionDebugPage1) // melee
+	h.press(id, ActionConfirm)
+	c := h.combat(id)
+	if c.Phase != PhaseVictory {
+		t.Fatalf("phase %v after felling the dummy, want victory", c.Phase)
+	}
+	if c.EXPGained != testEnemies["dummy"].EXP {
+		t.Fatalf("EXP gained %d, want %d", c.EXPGained, testEnemies["dummy"].EXP)
+	}
+	if stamina := h.self(id).Stamina; stamina != DefaultStamina-MeleeCost {
+		t.Fatalf("stamina %d after a melee attack, want %d", stamina, DefaultStamina-MeleeCost)
+	}
+
+	// The result screen stays up, then the player is back on the map
+	h.step(h.timing.CombatResultDelay / 2)
+	h.combat(id)
+	h.step(h.timing.CombatResultDelay / 2)
+	if h.state(id).Combat != nil {
+		t.Fatal("still in the fight after the result screen")
+	}
+	p := h.self(id)
+	if p.FightID != 0 || p.EXP != testEnemies["dummy"].EXP {
+		t.Fatalf("fight %d EXP %d after victory", p.FightID, p.EXP)
+	}
+	h.at(id, "Arena", 2, 1)
+}
+
+func TestCombatDefeat(t *testing.T) {
+	h := newHarness(t, 1, arena("brute"))
+	id := h.join("alice")
+	h.startFight(id)
+
+	h.press(id, ActionDefend)
+	c := h.combat(id)
+	if c.Phase != PhaseDefeat {
+		t.Fatalf("phase %v after the brute's blow, want defeat", c.Phase)
+	}
+	if p := h.self(id); !p.Dead || p.HP != 0 {
+		t.Fatalf("dead %v HP %d after defeat", p.Dead, p.HP)
+	}
+
+	// Defeat sends the player back to the spawn point, healed
+	h.step(h.timing.CombatResultDelay)
+	if h.state(id).Combat != nil {
+		t.Fatal("still in the fight after the defeat screen")
+	}
+	p := h.self(id)
+	if p.Dead || p.HP != p.MaxHP || p.Stamina != p.MaxStamina || p.MP != p.MaxMP {
+		t.Fatalf("dead %v HP %d/%d stamina %d/%d MP %d/%d after respawn", p.Dead, p.HP, p.MaxHP, p.Stamina, p.MaxStamina, p.MP, p.MaxMP)
+	}
+	h.at(id, "Arena", 1, 1)
+}
+
+func TestCombatPullsInPlayersOnTheSameMap(t *testing.T) {
+	h := newHarness(t, 1, arena("post"))
+	alice := h.join("alice")
+	bob := h.join("bob")
+
+	h.startFight(alice)
+	a, b := h.combat(alice), h.combat(bob)
+	if len(a.Players) != 2 || len(b.Players) != 2 {
+		t.Fatalf("party sizes %d and %d, want both players in one fight", len(a.Players), len(b.Players))
+	}
+	// The trigger player goes first, then the rest of the party
+	if a.CurrentTurn != alice {
+		t.Fatalf("turn %q, want alice first", a.CurrentTurn)
+	}
+	h.press(bob, ActionDefend) // not bob's turn
+	if turn := h.combat(bob).CurrentTurn; turn != alice {
+		t.Fatalf("turn %q after bob acted out of turn", turn)
+	}
+	h.press(alice, ActionDefend)
+	if turn := h.combat(bob).CurrentTurn; turn != bob {
+		t.Fatalf("turn %q after alice defended, want bob", turn)
+	}
+}
+
+func TestCombatIsRepeatableWithASeed(t *testing.T) {
+	fight := func(seed int64) []string {
+		h := newHarness(t, seed, arena("post"))
+		id := h.join("alice")
+		h.startFight(id)
+		for round := 0; round < 3; round++ {
+			h.press(id, ActionDebugPage2) // ranged
+			h.press(id, ActionConfirm)
+			h.step(h.timing.CombatEnemyActDelay + 1)
+		}
+		return h.combat(id).Log
+	}
+	if first, second := fight(7), fight(7); !slices.Equal(first, second) {
+		t.Fatalf("same seed, different fights:\n%v\n%v", first, second)
+	}
+}
@@ -0,0 +1,159 @@
+package game
+
+import "happy-place-2/internal/maps"
+
+// Conversation is a player's open dialogue with an NPC.
+type Conversation struct {
+	NPC  *NPC
+	Node *maps.DialogueNode
+}
+
+// DialogueState is the viewer's open conversation, sent with GameState.
+type DialogueState struct {
+	Name    string
+	Color   [3]uint8
+	Text    string
+	Choices []string // only the choices whose conditions hold
+}
+
+// HasFlag reports whether a player flag is set.
+func (p *Player) HasFlag(flag string) bool {
+	return p.Flags[flag]
+}
+
+// SetFlag sets or clears a player flag.
+func (p *Player) SetFlag(flag string, on bool) {
+	if !on {
+		delete(p.Flags, flag)
+		return
+	}
+	if p.Flags == nil {
+		p.Flags = make(map[string]bool)
+	}
+	p.Flags[flag] = true
+}
+
+// meets reports whether the player satisfies every condition.
+func (p *Player) meets(conds []maps.Condition) bool {
+	for _, c := range conds {
+		var ok bool
+		if c.Quest != "" {
+			status := p.questStatus(c.Quest)
+			ok = status != "" && (c.Status == "" || c.Status == status)
+		} else {
+			ok = p.HasFlag(c.Flag)
+		}
+		if ok == c.Not {
+			return false
+		}
+	}
+	return true
+}
+
+// apply carries out dialogue or quest effects on the player.
+func (gl *GameLoop) apply(p *Player, effects []maps.Effect) {
+	for _, e := range effects {
+		if e.Set != "" {
+			p.SetFlag(e.Set, true)
+		}
+		if e.Clear != "" {
+			p.SetFlag(e.Clear, false)
+		}
+		if e.StartQuest != "" {
+			gl.startQuest(p, e.StartQuest)
+		}
+	}
+}
+
+// visibleChoices returns the node's choices the player may pick, in order.
+func (p *Player) visibleChoices(node *maps.DialogueNode) []maps.DialogueChoice {
+	var out []maps.DialogueChoice
+	for _, c := range node.Choices {
+		if p.meets(c.Conditions) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// startConversation opens an NPC's dialogue at its start node and turns the
+// NPC to face the player. Talk and delivery objectives are credited first,
+// so the dialogue can react to the quests they complete.
+func (gl *GameLoop) startConversation(p *Player, n *NPC) {
+	n.Dir = p.Dir.opposite()
+	gl.questEvent(p, ObjectiveTalk, n.Def.ID, 1)
+	p.Talk = &Conversation{NPC: n}
+	gl.showNode(p, n.Def.Dialogue.Start)
+}
+
+// showNode moves the conversation to a node, or ends it for "".
+func (gl *GameLoop) showNode(p *Player, id string) {
+	node := p.Talk.NPC.Def.Dialogue.Nodes[id]
+	if node == nil {
+		p.Talk = nil
+		return
+	}
+	p.Talk.Node = node
+	gl.apply(p, node.Effects)
+}
+
+// processDialogueInput handles input while a conversation is open: Enter
+// advances lines without choices, number keys pick a choice, and moving
+// walks away.
+func (gl *GameLoop) processDialogueInput(p *Player, action Action) {
+	node := p.Talk.Node
+	switch action {
+	case ActionConfirm:
+		if len(p.visibleChoices(node)) == 0 {
+			gl.showNode(p, node.Next)
+		}
+	case ActionUp, ActionDown, ActionLeft, ActionRight:
+		p.Talk = nil
+	default:
+		n, ok := choiceKey(action)
+		if !ok {
+			return
+		}
+		choices := p.visibleChoices(node)
+		if n > len(choices) {
+			return
+		}
+		choice := choices[n-1]
+		gl.apply(p, choice.Effects)
+		gl.showNode(p, choice.Next)
+	}
+}
+
+// choiceKey maps the number keys to dialogue choices 1-5. They share
+// actions with the combat menu.
+func choiceKey(action Action) (int, bool) {
+	switch action {
+	case ActionDebugPage1:
+		return 1, true
+	case ActionDebugPage2:
+		return 2, true
+	case ActionDebugPage3:
+		return 3, true
+	case ActionDefend:
+		return 4, true
+	case ActionItem:
+		return 5, true
+	}
+	return 0, false
+}
+
+// dialogueState builds the viewer's open conversation, or nil.
+func (gl *GameLoop) dialogueState(p *Player) *DialogueState {
+	if p.Talk == nil {
+		return nil
+	}
+	ds := &DialogueState{
+		Name:  p.Talk.NPC.Def.Name,
+		Color: p.Talk.NPC.Def.Color,
+		Text:  p.Talk.Node.Text,
+	}
+	for _, c := range p.visibleChoices(p.Talk.Node) {
+		ds.Choices = append(ds.Choices, c.Text)
+	}
+	return ds
+}
@@ -0,0 +1,88 @@
+package game
+
+import (
+	"fmt"
+	"log"
+)
+
+// A player whose connection drops mid-fight keeps their place for
+// CombatReconnectGrace. Their turns auto-defend, and logging in again under
+// the same name puts them back in the same fight. If the fight ends while
+// they are away, they get its outcome like everyone else and are saved.
+// If the grace period runs out first, they forfeit: they leave the fight
+// with no share of its EXP or loot and are sent back to town restored, as
+// after a defeat.
+
+// holdForReconnect keeps a disconnecting player in their fight and reports
+// whether it did. Guests can't reconnect to the same character, and
+// finished fights have nothing left to hold a place in.
+func (gl *GameLoop) holdForReconnect(p *Player) bool {
+	if p.Guest || p.FightID == 0 || gl.drained {
+		return false
+	}
+	fight, ok := gl.fights[p.FightID]
+	if !ok || fight.Phase == PhaseVictory || fight.Phase == PhaseDefeat {
+		return false
+	}
+	p.Disconnected = true
+	p.ReconnectTimer = gl.timing.CombatReconnectGrace
+	fight.AddLog(fmt.Sprintf("%s lost connection!", p.Name))
+	log.Printf("%s disconnected mid-fight — holding their place for %ds", p.Name, gl.timing.Secs(gl.timing.CombatReconnectGrace))
+	return true
+}
+
+// awaitingReconnect finds a disconnected player held in a fight by name.
+func (gl *GameLoop) awaitingReconnect(name string) *Player {
+	for _, p := range gl.players {
+		if p.Disconnected && p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// resume hands a held player to a new session.
+func (gl *GameLoop) resume(p *Player, opts JoinOptions) RenderChan {
+	p.Disconnected = false
+	p.ReconnectTimer = 0
+	if fight, ok := gl.fights[p.FightID]; ok {
+		fight.AddLog(fmt.Sprintf("%s is back!", p.Name))
+	}
+	log.Printf("%s reconnected and rejoined fight %d", p.Name, p.FightID)
+	return gl.reattach(p, opts, "Reconnected — back in the fight.")
+}
+
+// tickDisconnected counts down every held player's grace period, and
+// removes those whose fight has ended or whose time has run out.
+func (gl *GameLoop) tickDisconnected() {
+	for _, p := range gl.players {
+		if !p.Disconnected {
+			continue
+		}
+		if _, ok := gl.fights[p.FightID]; !ok {
+			// The fight ended without them; it already paid out
+			gl.dropDisconnected(p)
+			log.Printf("%s's fight ended while they were away — saved", p.Name)
+			continue
+		}
+		p.ReconnectTimer--
+		if p.ReconnectTimer > 0 {
+			continue
+		}
+		gl.fights[p.FightID].AddLog(fmt.Sprintf("%s forfeits the fight.", p.Name))
+		gl.leaveFight(p)
+		gl.respawn(p)
+		gl.dropDisconnected(p)
+		log.Printf("%s did not reconnect in time and forfeited their fight", p.Name)
+	}
+}
+
+// dropDisconnected saves a held player and removes them from the world.
+func (gl *GameLoop) dropDisconnected(p *Player) {
+	p.Disconnected = false
+	p.ReconnectTimer = 0
+	p.FightID = 0
+	p.Dead = false
+	gl.savePlayer(p)
+	delete(gl.players, p.ID)
+}
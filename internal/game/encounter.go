@@ -0,0 +1,69 @@
+package game
+
+import (
+	"math/rand"
+
+	"happy-place-2/internal/maps"
+)
+
+// EncounterChance is the default percent chance per tall_grass step on
+// maps without their own encounter tables.
+const EncounterChance = 15
+
+// DefaultEncounters applies to maps that declare no encounter tables:
+// rats on tall_grass, one per player, so a map without tables is always
+// safe for a new player. A catalog without rats falls back to any enemy
+// (see RollEncounter). Each World starts with a copy it can tune.
+var DefaultEncounters = maps.EncounterTable{
+	Chance:  EncounterChance,
+	Tiles:   []string{"tall_grass"},
+	Enemies: []maps.EncounterEnemy{{ID: EnemyRat.ID, Weight: 1}},
+	Group:   maps.DefaultGroupSize,
+}
+
+// EncounterAt returns the encounter table for a position on the named map,
+// or nil if stepping there can never start a fight.
+func (w *World) EncounterAt(mapName string, x, y int) *maps.EncounterTable {
+	m, ok := w.Maps[mapName]
+	if !ok {
+		return nil
+	}
+	if len(m.Encounters) == 0 {
+		if w.DefaultEncounters.Covers(x, y, m.TileAt(x, y).Name) {
+			return &w.DefaultEncounters
+		}
+		return nil
+	}
+	return m.EncounterAt(x, y)
+}
+
+// RollEncounter picks the enemies for a fight from a table, sized for the
+// party. Table entries missing from the catalog are skipped; if none are
+// usable the whole catalog is drawn from uniformly.
+func (w *World) RollEncounter(t *maps.EncounterTable, partySize, avgLevel int, rng *rand.Rand) []EnemyDef {
+	var entries []maps.EncounterEnemy
+	total := 0
+	for _, e := range t.Enemies {
+		if _, ok := w.Enemies[e.ID]; ok && e.Weight > 0 {
+			entries = append(entries, e)
+			total += e.Weight
+		}
+	}
+
+	defs := make([]EnemyDef, t.Group.Size(partySize, avgLevel))
+	for i := range defs {
+		if total == 0 {
+			defs[i] = w.RandomEnemy(rng)
+			continue
+		}
+		r := rng.Intn(total)
+		for _, e := range entries {
+			if r < e.Weight {
+				defs[i] = w.Enemies[e.ID]
+				break
+			}
+			r -= e.Weight
+		}
+	}
+	return defs
+}
@@ -1,20 +1,40 @@
 package game
 
+import "math/rand"
+
+// Behavior profiles control how an enemy picks its action each turn.
+const (
+	BehaviorRandom     = "random"     // attacks a random living player
+	BehaviorAggressive = "aggressive" // focuses the player with the lowest HP
+	BehaviorErratic    = "erratic"    // sometimes hesitates and loses its turn
+)
+
 // EnemyDef defines an enemy type's base stats.
 type EnemyDef struct {
-	Name    string
-	MaxHP   int
-	Attack  int
-	Defense int
-	EXP     int // awarded per kill
+	ID         string // catalog key, e.g. "rat"
+	Name       string
+	MaxHP      int
+	Attack     int
+	Defense    int
+	EXP        int        // awarded per kill
+	AttackVerb string     // e.g. "bites" in "Rat bites Alice for 3 damage!"
+	Art        [][]string // animation frames, each a block of lines for the combat view
+	Color      [3]uint8   // RGB color of the art
+	Behavior   string     // one of the Behavior* profiles
+	Loot       []LootDrop // rolled once when the enemy is defeated
 }
 
+// erraticHesitateChance is the percent chance an erratic enemy skips its turn.
+const erraticHesitateChance = 30
+
 // EnemyInstance is a live enemy in a fight.
 type EnemyInstance struct {
 	Def   EnemyDef
 	HP    int
 	ID    int    // unique within the fight (0-based)
 	Label string // display name, e.g. "Rat A"
+
+	KilledBy string // player ID that landed the final blow
 }
 
 // Alive reports whether this enemy still has HP.
@@ -22,33 +42,73 @@ func (e *EnemyInstance) Alive() bool {
 	return e.HP > 0
 }
 
-// EnemyRat is the basic encounter enemy.
+// Hesitates reports whether the enemy's behavior makes it lose this turn.
+func (e *EnemyInstance) Hesitates(rng *rand.Rand) bool {
+	return e.Def.Behavior == BehaviorErratic && rng.Intn(100) < erraticHesitateChance
+}
+
+// ChooseTarget picks which living player the enemy attacks.
+func (e *EnemyInstance) ChooseTarget(living []*Player, rng *rand.Rand) *Player {
+	if e.Def.Behavior == BehaviorAggressive {
+		target := living[0]
+		for _, p := range living[1:] {
+			if p.HP < target.HP {
+				target = p
+			}
+		}
+		return target
+	}
+	return living[rng.Intn(len(living))]
+}
+
+// EnemyRat is the basic encounter enemy, used when no catalog is available.
 var EnemyRat = EnemyDef{
-	Name:    "Rat",
-	MaxHP:   15,
-	Attack:  4,
-	Defense: 1,
-	EXP:     8,
-}
-
-// enemyLabels generates labels like "Rat A", "Rat B", ... for N enemies.
-func enemyLabels(count int, name string) []string {
-	labels := make([]string, count)
-	for i := 0; i < count; i++ {
-		if count == 1 {
+	ID:         "rat",
+	Name:       "Rat",
+	MaxHP:      15,
+	Attack:     4,
+	Defense:    1,
+	EXP:        8,
+	AttackVerb: "bites",
+	Art:        [][]string{{">·~"}, {">·-"}},
+	Color:      [3]uint8{180, 160, 140},
+	Behavior:   BehaviorRandom,
+}
+
+// DefaultEnemies returns a fallback catalog containing only the rat.
+func DefaultEnemies() map[string]EnemyDef {
+	return map[string]EnemyDef{EnemyRat.ID: EnemyRat}
+}
+
+// enemyLabels generates labels like "Rat A", "Rat B", "Wolf" for a group.
+// Names that appear once keep their plain name.
+func enemyLabels(names []string) []string {
+	counts := make(map[string]int)
+	for _, name := range names {
+		counts[name]++
+	}
+	seen := make(map[string]int)
+	labels := make([]string, len(names))
+	for i, name := range names {
+		if counts[name] == 1 {
 			labels[i] = name
 		} else {
-			labels[i] = name + " " + string(rune('A'+i))
+			labels[i] = name + " " + string(rune('A'+seen[name]))
 		}
+		seen[name]++
 	}
 	return labels
 }
 
-// spawnEnemies creates N instances of a given EnemyDef.
-func spawnEnemies(def EnemyDef, count int) []*EnemyInstance {
-	labels := enemyLabels(count, def.Name)
-	enemies := make([]*EnemyInstance, count)
-	for i := 0; i < count; i++ {
+// spawnEnemies creates one instance per EnemyDef.
+func spawnEnemies(defs []EnemyDef) []*EnemyInstance {
+	names := make([]string, len(defs))
+	for i, def := range defs {
+		names[i] = def.Name
+	}
+	labels := enemyLabels(names)
+	enemies := make([]*EnemyInstance, len(defs))
+	for i, def := range defs {
 		enemies[i] = &EnemyInstance{
 			Def:   def,
 			HP:    def.MaxHP,
@@ -0,0 +1,167 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"happy-place-2/internal/maps"
+)
+
+// Limits on enemy art so every catalog entry fits in a combat row.
+const (
+	MaxEnemyArtWidth  = 16
+	MaxEnemyArtHeight = 4
+)
+
+// jsonEnemy is the on-disk format of an assets/enemies/*.json file.
+type jsonEnemy struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Stats      jsonStats  `json:"stats"`
+	EXP        int        `json:"exp"`
+	AttackVerb string     `json:"attack_verb"`
+	Behavior   string     `json:"behavior,omitempty"`
+	Color      string     `json:"color,omitempty"`
+	Art        [][]string `json:"art"`
+	Loot       []jsonLoot `json:"loot,omitempty"`
+}
+
+type jsonLoot struct {
+	Item   string `json:"item"`
+	Chance int    `json:"chance"`
+	Min    int    `json:"min,omitempty"`
+	Max    int    `json:"max,omitempty"`
+}
+
+type jsonStats struct {
+	MaxHP   int `json:"max_hp"`
+	Attack  int `json:"attack"`
+	Defense int `json:"defense"`
+}
+
+// LoadEnemy reads and validates a single enemy definition file.
+func LoadEnemy(path string) (EnemyDef, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return EnemyDef{}, fmt.Errorf("read enemy file: %w", err)
+	}
+
+	var je jsonEnemy
+	if err := json.Unmarshal(data, &je); err != nil {
+		return EnemyDef{}, fmt.Errorf("parse enemy JSON: %w", err)
+	}
+
+	def := EnemyDef{
+		ID:         je.ID,
+		Name:       je.Name,
+		MaxHP:      je.Stats.MaxHP,
+		Attack:     je.Stats.Attack,
+		Defense:    je.Stats.Defense,
+		EXP:        je.EXP,
+		AttackVerb: je.AttackVerb,
+		Art:        je.Art,
+		Color:      [3]uint8{200, 200, 200},
+		Behavior:   je.Behavior,
+	}
+	for _, jl := range je.Loot {
+		d := LootDrop{ItemID: jl.Item, Chance: jl.Chance, Min: jl.Min, Max: jl.Max}
+		if d.Min == 0 {
+			d.Min = 1
+		}
+		if d.Max == 0 {
+			d.Max = d.Min
+		}
+		def.Loot = append(def.Loot, d)
+	}
+	if def.Behavior == "" {
+		def.Behavior = BehaviorRandom
+	}
+	if je.Color != "" {
+		c, err := maps.ParseHexColor(je.Color)
+		if err != nil {
+			return EnemyDef{}, err
+		}
+		def.Color = c
+	}
+	if err := def.validate(); err != nil {
+		return EnemyDef{}, err
+	}
+	return def, nil
+}
+
+// validate checks the fields LoadEnemy cannot fill in with defaults.
+func (d EnemyDef) validate() error {
+	switch {
+	case d.ID == "":
+		return fmt.Errorf("missing id")
+	case d.Name == "":
+		return fmt.Errorf("enemy %q: missing name", d.ID)
+	case d.AttackVerb == "":
+		return fmt.Errorf("enemy %q: missing attack_verb", d.ID)
+	case d.MaxHP <= 0:
+		return fmt.Errorf("enemy %q: max_hp must be positive, got %d", d.ID, d.MaxHP)
+	case d.Attack < 0 || d.Defense < 0 || d.EXP < 0:
+		return fmt.Errorf("enemy %q: attack, defense and exp must not be negative", d.ID)
+	}
+	switch d.Behavior {
+	case BehaviorRandom, BehaviorAggressive, BehaviorErratic:
+	default:
+		return fmt.Errorf("enemy %q: unknown behavior %q", d.ID, d.Behavior)
+	}
+
+	for _, l := range d.Loot {
+		if l.ItemID == "" || l.Chance < 1 || l.Chance > 100 || l.Min < 1 || l.Max < l.Min {
+			return fmt.Errorf("enemy %q: invalid loot entry %+v", d.ID, l)
+		}
+	}
+
+	if len(d.Art) == 0 {
+		return fmt.Errorf("enemy %q: art needs at least one frame", d.ID)
+	}
+	height := len(d.Art[0])
+	for i, frame := range d.Art {
+		if len(frame) == 0 || len(frame) > MaxEnemyArtHeight {
+			return fmt.Errorf("enemy %q: art frame %d has %d lines, want 1..%d", d.ID, i, len(frame), MaxEnemyArtHeight)
+		}
+		if len(frame) != height {
+			return fmt.Errorf("enemy %q: art frame %d has %d lines, frame 0 has %d", d.ID, i, len(frame), height)
+		}
+		for _, line := range frame {
+			if w := len([]rune(line)); w > MaxEnemyArtWidth {
+				return fmt.Errorf("enemy %q: art line %q is %d wide, max %d", d.ID, line, w, MaxEnemyArtWidth)
+			}
+		}
+	}
+	return nil
+}
+
+// LoadEnemies scans a directory for *.json enemy files and returns
+// them indexed by ID.
+func LoadEnemies(dir string) (map[string]EnemyDef, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read enemies directory: %w", err)
+	}
+
+	catalog := make(map[string]EnemyDef)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		def, err := LoadEnemy(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("load %s: %w", entry.Name(), err)
+		}
+		if _, exists := catalog[def.ID]; exists {
+			return nil, fmt.Errorf("duplicate enemy id %q in %s", def.ID, entry.Name())
+		}
+		catalog[def.ID] = def
+	}
+	if len(catalog) == 0 {
+		return nil, fmt.Errorf("no enemy definitions in %s", dir)
+	}
+	return catalog, nil
+}
@@ -0,0 +1,233 @@
+package game
+
+import "fmt"
+
+// EquipSlot identifies where a piece of gear is worn.
+type EquipSlot int
+
+const (
+	SlotWeapon EquipSlot = iota
+	SlotArmor
+	SlotAccessory
+	NumEquipSlots
+)
+
+// slotNames are the persisted and displayed names of each slot.
+var slotNames = [NumEquipSlots]string{"weapon", "armor", "accessory"}
+
+// String returns the slot's name, e.g. "weapon".
+func (s EquipSlot) String() string {
+	if s < 0 || s >= NumEquipSlots {
+		return "unknown"
+	}
+	return slotNames[s]
+}
+
+// slotByName maps a slot name (also the item kind) back to its slot.
+func slotByName(name string) (EquipSlot, bool) {
+	for i, n := range slotNames {
+		if n == name {
+			return EquipSlot(i), true
+		}
+	}
+	return 0, false
+}
+
+// StatBonus is what a piece of gear adds to combat power.
+type StatBonus struct {
+	Attack  int // melee
+	Defense int
+	Ranged  int
+	Magic   int
+}
+
+func (b StatBonus) add(o StatBonus) StatBonus {
+	return StatBonus{
+		Attack:  b.Attack + o.Attack,
+		Defense: b.Defense + o.Defense,
+		Ranged:  b.Ranged + o.Ranged,
+		Magic:   b.Magic + o.Magic,
+	}
+}
+
+// String formats the non-zero bonuses, e.g. "+2 ATK +1 DEF".
+func (b StatBonus) String() string {
+	s := ""
+	for _, part := range []struct {
+		v    int
+		name string
+	}{{b.Attack, "ATK"}, {b.Defense, "DEF"}, {b.Ranged, "RNG"}, {b.Magic, "MAG"}} {
+		if part.v != 0 {
+			if s != "" {
+				s += " "
+			}
+			s += fmt.Sprintf("%+d %s", part.v, part.name)
+		}
+	}
+	return s
+}
+
+// Slot returns the equipment slot an item goes in, if it is gear.
+func (d ItemDef) Slot() (EquipSlot, bool) {
+	return slotByName(d.Kind)
+}
+
+// MeleePower is the attack used by ResolveMelee.
+func (p *Player) MeleePower() int {
+	return p.Attack + p.Gear.Attack
+}
+
+// RangedPower is the attack used by ResolveRanged.
+func (p *Player) RangedPower() int {
+	return p.Attack/2 + p.Gear.Ranged
+}
+
+// MagicPower is the attack used by ResolveMagic.
+func (p *Player) MagicPower() int {
+	return p.Attack*2 + p.Gear.Magic
+}
+
+// DefensePower is the defense used by ResolveEnemyAttack.
+func (p *Player) DefensePower() int {
+	return p.Defense + p.Gear.Defense
+}
+
+// refreshGear recomputes the cached gear bonus from the equipped items.
+func (p *Player) refreshGear(w *World) {
+	var total StatBonus
+	for _, id := range p.Equipment {
+		if id != "" {
+			total = total.add(w.Item(id).Bonus)
+		}
+	}
+	p.Gear = total
+}
+
+// Equip moves one of an item from the inventory into its slot. Whatever was
+// in the slot goes back into the inventory.
+func (p *Player) Equip(def ItemDef, w *World) (string, bool) {
+	slot, ok := def.Slot()
+	if !ok {
+		return fmt.Sprintf("%s can't be equipped.", def.Name), false
+	}
+	if p.FightID != 0 {
+		return "You can't change gear mid-fight.", false
+	}
+	if !p.RemoveItem(def.ID, 1) {
+		return fmt.Sprintf("You have no %s.", def.Name), false
+	}
+	if old := p.Equipment[slot]; old != "" {
+		if p.AddItem(w.Item(old), 1) == 0 {
+			p.AddItem(def, 1) // undo: no room for the old piece
+			return "Your bag is too full to swap gear.", false
+		}
+	}
+	p.Equipment[slot] = def.ID
+	p.refreshGear(w)
+	return fmt.Sprintf("Equipped %s (%s).", def.Name, def.Bonus), true
+}
+
+// Unequip moves the item in a slot back into the inventory.
+func (p *Player) Unequip(slot EquipSlot, w *World) (string, bool) {
+	id := p.Equipment[slot]
+	if id == "" {
+		return fmt.Sprintf("Nothing equipped as %s.", slot), false
+	}
+	if p.FightID != 0 {
+		return "You can't change gear mid-fight.", false
+	}
+	def := w.Item(id)
+	if p.AddItem(def, 1) == 0 {
+		return "Your bag is full.", false
+	}
+	p.Equipment[slot] = ""
+	p.refreshGear(w)
+	return fmt.Sprintf("Unequipped %s.", def.Name), true
+}
+
+// EquippedItem is one equipment slot on the character sheet.
+type EquippedItem struct {
+	Slot  string
+	Name  string // "" = empty slot
+	Bonus string
+}
+
+// StatLine is one allocatable base stat on the character sheet.
+type StatLine struct {
+	Label string
+	Value int
+	Gain  int // what one stat point adds
+}
+
+// CharacterState is the viewer's character sheet, sent with GameState.
+type CharacterState struct {
+	Name       string
+	Level      int
+	Slots      []EquippedItem
+	Stats      []StatLine
+	StatPoints int
+	Cursor     int // equipment slots first, then stats
+
+	Attack, Defense int // base stats
+	Melee, Ranged   int // derived power including gear
+	Magic, Guard    int // Guard = derived defense
+}
+
+// sheetRows is the number of selectable rows on the character sheet.
+const sheetRows = int(NumEquipSlots) + int(NumStats)
+
+// characterState builds the viewer's character sheet, or nil when closed.
+func (gl *GameLoop) characterState(p *Player) *CharacterState {
+	if !p.SheetOpen {
+		return nil
+	}
+	cs := &CharacterState{
+		Name:       p.Name,
+		Level:      p.Level,
+		Slots:      make([]EquippedItem, NumEquipSlots),
+		Stats:      make([]StatLine, NumStats),
+		StatPoints: p.StatPoints,
+		Cursor:     p.SheetCursor,
+		Attack:     p.Attack,
+		Defense:    p.Defense,
+		Melee:      p.MeleePower(),
+		Ranged:     p.RangedPower(),
+		Magic:      p.MagicPower(),
+		Guard:      p.DefensePower(),
+	}
+	for slot, id := range p.Equipment {
+		cs.Slots[slot].Slot = EquipSlot(slot).String()
+		if id != "" {
+			def := gl.world.Item(id)
+			cs.Slots[slot].Name = def.Name
+			cs.Slots[slot].Bonus = def.Bonus.String()
+		}
+	}
+	for i := range cs.Stats {
+		st := Stat(i)
+		g := st.pointGrowth(gl.world.Progress)
+		cs.Stats[i] = StatLine{Label: st.String(), Value: st.value(p), Gain: g.HP + g.Stamina + g.MP + g.Attack + g.Defense}
+	}
+	return cs
+}
+
+// processSheetInput handles input while the character sheet is open:
+// up/down pick a row, Enter unequips a slot or spends a stat point.
+func (gl *GameLoop) processSheetInput(p *Player, action Action) {
+	switch action {
+	case ActionCharacter:
+		p.SheetOpen = false
+	case ActionUp:
+		p.SheetCursor = (p.SheetCursor + sheetRows - 1) % sheetRows
+	case ActionDown:
+		p.SheetCursor = (p.SheetCursor + 1) % sheetRows
+	case ActionConfirm:
+		var msg string
+		if p.SheetCursor < int(NumEquipSlots) {
+			msg, _ = p.Unequip(EquipSlot(p.SheetCursor), gl.world)
+		} else {
+			msg, _ = p.SpendStatPoint(Stat(p.SheetCursor-int(NumEquipSlots)), gl.world.Progress)
+		}
+		gl.systemMessage(p, msg)
+	}
+}
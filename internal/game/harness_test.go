@@ -0,0 +1,242 @@
+package game
+
+import (
+	"encoding/json"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"happy-place-2/internal/maps"
+	"happy-place-2/internal/store"
+)
+
+// testEnemies is the catalog every harness world uses.
+var testEnemies = map[string]EnemyDef{
+	// dummy falls to any single hit
+	"dummy": {ID: "dummy", Name: "Dummy", MaxHP: 1, Attack: 1, EXP: 10, AttackVerb: "pokes", Behavior: BehaviorRandom},
+	// post can't be killed quickly and barely hurts
+	"post": {ID: "post", Name: "Post", MaxHP: 500, Attack: 1, Defense: 50, EXP: 1, AttackVerb: "leans on", Behavior: BehaviorRandom},
+	// brute flattens anyone in one blow
+	"brute": {ID: "brute", Name: "Brute", MaxHP: 500, Attack: 500, Defense: 50, EXP: 1, AttackVerb: "crushes", Behavior: BehaviorAggressive},
+}
+
+// mapSpec describes a small test map. Rows use '.' for grass, '#' for wall
+// and ',' for tall grass. A digit from 3 to 9 is that legend key, which
+// Legend must define.
+type mapSpec struct {
+	Name       string
+	Rows       []string
+	SpawnX     int
+	SpawnY     int
+	Portals    []maps.Portal
+	Encounters []map[string]any          // raw JSON encounter tables
+	Legend     map[string]map[string]any // raw JSON legend entries beyond 0-2
+	Switches   []map[string]any          // raw JSON switches
+}
+
+// json renders the spec in the on-disk map format, so tests load maps the
+// same way the server does.
+func (s mapSpec) json() ([]byte, error) {
+	tiles := make([][]int, len(s.Rows))
+	for y, row := range s.Rows {
+		for _, ch := range row {
+			idx := 0
+			switch ch {
+			case '#':
+				idx = 1
+			case ',':
+				idx = 2
+			case '3', '4', '5', '6', '7', '8', '9':
+				idx = int(ch - '0')
+			}
+			tiles[y] = append(tiles[y], idx)
+		}
+	}
+	portals := make([]map[string]any, len(s.Portals))
+	for i, p := range s.Portals {
+		portals[i] = map[string]any{
+			"x": p.X, "y": p.Y, "target_map": p.TargetMap, "target_x": p.TargetX, "target_y": p.TargetY,
+			"if": p.Conditions, "item": p.Item, "level": p.Level, "locked_text": p.LockedText,
+			"transition": p.Transition, "facing": p.Facing, "two_way": p.TwoWay,
+		}
+	}
+	legend := map[string]any{
+		"0": map[string]any{"char": ".", "fg": "green", "walkable": true, "name": "grass"},
+		"1": map[string]any{"char": "#", "fg": "gray", "walkable": false, "name": "wall"},
+		"2": map[string]any{"char": ",", "fg": "green", "walkable": true, "name": "tall_grass"},
+	}
+	for k, t := range s.Legend {
+		legend[k] = t
+	}
+	return json.Marshal(map[string]any{
+		"name":       s.Name,
+		"width":      len(s.Rows[0]),
+		"height":     len(s.Rows),
+		"spawn":      map[string]int{"x": s.SpawnX, "y": s.SpawnY},
+		"tiles":      tiles,
+		"legend":     legend,
+		"portals":    portals,
+		"encounters": s.Encounters,
+		"switches":   s.Switches,
+	})
+}
+
+// fixedClock always reports the same time.
+type fixedClock struct{ t time.Time }
+
+func (c fixedClock) Now() time.Time { return c.t }
+
+// harness drives a running GameLoop one tick at a time. Players join
+// through AddPlayer, act by sending InputEvents and are observed only
+// through the GameState snapshots on their render channels, as a session
+// would.
+type harness struct {
+	t      *testing.T
+	loop   *GameLoop
+	timing *Timing
+	chans  map[string]RenderChan
+	states map[string]GameState
+}
+
+// newHarness builds a world from specs (the first is the default map) and
+// starts a loop with a manual timing model, a fixed clock and a seeded RNG.
+// The loop is stopped when the test ends.
+func newHarness(t *testing.T, seed int64, specs ...mapSpec) *harness {
+	t.Helper()
+	return newStoreHarness(t, seed, store.NewMemoryStore(), specs...)
+}
+
+// newStoreHarness is newHarness with players saved to st.
+func newStoreHarness(t *testing.T, seed int64, st store.Store, specs ...mapSpec) *harness {
+	t.Helper()
+	all, err := maps.LoadMaps(mapDir(t, specs...))
+	if err != nil {
+		t.Fatalf("load maps: %v", err)
+	}
+
+	timing := DefaultTiming()
+	timing.Interval = 0
+	world := NewWorld(all, testEnemies, nil, nil, nil, specs[0].Name)
+	loop := NewGameLoop(world, st, LoopOptions{
+		Timing: timing,
+		Clock:  fixedClock{time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)},
+		Rand:   rand.New(rand.NewSource(seed)),
+	})
+	go loop.Run()
+	t.Cleanup(loop.Stop)
+	return &harness{
+		t:      t,
+		loop:   loop,
+		timing: timing,
+		chans:  make(map[string]RenderChan),
+		states: make(map[string]GameState),
+	}
+}
+
+// mapDir writes specs as map files to a fresh directory and returns it.
+func mapDir(t *testing.T, specs ...mapSpec) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, s := range specs {
+		data, err := s.json()
+		if err != nil {
+			t.Fatalf("encode map %s: %v", s.Name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, s.Name+".json"), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// join adds a player and runs a tick so they have a first snapshot.
+func (h *harness) join(name string) string {
+	h.t.Helper()
+	id, ch := h.loop.AddPlayer(name, JoinOptions{})
+	h.chans[id] = ch
+	h.step(1)
+	return id
+}
+
+// step runs n ticks, keeping each player's latest snapshot.
+func (h *harness) step(n int) {
+	for i := 0; i < n; i++ {
+		h.loop.Step()
+		for id, ch := range h.chans {
+		drain:
+			for {
+				select {
+				case s, ok := <-ch:
+					if !ok {
+						break drain
+					}
+					h.states[id] = s
+				default:
+					break drain
+				}
+			}
+		}
+	}
+}
+
+// press sends one action from a player and runs the tick that handles it.
+func (h *harness) press(id string, a Action) {
+	h.loop.InputChan() <- InputEvent{PlayerID: id, Action: a}
+	h.step(1)
+}
+
+// walk presses a direction and then waits out the move cooldown, so the
+// next press is free to move again.
+func (h *harness) walk(id string, a Action) {
+	h.press(id, a)
+	h.step(h.timing.MoveRepeatDelay)
+}
+
+// state returns the player's most recent snapshot.
+func (h *harness) state(id string) GameState {
+	h.t.Helper()
+	s, ok := h.states[id]
+	if !ok {
+		h.t.Fatalf("no snapshot for %s", id)
+	}
+	return s
+}
+
+// self returns the player's own entry in their latest snapshot.
+func (h *harness) self(id string) PlayerSnapshot {
+	h.t.Helper()
+	for _, p := range h.state(id).Map.Players {
+		if p.ID == id {
+			return p
+		}
+	}
+	h.t.Fatalf("%s is missing from their own snapshot", id)
+	return PlayerSnapshot{}
+}
+
+// combat returns the player's combat state, failing if they aren't fighting.
+func (h *harness) combat(id string) *CombatState {
+	h.t.Helper()
+	c := h.state(id).Combat
+	if c == nil {
+		h.t.Fatalf("%s is not in a fight", id)
+	}
+	return c
+}
+
+// at fails unless the player stands at (x, y) on mapName.
+func (h *harness) at(id, mapName string, x, y int) {
+	h.t.Helper()
+	p := h.self(id)
+	if got := h.state(id).Map.Map.Name; got != mapName || p.X != x || p.Y != y {
+		h.t.Fatalf("%s at %s (%d,%d), want %s (%d,%d)", id, got, p.X, p.Y, mapName, x, y)
+	}
+}
+
+// encounterOn returns an encounter table that always starts a fight
+// against the given enemy on tall grass.
+func encounterOn(enemyID string) map[string]any {
+	return map[string]any{"chance": 100, "enemies": []map[string]any{{"id": enemyID}}}
+}
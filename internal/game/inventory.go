@@ -0,0 +1,137 @@
+package game
+
+import "fmt"
+
+// CombatActionItem is the CombatAction index for using an item.
+const CombatActionItem = 5
+
+// InventoryEntry is one inventory row for rendering.
+type InventoryEntry struct {
+	Name        string
+	Description string
+	Qty         int
+	Usable      bool
+	Slot        string // gear slot name, "" for non-gear
+	Bonus       string // gear bonus summary, e.g. "+2 ATK"
+}
+
+// InventoryState is the viewer's open inventory, sent with GameState.
+type InventoryState struct {
+	Items    []InventoryEntry
+	Cursor   int
+	InCombat bool // picking an item as a combat action
+}
+
+// inventoryState builds the viewer's inventory view, or nil when the
+// inventory is not on screen.
+func (gl *GameLoop) inventoryState(p *Player) *InventoryState {
+	inCombat := p.FightID != 0 && p.CombatAction == CombatActionItem
+	if !p.InventoryOpen && !inCombat {
+		return nil
+	}
+	items := make([]InventoryEntry, len(p.Inventory))
+	for i, s := range p.Inventory {
+		def := gl.world.Item(s.ID)
+		items[i] = InventoryEntry{
+			Name:        def.Name,
+			Description: def.Description,
+			Qty:         s.Qty,
+			Usable:      def.Usable(),
+		}
+		if slot, gear := def.Slot(); gear {
+			items[i].Slot = slot.String()
+			items[i].Bonus = def.Bonus.String()
+		}
+	}
+	return &InventoryState{Items: items, Cursor: p.InventoryCursor, InCombat: inCombat}
+}
+
+// moveInventoryCursor shifts the selection, wrapping at both ends.
+func (p *Player) moveInventoryCursor(delta int) {
+	n := len(p.Inventory)
+	if n == 0 {
+		p.InventoryCursor = 0
+		return
+	}
+	p.InventoryCursor = ((p.InventoryCursor+delta)%n + n) % n
+}
+
+// selectedItem returns the stack under the cursor, if any.
+func (p *Player) selectedItem() (ItemStack, bool) {
+	if p.InventoryCursor < 0 || p.InventoryCursor >= len(p.Inventory) {
+		return ItemStack{}, false
+	}
+	return p.Inventory[p.InventoryCursor], true
+}
+
+// processInventoryInput handles input while the overworld inventory is open.
+// Movement is blocked until it is closed again.
+func (gl *GameLoop) processInventoryInput(p *Player, action Action) {
+	switch action {
+	case ActionInventory:
+		p.InventoryOpen = false
+	case ActionUp:
+		p.moveInventoryCursor(-1)
+	case ActionDown:
+		p.moveInventoryCursor(1)
+	case ActionConfirm:
+		stack, ok := p.selectedItem()
+		if !ok {
+			return
+		}
+		def := gl.world.Item(stack.ID)
+		var msg string
+		if _, gear := def.Slot(); gear {
+			msg, _ = p.Equip(def, gl.world)
+		} else {
+			msg, _ = p.UseItem(def)
+		}
+		gl.systemMessage(p, msg)
+	}
+}
+
+// useCombatItem uses the selected item as the player's turn. It reports
+// whether the turn was spent.
+func (gl *GameLoop) useCombatItem(p *Player, fight *Fight) bool {
+	stack, ok := p.selectedItem()
+	if !ok {
+		return false
+	}
+	msg, used := p.UseItem(gl.world.Item(stack.ID))
+	if !used {
+		gl.systemMessage(p, msg)
+		return false
+	}
+	fight.AddLog(msg)
+	return true
+}
+
+// awardLoot rolls each defeated enemy's loot table. Drops go to the player
+// who landed the killing blow, or the first living player if they left.
+func (gl *GameLoop) awardLoot(fight *Fight) {
+	for _, e := range fight.Enemies {
+		for _, drop := range rollLoot(e.Def.Loot, gl.rng) {
+			def, ok := gl.world.Items[drop.ID]
+			if !ok {
+				continue
+			}
+			p := gl.players[e.KilledBy]
+			if p == nil || p.Dead {
+				living := fight.LivingPlayers(gl.players)
+				if len(living) == 0 {
+					return
+				}
+				p = gl.players[living[0]]
+			}
+			got := p.AddItem(def, drop.Qty)
+			switch {
+			case got == 0:
+				fight.AddLog(fmt.Sprintf("%s dropped %s, but %s's bag is full.", e.Label, def.Name, p.Name))
+			case got == 1:
+				fight.AddLog(fmt.Sprintf("%s dropped %s → %s", e.Label, def.Name, p.Name))
+			default:
+				fight.AddLog(fmt.Sprintf("%s dropped %d× %s → %s", e.Label, got, def.Name, p.Name))
+			}
+		}
+	}
+}
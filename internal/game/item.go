@@ -0,0 +1,160 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// Item kinds.
+const (
+	ItemConsumable = "consumable" // used up to apply its effect
+	ItemMaterial   = "material"   // loot with no direct use
+	// Gear kinds match the EquipSlot names: "weapon", "armor", "accessory".
+)
+
+// InventorySlots is the number of distinct item stacks a player can carry.
+const InventorySlots = 20
+
+// ItemEffect is what a consumable restores when used.
+type ItemEffect struct {
+	HP      int
+	Stamina int
+	MP      int
+}
+
+// ItemDef defines an item type.
+type ItemDef struct {
+	ID          string
+	Name        string
+	Description string
+	Kind        string // ItemConsumable, ItemMaterial or a gear slot name
+	MaxStack    int
+	UseVerb     string     // e.g. "drinks" in "alice drinks a Potion"
+	Effect      ItemEffect // consumables only
+	Bonus       StatBonus  // gear only
+}
+
+// Usable reports whether the item can be used from the inventory.
+func (d ItemDef) Usable() bool {
+	return d.Kind == ItemConsumable
+}
+
+// ItemStack is a quantity of one item type in an inventory.
+type ItemStack struct {
+	ID  string
+	Qty int
+}
+
+// LootDrop is one entry in an enemy's loot table. Each entry is rolled
+// independently when the enemy is defeated.
+type LootDrop struct {
+	ItemID   string
+	Chance   int // percent
+	Min, Max int // quantity range when the roll succeeds
+}
+
+// rollLoot returns the items dropped by one defeated enemy.
+func rollLoot(table []LootDrop, rng *rand.Rand) []ItemStack {
+	var drops []ItemStack
+	for _, d := range table {
+		if rng.Intn(100) >= d.Chance {
+			continue
+		}
+		qty := d.Min
+		if d.Max > d.Min {
+			qty += rng.Intn(d.Max - d.Min + 1)
+		}
+		drops = append(drops, ItemStack{ID: d.ItemID, Qty: qty})
+	}
+	return drops
+}
+
+// AddItem puts up to qty of an item into the inventory, respecting the
+// stack limit and slot count. It returns how many were added.
+func (p *Player) AddItem(def ItemDef, qty int) int {
+	for i := range p.Inventory {
+		s := &p.Inventory[i]
+		if s.ID != def.ID {
+			continue
+		}
+		n := min(qty, def.MaxStack-s.Qty)
+		if n < 0 {
+			n = 0
+		}
+		s.Qty += n
+		return n
+	}
+	if len(p.Inventory) >= InventorySlots {
+		return 0
+	}
+	n := min(qty, def.MaxStack)
+	p.Inventory = append(p.Inventory, ItemStack{ID: def.ID, Qty: n})
+	return n
+}
+
+// RemoveItem takes qty of an item out of the inventory, dropping the stack
+// when it runs out. It reports false if the player has too few.
+func (p *Player) RemoveItem(id string, qty int) bool {
+	for i := range p.Inventory {
+		s := &p.Inventory[i]
+		if s.ID != id {
+			continue
+		}
+		if s.Qty < qty {
+			return false
+		}
+		s.Qty -= qty
+		if s.Qty == 0 {
+			p.Inventory = append(p.Inventory[:i], p.Inventory[i+1:]...)
+			if p.InventoryCursor >= len(p.Inventory) && p.InventoryCursor > 0 {
+				p.InventoryCursor--
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// ItemCount returns how many of an item the player carries.
+func (p *Player) ItemCount(id string) int {
+	for _, s := range p.Inventory {
+		if s.ID == id {
+			return s.Qty
+		}
+	}
+	return 0
+}
+
+// UseItem consumes one of the item and applies its effect to the player.
+// It returns a description of the result and whether the item was used.
+func (p *Player) UseItem(def ItemDef) (string, bool) {
+	if !def.Usable() {
+		return fmt.Sprintf("%s can't be used.", def.Name), false
+	}
+	if p.ItemCount(def.ID) == 0 {
+		return fmt.Sprintf("%s has no %s left.", p.Name, def.Name), false
+	}
+	hp := min(def.Effect.HP, p.MaxHP-p.HP)
+	sta := min(def.Effect.Stamina, p.MaxStamina-p.Stamina)
+	mp := min(def.Effect.MP, p.MaxMP-p.MP)
+	if hp <= 0 && sta <= 0 && mp <= 0 {
+		return fmt.Sprintf("%s wouldn't do anything right now.", def.Name), false
+	}
+	p.RemoveItem(def.ID, 1)
+	p.HP += max(hp, 0)
+	p.Stamina += max(sta, 0)
+	p.MP += max(mp, 0)
+
+	var gains []string
+	if hp > 0 {
+		gains = append(gains, fmt.Sprintf("+%d HP", hp))
+	}
+	if sta > 0 {
+		gains = append(gains, fmt.Sprintf("+%d STA", sta))
+	}
+	if mp > 0 {
+		gains = append(gains, fmt.Sprintf("+%d MP", mp))
+	}
+	return fmt.Sprintf("%s %s %s (%s)", p.Name, def.UseVerb, def.Name, strings.Join(gains, ", ")), true
+}
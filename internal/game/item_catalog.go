@@ -0,0 +1,167 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"happy-place-2/internal/maps"
+)
+
+// defaultMaxStack applies when an item file omits max_stack.
+const defaultMaxStack = 99
+
+// jsonItem is the on-disk format of an assets/items/*.json file.
+type jsonItem struct {
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Kind        string      `json:"kind"`
+	MaxStack    int         `json:"max_stack,omitempty"`
+	UseVerb     string      `json:"use_verb,omitempty"`
+	Effect      *jsonEffect `json:"effect,omitempty"`
+	Bonus       *jsonBonus  `json:"bonus,omitempty"`
+}
+
+type jsonBonus struct {
+	Attack  int `json:"attack,omitempty"`
+	Defense int `json:"defense,omitempty"`
+	Ranged  int `json:"ranged,omitempty"`
+	Magic   int `json:"magic,omitempty"`
+}
+
+type jsonEffect struct {
+	HP      int `json:"hp,omitempty"`
+	Stamina int `json:"stamina,omitempty"`
+	MP      int `json:"mp,omitempty"`
+}
+
+// LoadItem reads and validates a single item definition file.
+func LoadItem(path string) (ItemDef, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ItemDef{}, fmt.Errorf("read item file: %w", err)
+	}
+
+	var ji jsonItem
+	if err := json.Unmarshal(data, &ji); err != nil {
+		return ItemDef{}, fmt.Errorf("parse item JSON: %w", err)
+	}
+
+	def := ItemDef{
+		ID:          ji.ID,
+		Name:        ji.Name,
+		Description: ji.Description,
+		Kind:        ji.Kind,
+		MaxStack:    ji.MaxStack,
+		UseVerb:     ji.UseVerb,
+	}
+	if def.MaxStack == 0 {
+		def.MaxStack = defaultMaxStack
+	}
+	if def.UseVerb == "" {
+		def.UseVerb = "uses"
+	}
+	if ji.Effect != nil {
+		def.Effect = ItemEffect{HP: ji.Effect.HP, Stamina: ji.Effect.Stamina, MP: ji.Effect.MP}
+	}
+	if ji.Bonus != nil {
+		def.Bonus = StatBonus{Attack: ji.Bonus.Attack, Defense: ji.Bonus.Defense, Ranged: ji.Bonus.Ranged, Magic: ji.Bonus.Magic}
+	}
+	if err := def.validate(); err != nil {
+		return ItemDef{}, err
+	}
+	return def, nil
+}
+
+// validate checks the fields LoadItem cannot fill in with defaults.
+func (d ItemDef) validate() error {
+	switch {
+	case d.ID == "":
+		return fmt.Errorf("missing id")
+	case d.Name == "":
+		return fmt.Errorf("item %q: missing name", d.ID)
+	case d.MaxStack < 1:
+		return fmt.Errorf("item %q: max_stack must be positive, got %d", d.ID, d.MaxStack)
+	}
+	switch d.Kind {
+	case ItemConsumable:
+		e := d.Effect
+		if e.HP < 0 || e.Stamina < 0 || e.MP < 0 || e.HP+e.Stamina+e.MP == 0 {
+			return fmt.Errorf("item %q: consumable needs a positive effect", d.ID)
+		}
+	case ItemMaterial:
+	default:
+		if _, ok := d.Slot(); !ok {
+			return fmt.Errorf("item %q: unknown kind %q", d.ID, d.Kind)
+		}
+	}
+	if _, gear := d.Slot(); !gear && d.Bonus != (StatBonus{}) {
+		return fmt.Errorf("item %q: only weapon, armor and accessory items can have a bonus", d.ID)
+	}
+	return nil
+}
+
+// LoadItems scans a directory for *.json item files and returns them
+// indexed by ID.
+func LoadItems(dir string) (map[string]ItemDef, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read items directory: %w", err)
+	}
+
+	catalog := make(map[string]ItemDef)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		def, err := LoadItem(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("load %s: %w", entry.Name(), err)
+		}
+		if _, exists := catalog[def.ID]; exists {
+			return nil, fmt.Errorf("duplicate item id %q in %s", def.ID, entry.Name())
+		}
+		catalog[def.ID] = def
+	}
+	return catalog, nil
+}
+
+// CheckLoot reports loot table entries that reference items missing from
+// the catalog. Such drops are skipped at runtime.
+func CheckLoot(enemies map[string]EnemyDef, items map[string]ItemDef) []error {
+	var errs []error
+	for _, e := range enemies {
+		for _, d := range e.Loot {
+			if _, ok := items[d.ItemID]; !ok {
+				errs = append(errs, fmt.Errorf("enemy %q drops unknown item %q", e.ID, d.ItemID))
+			}
+		}
+	}
+	return errs
+}
+
+// CheckTiles reports breakable tiles whose break_with item is missing from
+// the catalog, and portals that need a missing item. Nobody can break or
+// pass them.
+func CheckTiles(allMaps map[string]*maps.Map, items map[string]ItemDef) []error {
+	var errs []error
+	for name, m := range allMaps {
+		for k, t := range m.Legend {
+			if t.BreakWith == "" {
+				continue
+			}
+			if _, ok := items[t.BreakWith]; !ok {
+				errs = append(errs, fmt.Errorf("map %q tile %d (%s) breaks with unknown item %q", name, k, t.Name, t.BreakWith))
+			}
+		}
+		for _, p := range m.Portals {
+			if _, ok := items[p.Item]; p.Item != "" && !ok {
+				errs = append(errs, fmt.Errorf("map %q portal at (%d,%d) needs unknown item %q", name, p.X, p.Y, p.Item))
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,78 @@
+package game
+
+// JournalObjective is one objective line in the journal.
+type JournalObjective struct {
+	Text string // includes "(n/max)" progress on the current objective
+	Done bool
+}
+
+// JournalQuest is one journal entry.
+type JournalQuest struct {
+	Name        string
+	Description string
+	Done        bool
+	Objectives  []JournalObjective // completed ones and the current one
+}
+
+// JournalState is the viewer's open quest journal, sent with GameState.
+type JournalState struct {
+	Quests []JournalQuest // active quests first, then finished ones
+	Cursor int
+}
+
+// journalQuests returns the player's known quests in journal order: active
+// first, then finished, each in the order they were started.
+func (gl *GameLoop) journalQuests(p *Player) []*QuestProgress {
+	var active, done []*QuestProgress
+	for _, q := range p.Quests {
+		if _, ok := gl.world.Quests[q.ID]; !ok {
+			continue
+		}
+		if q.Done {
+			done = append(done, q)
+		} else {
+			active = append(active, q)
+		}
+	}
+	return append(active, done...)
+}
+
+// journalState builds the viewer's journal, or nil when it is closed.
+func (gl *GameLoop) journalState(p *Player) *JournalState {
+	if !p.JournalOpen {
+		return nil
+	}
+	js := &JournalState{Cursor: p.JournalCursor}
+	for _, q := range gl.journalQuests(p) {
+		def := gl.world.Quests[q.ID]
+		jq := JournalQuest{Name: def.Name, Description: def.Description, Done: q.Done}
+		for i, o := range def.Objectives {
+			switch {
+			case q.Done || i < q.Stage:
+				jq.Objectives = append(jq.Objectives, JournalObjective{Text: gl.objectiveText(o), Done: true})
+			case i == q.Stage:
+				jq.Objectives = append(jq.Objectives, JournalObjective{Text: gl.objectiveText(o) + gl.objectiveProgress(p, q, o)})
+			}
+		}
+		js.Quests = append(js.Quests, jq)
+	}
+	return js
+}
+
+// processJournalInput handles input while the journal is open: up/down pick
+// an entry and J closes it.
+func (gl *GameLoop) processJournalInput(p *Player, action Action) {
+	n := len(gl.journalQuests(p))
+	switch action {
+	case ActionJournal:
+		p.JournalOpen = false
+	case ActionUp:
+		if n > 0 {
+			p.JournalCursor = (p.JournalCursor + n - 1) % n
+		}
+	case ActionDown:
+		if n > 0 {
+			p.JournalCursor = (p.JournalCursor + 1) % n
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+)
+
+// StatGrowth is a set of increases to a player's base stats.
+type StatGrowth struct {
+	HP      int `json:"hp,omitempty"`
+	Stamina int `json:"stamina,omitempty"`
+	MP      int `json:"mp,omitempty"`
+	Attack  int `json:"attack,omitempty"`
+	Defense int `json:"defense,omitempty"`
+}
+
+// Progression defines the EXP curve and what each level is worth.
+//
+// The EXP needed to go from level L to L+1 is BaseEXP × L^Exponent, rounded.
+type Progression struct {
+	BaseEXP    int        `json:"base_exp"`
+	Exponent   float64    `json:"exponent"`
+	MaxLevel   int        `json:"max_level"`
+	Growth     StatGrowth `json:"growth"`      // applied automatically on every level
+	StatPoints int        `json:"stat_points"` // unallocated points granted per level
+	PointValue StatGrowth `json:"point_value"` // what one allocated point adds
+
+	thresholds []int // thresholds[L] = total EXP needed to reach level L
+}
+
+// DefaultProgression is used when assets/progression.json is missing.
+func DefaultProgression() *Progression {
+	p := &Progression{
+		BaseEXP:    50,
+		Exponent:   1.5,
+		MaxLevel:   50,
+		Growth:     StatGrowth{HP: 4, Stamina: 2, MP: 2, Attack: 1, Defense: 1},
+		StatPoints: 2,
+		PointValue: StatGrowth{HP: 5, Stamina: 3, MP: 3, Attack: 1, Defense: 1},
+	}
+	p.buildThresholds()
+	return p
+}
+
+// LoadProgression reads and validates a progression file.
+func LoadProgression(path string) (*Progression, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read progression file: %w", err)
+	}
+	var p Progression
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse progression JSON: %w", err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	p.buildThresholds()
+	return &p, nil
+}
+
+func (p *Progression) validate() error {
+	switch {
+	case p.BaseEXP < 1:
+		return fmt.Errorf("base_exp must be positive, got %d", p.BaseEXP)
+	case p.Exponent < 0:
+		return fmt.Errorf("exponent must not be negative, got %g", p.Exponent)
+	case p.MaxLevel < 1:
+		return fmt.Errorf("max_level must be positive, got %d", p.MaxLevel)
+	case p.StatPoints < 0:
+		return fmt.Errorf("stat_points must not be negative, got %d", p.StatPoints)
+	}
+	g := p.Growth
+	if g.HP < 0 || g.Stamina < 0 || g.MP < 0 || g.Attack < 0 || g.Defense < 0 {
+		return fmt.Errorf("growth must not be negative")
+	}
+	v := p.PointValue
+	if v.HP < 0 || v.Stamina < 0 || v.MP < 0 || v.Attack < 0 || v.Defense < 0 {
+		return fmt.Errorf("point_value must not be negative")
+	}
+	return nil
+}
+
+func (p *Progression) buildThresholds() {
+	p.thresholds = make([]int, p.MaxLevel+1)
+	for lv := 2; lv <= p.MaxLevel; lv++ {
+		step := int(math.Round(float64(p.BaseEXP) * math.Pow(float64(lv-1), p.Exponent)))
+		p.thresholds[lv] = p.thresholds[lv-1] + max(step, 1)
+	}
+}
+
+// EXPForLevel returns the total EXP needed to reach a level.
+func (p *Progression) EXPForLevel(level int) int {
+	level = min(max(level, 1), p.MaxLevel)
+	return p.thresholds[level]
+}
+
+// LevelProgress returns EXP earned into the current level and the EXP the
+// level spans. The span is 0 at the level cap.
+func (p *Progression) LevelProgress(level, exp int) (into, span int) {
+	if level >= p.MaxLevel {
+		return 0, 0
+	}
+	base := p.EXPForLevel(level)
+	return exp - base, p.EXPForLevel(level+1) - base
+}
+
+// LevelUp is one level gained by a player.
+type LevelUp struct {
+	Name   string
+	Level  int
+	Growth StatGrowth
+	Points int // stat points granted
+}
+
+// String describes the level-up for the combat log, e.g.
+// "alice reached Lv 3! +4 HP +1 ATK".
+func (l LevelUp) String() string {
+	return fmt.Sprintf("%s reached Lv %d! %s", l.Name, l.Level, l.Growth)
+}
+
+// String formats the non-zero increases, e.g. "+4 HP +2 STA +1 ATK".
+func (g StatGrowth) String() string {
+	s := ""
+	for _, part := range []struct {
+		v    int
+		name string
+	}{{g.HP, "HP"}, {g.Stamina, "STA"}, {g.MP, "MP"}, {g.Attack, "ATK"}, {g.Defense, "DEF"}} {
+		if part.v != 0 {
+			if s != "" {
+				s += " "
+			}
+			s += fmt.Sprintf("%+d %s", part.v, part.name)
+		}
+	}
+	return s
+}
+
+// grow raises the player's base stats. Current HP, stamina and MP rise by
+// the same amount as their maximums.
+func (p *Player) grow(g StatGrowth) {
+	p.MaxHP += g.HP
+	p.HP += g.HP
+	p.MaxStamina += g.Stamina
+	p.Stamina += g.Stamina
+	p.MaxMP += g.MP
+	p.MP += g.MP
+	p.Attack += g.Attack
+	p.Defense += g.Defense
+}
+
+// GainEXP adds EXP and applies every level it earns.
+func (p *Player) GainEXP(amount int, prog *Progression) []LevelUp {
+	p.EXP += amount
+	return p.applyLevels(prog)
+}
+
+// applyLevels raises the player's level until it matches their EXP.
+func (p *Player) applyLevels(prog *Progression) []LevelUp {
+	var ups []LevelUp
+	for p.Level < prog.MaxLevel && p.EXP >= prog.EXPForLevel(p.Level+1) {
+		p.Level++
+		p.grow(prog.Growth)
+		p.StatPoints += prog.StatPoints
+		ups = append(ups, LevelUp{Name: p.Name, Level: p.Level, Growth: prog.Growth, Points: prog.StatPoints})
+	}
+	return ups
+}
+
+// Stat identifies a base stat that stat points can be spent on.
+type Stat int
+
+const (
+	StatHP Stat = iota
+	StatStamina
+	StatMP
+	StatAttack
+	StatDefense
+	NumStats
+)
+
+var statLabels = [NumStats]string{"Max HP", "Max STA", "Max MP", "Attack", "Defense"}
+
+// String returns the stat's label on the character sheet.
+func (s Stat) String() string {
+	if s < 0 || s >= NumStats {
+		return "unknown"
+	}
+	return statLabels[s]
+}
+
+// value returns the player's base value for the stat.
+func (s Stat) value(p *Player) int {
+	switch s {
+	case StatHP:
+		return p.MaxHP
+	case StatStamina:
+		return p.MaxStamina
+	case StatMP:
+		return p.MaxMP
+	case StatAttack:
+		return p.Attack
+	case StatDefense:
+		return p.Defense
+	}
+	return 0
+}
+
+// pointGrowth is what one point spent on the stat adds.
+func (s Stat) pointGrowth(prog *Progression) StatGrowth {
+	v := prog.PointValue
+	switch s {
+	case StatHP:
+		return StatGrowth{HP: v.HP}
+	case StatStamina:
+		return StatGrowth{Stamina: v.Stamina}
+	case StatMP:
+		return StatGrowth{MP: v.MP}
+	case StatAttack:
+		return StatGrowth{Attack: v.Attack}
+	case StatDefense:
+		return StatGrowth{Defense: v.Defense}
+	}
+	return StatGrowth{}
+}
+
+// SpendStatPoint allocates one unspent stat point.
+func (p *Player) SpendStatPoint(s Stat, prog *Progression) (string, bool) {
+	if p.StatPoints <= 0 {
+		return "No stat points to spend.", false
+	}
+	g := s.pointGrowth(prog)
+	if g == (StatGrowth{}) {
+		return fmt.Sprintf("%s can't be raised with points.", s), false
+	}
+	p.StatPoints--
+	p.grow(g)
+	return fmt.Sprintf("%s raised to %d. %d point(s) left.", s, s.value(p), p.StatPoints), true
+}
+
+// awardEXP gives each surviving player the fight's EXP and logs any levels
+// gained, for the result screen and the combat log.
+func (gl *GameLoop) awardEXP(fight *Fight) {
+	fight.EXPGained = fight.TotalEXP()
+	fight.AddLog(fmt.Sprintf("Each survivor gains %d EXP.", fight.EXPGained))
+	for _, pid := range fight.LivingPlayers(gl.players) {
+		p := gl.players[pid]
+		for _, up := range p.GainEXP(fight.EXPGained, gl.world.Progress) {
+			fight.LevelUps = append(fight.LevelUps, up)
+			fight.AddLog("★ " + up.String())
+			gl.announceLevelUp(p, up)
+		}
+	}
+}
+
+// announceLevelUp tells the player about a level they just gained and
+// starts the HUD celebration.
+func (gl *GameLoop) announceLevelUp(p *Player, up LevelUp) {
+	p.LevelUpFlash = gl.timing.LevelUpFlash
+	msg := fmt.Sprintf("Level up! You are now Lv %d (%s).", up.Level, up.Growth)
+	if up.Points > 0 {
+		msg += fmt.Sprintf(" %d stat point(s) to spend — press C.", up.Points)
+	}
+	gl.systemMessage(p, msg)
+}
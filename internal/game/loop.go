@@ -1,12 +1,14 @@
 package game
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"math/rand"
-	"sync"
 	"time"
 
 	"happy-place-2/internal/maps"
+	"happy-place-2/internal/store"
 )
 
 const (
@@ -15,66 +17,116 @@ const (
 
 // WorldState holds world-wide info shared across all maps.
 type WorldState struct {
-	TotalPlayers int
-	Tick         uint64
+	TotalPlayers  int
+	Tick          uint64
+	ShutdownTicks int // ticks until the server shuts down, 0 = not shutting down
 }
 
 // MapState holds the state for a single map sent to a session.
 type MapState struct {
 	Map     *maps.Map
 	Players []PlayerSnapshot
+	NPCs    []NPCSnapshot
 }
 
 // GameState is a snapshot sent to each session for rendering.
 type GameState struct {
 	World  WorldState
 	Map    MapState
-	Combat *CombatState // non-nil when the viewer is in combat
+	Combat *CombatState  // non-nil when the viewer is in combat
+	Chat   []ChatMessage // the viewer's recent chat, oldest first
+
+	Inventory *InventoryState // non-nil while the viewer's inventory is on screen
+	Character *CharacterState // non-nil while the viewer's character sheet is open
+	Dialogue  *DialogueState  // non-nil while the viewer is talking to an NPC
+	Journal   *JournalState   // non-nil while the viewer's quest journal is open
 }
 
 // RenderChan is the per-session channel that receives game state snapshots.
 type RenderChan chan GameState
 
-// savedState holds persisted player data for reconnecting players.
-type savedState struct {
-	X, Y    int
-	Color   int
-	MapName string
-
-	HP, MaxHP           int
-	Stamina, MaxStamina int
-	MP, MaxMP           int
-	Attack, Defense     int
-	EXP                 int
-}
-
-// GameLoop is the central game loop singleton.
+// GameLoop is the central game loop singleton. All world state belongs to
+// the goroutine running Run: sessions never touch it directly, but send
+// InputEvents, and joins, leaves and admin commands reach it as requests
+// that run between ticks.
 type GameLoop struct {
 	world   *World
 	inputCh chan InputEvent
+	reqCh   chan func() // joins, leaves and admin commands; see do
 	tickCount uint64
+	timing    *Timing
+	clock     Clock
+	rng       *rand.Rand // every random roll
 
-	mu          sync.RWMutex
 	players     map[string]*Player
 	renderChans map[string]RenderChan
-	saved       map[string]savedState // keyed by username
+	store       store.Store // saved players, keyed by username
+	saver       *saver      // writes saves off the loop goroutine
 
 	fights      map[int]*Fight
 	nextFightID int
+	dupIDs      int // suffixes handed out to players whose ID was taken
+
+	npcs map[string][]*NPC // live NPCs by map name
+
+	shutdownTimer int  // ticks until drain, 0 = no shutdown pending
+	drained       bool // sessions released, no new players accepted
+	drainedCh     chan struct{}
 
 	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// LoopOptions supplies the game loop's timing, clock and randomness. Zero
+// values fall back to DefaultTiming, the system clock and a randomly
+// seeded source; tests pass fixed ones to make a run repeatable.
+type LoopOptions struct {
+	Timing *Timing
+	Clock  Clock
+	Rand   *rand.Rand
+}
+
+// Clock tells the loop the wall-clock time, for save timestamps.
+type Clock interface {
+	Now() time.Time
 }
 
-// NewGameLoop creates and returns a new game loop.
-func NewGameLoop(world *World) *GameLoop {
+// systemClock is the real wall clock.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// NewGameLoop creates and returns a new game loop backed by the given store.
+// A nil store falls back to an in-memory one.
+func NewGameLoop(world *World, st store.Store, opts LoopOptions) *GameLoop {
+	if st == nil {
+		st = store.NewMemoryStore()
+	}
+	if opts.Timing == nil {
+		opts.Timing = DefaultTiming()
+	}
+	if opts.Clock == nil {
+		opts.Clock = systemClock{}
+	}
+	if opts.Rand == nil {
+		opts.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
 	return &GameLoop{
 		world:       world,
+		timing:      opts.Timing,
+		clock:       opts.Clock,
+		rng:         opts.Rand,
 		inputCh:     make(chan InputEvent, InputChanSize),
+		reqCh:       make(chan func()),
 		players:     make(map[string]*Player),
 		renderChans: make(map[string]RenderChan),
-		saved:       make(map[string]savedState),
+		store:       st,
+		saver:       newSaver(st),
 		fights:      make(map[int]*Fight),
+		npcs:        spawnNPCs(world, opts.Timing),
+		drainedCh:   make(chan struct{}),
 		stopCh:      make(chan struct{}),
+		doneCh:      make(chan struct{}),
 	}
 }
 
@@ -83,43 +135,71 @@ func (gl *GameLoop) InputChan() chan<- InputEvent {
 	return gl.inputCh
 }
 
+// Timing returns the loop's timing model. It never changes, so sessions
+// may read it without locking.
+func (gl *GameLoop) Timing() *Timing {
+	return gl.timing
+}
+
+// JoinOptions describes how a session is joining the world.
+type JoinOptions struct {
+	Guest bool // unauthenticated: progress is neither loaded nor saved
+	Admin bool // may use admin commands and the debug combat key
+}
+
 // AddPlayer registers a player using their username as identity.
 // If the username was seen before, position, color, and map are restored.
 // Returns the effective player ID and the render channel.
-func (gl *GameLoop) AddPlayer(name string) (string, RenderChan) {
-	gl.mu.Lock()
-	defer gl.mu.Unlock()
+func (gl *GameLoop) AddPlayer(name string, opts JoinOptions) (string, RenderChan) {
+	var rec *store.PlayerRecord
+	var broken bool
+	if !opts.Guest {
+		rec, broken = gl.loadRecord(name)
+	}
+
+	var id string
+	var ch RenderChan
+	if err := gl.do(func() { id, ch = gl.addPlayer(name, rec, broken, opts) }); err != nil {
+		// The loop is gone: hand back a closed channel so the session exits
+		ch = make(RenderChan)
+		close(ch)
+		return name, ch
+	}
+	return id, ch
+}
 
-	// If this username is already online, add a suffix
+// addPlayer is AddPlayer on the loop goroutine, with the saved record
+// already loaded. broken means a save exists but couldn't be read.
+func (gl *GameLoop) addPlayer(name string, rec *store.PlayerRecord, broken bool, opts JoinOptions) (string, RenderChan) {
+	// A player whose connection dropped mid-fight rejoins the same fight
+	if !opts.Guest {
+		if p := gl.awaitingReconnect(name); p != nil {
+			return p.ID, gl.resume(p, opts)
+		}
+	}
+
+	// If this username is already online, add a suffix. Suffixes count
+	// up and are never reused, so two joins can't share an ID.
 	id := name
-	if _, online := gl.players[id]; online {
-		id = fmt.Sprintf("%s_%04d", name, time.Now().UnixNano()%10000)
+	for gl.players[id] != nil {
+		gl.dupIDs++
+		id = fmt.Sprintf("%s_%d", name, gl.dupIDs)
 	}
 
 	var player *Player
-	if ss, ok := gl.saved[name]; ok {
-		// Validate saved map still exists, fall back to default
-		mapName := ss.MapName
-		if gl.world.GetMap(mapName) == nil {
-			mapName, ss.X, ss.Y = gl.world.SpawnPoint()
+	if rec != nil {
+		player = &Player{ID: id, Name: name}
+		player.applyRecord(rec)
+		player.refreshGear(gl.world)
+		// Catch up on levels earned under an older save or EXP curve
+		if ups := player.applyLevels(gl.world.Progress); len(ups) > 0 {
+			player.LevelUpFlash = gl.timing.LevelUpFlash
+			log.Printf("%s: applied %d pending level(s), now Lv %d", name, len(ups), player.Level)
 		}
-		player = &Player{
-			ID:      id,
-			Name:    name,
-			X:       ss.X,
-			Y:       ss.Y,
-			Color:   ss.Color,
-			MapName: mapName,
+		// Validate saved map still exists, fall back to default
+		if gl.world.GetMap(player.MapName) == nil {
+			player.MapName, player.X, player.Y = gl.world.SpawnPoint()
 		}
-		player.HP = ss.HP
-		player.MaxHP = ss.MaxHP
-		player.Stamina = ss.Stamina
-		player.MaxStamina = ss.MaxStamina
-		player.MP = ss.MP
-		player.MaxMP = ss.MaxMP
-		player.Attack = ss.Attack
-		player.Defense = ss.Defense
-		player.EXP = ss.EXP
 	} else {
 		// Brand new player
 		mapName, spawnX, spawnY := gl.world.SpawnPoint()
@@ -133,36 +213,71 @@ func (gl *GameLoop) AddPlayer(name string) (string, RenderChan) {
 		}
 		player.InitStats()
 	}
+	player.Guest = opts.Guest
+	player.Admin = opts.Admin
+	player.SaveBroken = broken
+	player.initSession()
+	if broken {
+		gl.systemMessage(player, "Your saved character couldn't be loaded, so you're starting fresh. Nothing from this session will be saved — please tell an admin.")
+	}
 
-	gl.players[id] = player
 	ch := make(RenderChan, 2)
+	if gl.drained {
+		// Shutting down: hand back a closed channel so the session exits
+		close(ch)
+		return id, ch
+	}
+	gl.players[id] = player
 	gl.renderChans[id] = ch
 	return id, ch
 }
 
+// TakeOver hands a live player to a new session for the same account,
+// keeping position, fight membership and everything else. The old
+// session's render channel is dropped without being closed; the caller
+// must end that session and keep it from calling RemovePlayer. Reports
+// false if id is no longer online.
+func (gl *GameLoop) TakeOver(id string, opts JoinOptions) (RenderChan, bool) {
+	var ch RenderChan
+	var ok bool
+	gl.do(func() {
+		var p *Player
+		if p, ok = gl.players[id]; ok {
+			ch = gl.reattach(p, opts, "Reconnected — picking up where you left off.")
+		}
+	})
+	return ch, ok
+}
+
+// reattach gives a live player a fresh render channel for a new session.
+func (gl *GameLoop) reattach(p *Player, opts JoinOptions, greeting string) RenderChan {
+	p.Admin = opts.Admin
+	ch := make(RenderChan, 2)
+	if gl.drained {
+		close(ch)
+		return ch
+	}
+	gl.renderChans[p.ID] = ch
+	gl.systemMessage(p, greeting)
+	return ch
+}
+
 // RemovePlayer saves the player's state and unregisters them.
 func (gl *GameLoop) RemovePlayer(id string) {
-	gl.mu.Lock()
-	defer gl.mu.Unlock()
+	gl.do(func() { gl.removePlayer(id) })
+}
 
+func (gl *GameLoop) removePlayer(id string) {
 	if p, ok := gl.players[id]; ok {
-		gl.saved[p.Name] = savedState{
-			X: p.X, Y: p.Y, Color: p.Color, MapName: p.MapName,
-			HP: p.HP, MaxHP: p.MaxHP,
-			Stamina: p.Stamina, MaxStamina: p.MaxStamina,
-			MP: p.MP, MaxMP: p.MaxMP,
-			Attack: p.Attack, Defense: p.Defense,
-			EXP: p.EXP,
-		}
-		// If in combat, remove from fight
-		if p.FightID != 0 {
-			if fight, ok := gl.fights[p.FightID]; ok {
-				fight.RemovePlayer(p.ID)
+		gl.savePlayer(p)
+		// Mid-fight, hold their place for a while in case they reconnect
+		if !gl.holdForReconnect(p) {
+			if p.FightID != 0 {
+				gl.leaveFight(p)
 			}
-			p.FightID = 0
+			p.Dead = false
+			delete(gl.players, id)
 		}
-		p.Dead = false
-		delete(gl.players, id)
 	}
 	if ch, ok := gl.renderChans[id]; ok {
 		close(ch)
@@ -170,24 +285,62 @@ func (gl *GameLoop) RemovePlayer(id string) {
 	}
 }
 
-// Run starts the game loop. Blocks until Stop is called.
+// errLoopStopped is returned by requests the game loop can no longer serve.
+var errLoopStopped = errors.New("game loop stopped")
+
+// do runs fn on the loop goroutine between ticks and waits for it to
+// finish. It fails only once the loop has stopped, in which case fn never
+// runs. It must not be called from the loop goroutine itself.
+func (gl *GameLoop) do(fn func()) error {
+	done := make(chan struct{})
+	select {
+	case gl.reqCh <- func() { fn(); close(done) }:
+	case <-gl.doneCh:
+		return errLoopStopped
+	}
+	<-done
+	return nil
+}
+
+// Run starts the game loop. Blocks until Stop is called, then flushes
+// every online player to the store and waits for the writes. Requests are
+// served as they arrive, between ticks.
 func (gl *GameLoop) Run() {
-	ticker := time.NewTicker(time.Second / TickRate)
-	defer ticker.Stop()
+	defer close(gl.doneCh)
+	go gl.saver.run()
+	defer gl.saver.close()
+	var tickC <-chan time.Time // nil when the timing model is stepped by hand
+	if gl.timing.Interval > 0 {
+		ticker := time.NewTicker(gl.timing.Interval)
+		defer ticker.Stop()
+		tickC = ticker.C
+	}
 
 	for {
 		select {
 		case <-gl.stopCh:
+			gl.saveAll()
 			return
-		case <-ticker.C:
+		case req := <-gl.reqCh:
+			req()
+		case <-tickC:
 			gl.tick()
 		}
 	}
 }
 
-// Stop shuts down the game loop.
+// Stop shuts down the game loop and waits for the final save.
+// Must only be called after Run has been started.
 func (gl *GameLoop) Stop() {
 	close(gl.stopCh)
+	<-gl.doneCh
+}
+
+// Step runs exactly one tick on the loop goroutine and waits for it. It
+// drives the loop by hand, as tests do: Run must be running, usually with a
+// timing Interval of zero so that Step is the only thing that ticks.
+func (gl *GameLoop) Step() {
+	gl.do(gl.tick)
 }
 
 func (gl *GameLoop) tick() {
@@ -204,32 +357,55 @@ drained:
 
 	gl.tickCount++
 
+	// Periodic autosave so a crash loses at most one interval of progress
+	if gl.tickCount%uint64(gl.timing.AutosaveInterval) == 0 {
+		gl.saveAll()
+	}
+
 	// Update animations and interactions for all players
-	gl.mu.RLock()
 	for _, p := range gl.players {
-		updatePlayerAnimation(p)
+		updatePlayerAnimation(p, gl.timing)
+		updateChat(p, gl.timing)
+		gl.tickPortalFade(p)
+		gl.updateRegen(p)
+		if p.FightID == 0 {
+			if p.LevelUpFlash > 0 {
+				p.LevelUpFlash--
+			}
+			gl.updateQuests(p)
+		}
 		p.ActiveInteraction = gl.computeInteraction(p)
 	}
-	gl.mu.RUnlock()
+	gl.updateNPCs()
 
 	// Tick combat state machines
-	gl.mu.RLock()
 	gl.tickCombat()
-	gl.mu.RUnlock()
+
+	// Count down for players who dropped out of a fight
+	gl.tickDisconnected()
+
+	// Advance a pending shutdown; may release all sessions
+	gl.tickShutdown()
 
 	// Build per-player snapshots grouped by map
-	gl.mu.RLock()
 	totalPlayers := len(gl.players)
 
 	// Group player snapshots by map name
 	byMap := make(map[string][]PlayerSnapshot)
 	for _, p := range gl.players {
-		byMap[p.MapName] = append(byMap[p.MapName], p.Snapshot())
+		byMap[p.MapName] = append(byMap[p.MapName], p.Snapshot(gl.world.Progress, gl.timing))
+	}
+	npcsByMap := make(map[string][]NPCSnapshot)
+	for name, npcs := range gl.npcs {
+		for _, n := range npcs {
+			npcsByMap[name] = append(npcsByMap[name], n.Snapshot())
+		}
 	}
 
 	ws := WorldState{
-		TotalPlayers: totalPlayers,
-		Tick:         gl.tickCount,
+		TotalPlayers:  totalPlayers,
+		Tick:          gl.tickCount,
+		ShutdownTicks: gl.shutdownTimer,
 	}
 
 	// Send each player a GameState with only their map's players
@@ -241,7 +417,13 @@ drained:
 			Map: MapState{
 				Map:     m,
 				Players: byMap[p.MapName],
+				NPCs:    npcsByMap[p.MapName],
 			},
+			Chat:      append([]ChatMessage(nil), p.Chat...),
+			Inventory: gl.inventoryState(p),
+			Character: gl.characterState(p),
+			Dialogue:  gl.dialogueState(p),
+			Journal:   gl.journalState(p),
 		}
 		// Attach combat state if player is in a fight
 		if p.FightID != 0 {
@@ -255,11 +437,10 @@ drained:
 			// Drop frame for slow client
 		}
 	}
-	gl.mu.RUnlock()
 }
 
 // updatePlayerAnimation advances animation state each tick.
-func updatePlayerAnimation(p *Player) {
+func updatePlayerAnimation(p *Player, t *Timing) {
 	// Decrement move cooldown
 	if p.MoveCooldown > 0 {
 		p.MoveCooldown--
@@ -275,13 +456,13 @@ func updatePlayerAnimation(p *Player) {
 			p.Anim = AnimIdle
 			p.AnimFrame = 0
 			p.AnimTick = 0
-		} else if p.AnimTick >= WalkFrameInterval {
+		} else if p.AnimTick >= t.WalkFrameInterval {
 			p.AnimFrame = (p.AnimFrame + 1) % 2
 			p.AnimTick = 0
 		}
 	} else {
 		// Idle animation
-		if p.AnimTick >= IdleFrameInterval {
+		if p.AnimTick >= t.IdleFrameInterval {
 			p.AnimFrame = (p.AnimFrame + 1) % 2
 			p.AnimTick = 0
 		}
@@ -290,6 +471,71 @@ func updatePlayerAnimation(p *Player) {
 
 // computeInteraction checks if the player is facing an interaction tile.
 func (gl *GameLoop) computeInteraction(p *Player) *ActiveInteraction {
+	fx, fy := p.facing()
+	if n := gl.npcAt(p.MapName, fx, fy); n != nil && p.Talk == nil {
+		return &ActiveInteraction{WorldX: n.X, WorldY: n.Y, Text: n.Def.Name + " — Enter to talk"}
+	}
+	inter := gl.world.InteractionAt(p.MapName, fx, fy)
+	if inter == nil {
+		if text := gl.portalPrompt(p, fx, fy); text != "" {
+			return &ActiveInteraction{WorldX: fx, WorldY: fy, Text: text}
+		}
+		if text := gl.tilePrompt(p, fx, fy); text != "" {
+			return &ActiveInteraction{WorldX: fx, WorldY: fy, Text: text}
+		}
+		return nil
+	}
+	text := inter.Text
+	switch inter.Type {
+	case maps.InteractionInn:
+		text += " — Enter to rest"
+	case maps.InteractionQuest:
+		text += " — Enter to read"
+	}
+	return &ActiveInteraction{WorldX: inter.X, WorldY: inter.Y, Text: text}
+}
+
+// interact handles Enter in the overworld on whatever the player faces.
+func (gl *GameLoop) interact(p *Player) {
+	fx, fy := p.facing()
+	if n := gl.npcAt(p.MapName, fx, fy); n != nil {
+		gl.startConversation(p, n)
+		return
+	}
+	inter := gl.world.InteractionAt(p.MapName, fx, fy)
+	if inter == nil {
+		gl.useTile(p, fx, fy)
+		return
+	}
+	switch inter.Type {
+	case maps.InteractionInn:
+		if p.HP == p.MaxHP && p.Stamina == p.MaxStamina && p.MP == p.MaxMP {
+			gl.systemMessage(p, "You're already fully rested.")
+			return
+		}
+		p.Rest()
+		gl.systemMessage(p, fmt.Sprintf("You rest at %s. HP, stamina and MP fully restored.", inter.Text))
+	case maps.InteractionQuest:
+		def, ok := gl.world.Quests[inter.Quest]
+		if !ok {
+			return
+		}
+		switch p.questStatus(def.ID) {
+		case maps.QuestActive:
+			gl.systemMessage(p, fmt.Sprintf("You've already taken %q — press J to check your journal.", def.Name))
+		case maps.QuestDone:
+			gl.systemMessage(p, "Nothing new here.")
+		default:
+			if def.Description != "" {
+				gl.systemMessage(p, def.Description)
+			}
+			gl.startQuest(p, def.ID)
+		}
+	}
+}
+
+// facing returns the tile in front of the player.
+func (p *Player) facing() (int, int) {
 	fx, fy := p.X, p.Y
 	switch p.Dir {
 	case DirUp:
@@ -301,18 +547,18 @@ func (gl *GameLoop) computeInteraction(p *Player) *ActiveInteraction {
 	case DirRight:
 		fx++
 	}
-	inter := gl.world.InteractionAt(p.MapName, fx, fy)
-	if inter == nil {
-		return nil
-	}
-	return &ActiveInteraction{WorldX: inter.X, WorldY: inter.Y, Text: inter.Text}
+	return fx, fy
 }
 
 func (gl *GameLoop) processInput(ev InputEvent) {
-	gl.mu.RLock()
 	player, ok := gl.players[ev.PlayerID]
-	gl.mu.RUnlock()
-	if !ok {
+	if !ok || player.Disconnected {
+		return
+	}
+
+	// Chat works everywhere, including mid-fight
+	if ev.Action == ActionChat {
+		gl.processChat(player, ev.Text)
 		return
 	}
 
@@ -322,16 +568,59 @@ func (gl *GameLoop) processInput(ev InputEvent) {
 		return
 	}
 
+	// A portal fade plays out before anything else happens
+	if player.PortalFade > 0 {
+		return
+	}
+
 	// Toggle debug view
 	if ev.Action == ActionDebug {
 		player.DebugView = !player.DebugView
 		return
 	}
 
-	// Debug: force-start combat encounter from anywhere
+	// An open conversation captures input until it ends
+	if player.Talk != nil {
+		gl.processDialogueInput(player, ev.Action)
+		return
+	}
+
+	// Inventory screen captures input until it is closed
+	if player.InventoryOpen {
+		gl.processInventoryInput(player, ev.Action)
+		return
+	}
+	if player.SheetOpen {
+		gl.processSheetInput(player, ev.Action)
+		return
+	}
+	if player.JournalOpen {
+		gl.processJournalInput(player, ev.Action)
+		return
+	}
+	if ev.Action == ActionInventory {
+		player.InventoryOpen = true
+		player.moveInventoryCursor(0)
+		return
+	}
+	if ev.Action == ActionCharacter {
+		player.SheetOpen = true
+		return
+	}
+	if ev.Action == ActionJournal {
+		player.JournalOpen = true
+		player.JournalCursor = 0
+		return
+	}
+
+	// Debug: force-start combat encounter from anywhere (admins only)
 	if ev.Action == ActionDebugCombat {
-		if player.FightID == 0 && !player.Dead {
-			gl.startEncounter(player)
+		if player.Admin && player.FightID == 0 && !player.Dead && !gl.shuttingDown() {
+			table := gl.world.EncounterAt(player.MapName, player.X, player.Y)
+			if table == nil {
+				table = &gl.world.DefaultEncounters
+			}
+			gl.startEncounter(player, table)
 		}
 		return
 	}
@@ -360,9 +649,14 @@ func (gl *GameLoop) processInput(ev InputEvent) {
 		}
 	}
 
+	if ev.Action == ActionConfirm {
+		gl.interact(player)
+		return
+	}
+
 	// Ignore page/combat actions outside debug/combat
 	switch ev.Action {
-	case ActionDebugPage1, ActionDebugPage2, ActionDebugPage3, ActionConfirm, ActionDefend:
+	case ActionDebugPage1, ActionDebugPage2, ActionDebugPage3, ActionDefend, ActionItem:
 		return
 	}
 
@@ -404,21 +698,23 @@ func (gl *GameLoop) processInput(ev InputEvent) {
 		newX++
 	}
 
-	canMove := gl.world.CanMoveTo(player.MapName, newX, newY)
+	// A locked portal blocks like a wall; the popup says why
+	portal := gl.world.PortalAt(player.MapName, newX, newY)
+	if portal != nil && gl.portalLock(player, portal) != "" {
+		return
+	}
+
+	canMove := gl.world.CanMoveTo(player.MapName, newX, newY) && gl.npcAt(player.MapName, newX, newY) == nil
 	if canMove {
 		player.X = newX
 		player.Y = newY
 		player.Anim = AnimWalking
-		player.AnimTimer = WalkAnimDuration
-		player.MoveCooldown = MoveRepeatDelay
+		player.AnimTimer = gl.timing.WalkAnimDuration
+		player.MoveCooldown = gl.timing.MoveRepeatDelay
 		player.AnimTick = 0
 
-		// Check for portal at new position
-		portal := gl.world.PortalAt(player.MapName, newX, newY)
 		if portal != nil {
-			player.MapName = portal.TargetMap
-			player.X = portal.TargetX
-			player.Y = portal.TargetY
+			gl.enterPortal(player, portal)
 		} else {
 			// Check for encounter on tall_grass
 			gl.checkEncounter(player)
@@ -426,48 +722,71 @@ func (gl *GameLoop) processInput(ev InputEvent) {
 	}
 }
 
-// checkEncounter triggers a random combat encounter on tall_grass tiles.
+// checkEncounter rolls for a random combat encounter using the encounter
+// table that covers the player's tile.
 func (gl *GameLoop) checkEncounter(player *Player) {
-	m := gl.world.GetMap(player.MapName)
-	if m == nil {
+	table := gl.world.EncounterAt(player.MapName, player.X, player.Y)
+	if table == nil {
 		return
 	}
-	tile := m.TileAt(player.X, player.Y)
-	if tile.Name != "tall_grass" {
+	if gl.shuttingDown() {
 		return
 	}
-	if rand.Intn(100) >= EncounterChance {
+	if gl.rng.Intn(100) >= table.Chance {
 		return
 	}
-	gl.startEncounter(player)
+	gl.startEncounter(player, table)
 }
 
 // startEncounter creates a fight and pulls all same-map non-combat players in.
-func (gl *GameLoop) startEncounter(trigger *Player) {
+// Enemies are rolled from the table, sized for the gathered party.
+func (gl *GameLoop) startEncounter(trigger *Player, table *maps.EncounterTable) {
+	gl.startFight(trigger, func(partySize, avgLevel int) []EnemyDef {
+		return gl.world.RollEncounter(table, partySize, avgLevel, gl.rng)
+	})
+}
+
+// startFight creates a fight and pulls all same-map non-combat players in.
+// roll picks the enemies once the party is known.
+func (gl *GameLoop) startFight(trigger *Player, roll func(partySize, avgLevel int) []EnemyDef) {
 	gl.nextFightID++
 	fightID := gl.nextFightID
 
-	// Gather all non-combat, non-dead players on the same map
+	// Gather all non-combat, non-dead players on the same map, except any
+	// caught mid-portal
 	playerIDs := []string{trigger.ID}
-	trigger.CombatTransition = CombatTransitionLen
+	trigger.CombatTransition = gl.timing.CombatTransitionLen
 	trigger.FightID = fightID
 	trigger.CombatAction = 0
 	trigger.CombatTarget = 0
+	trigger.InventoryOpen = false
+	trigger.SheetOpen = false
+	trigger.JournalOpen = false
 
 	for _, p := range gl.players {
 		if p.ID == trigger.ID {
 			continue
 		}
-		if p.MapName == trigger.MapName && p.FightID == 0 && !p.Dead {
+		if p.MapName == trigger.MapName && p.FightID == 0 && !p.Dead && p.PortalFade == 0 {
 			p.FightID = fightID
-			p.CombatTransition = CombatCoopTransLen
+			p.CombatTransition = gl.timing.CombatCoopTransLen
 			p.CombatAction = 0
 			p.CombatTarget = 0
+			p.InventoryOpen = false
+			p.SheetOpen = false
+			p.JournalOpen = false
+			p.Talk = nil
 			playerIDs = append(playerIDs, p.ID)
 		}
 	}
 
-	fight := NewFight(fightID, trigger.MapName, playerIDs)
+	levels := 0
+	for _, pid := range playerIDs {
+		levels += gl.players[pid].Level
+	}
+	defs := roll(len(playerIDs), levels/len(playerIDs))
+
+	fight := NewFight(fightID, trigger.MapName, playerIDs, defs, gl.timing)
 	gl.fights[fightID] = fight
 }
 
@@ -512,6 +831,18 @@ func (gl *GameLoop) processCombatInput(player *Player, action Action) {
 		fight.AddLog(msg)
 		gl.advanceCombatTurn(fight)
 		return
+	case ActionItem, ActionInventory: // key '5' = Item
+		player.CombatAction = CombatActionItem
+		player.moveInventoryCursor(0)
+	case ActionUp, ActionDown:
+		// Pick an item while the item list is open
+		if player.CombatAction == CombatActionItem {
+			if action == ActionUp {
+				player.moveInventoryCursor(-1)
+			} else {
+				player.moveInventoryCursor(1)
+			}
+		}
 	case ActionLeft:
 		// Cycle target left
 		if player.CombatTarget > 0 {
@@ -527,6 +858,13 @@ func (gl *GameLoop) processCombatInput(player *Player, action Action) {
 		if player.CombatAction == 0 {
 			return // no action selected
 		}
+		if player.CombatAction == CombatActionItem {
+			if gl.useCombatItem(player, fight) {
+				player.CombatAction = 0
+				gl.advanceCombatTurn(fight)
+			}
+			return
+		}
 		// Clamp target to living enemies
 		if player.CombatTarget >= len(livingEnemies) {
 			player.CombatTarget = 0
@@ -537,11 +875,11 @@ func (gl *GameLoop) processCombatInput(player *Player, action Action) {
 		var msg string
 		switch player.CombatAction {
 		case 1: // Melee
-			_, msg, ok = ResolveMelee(player, target)
+			_, msg, ok = ResolveMelee(player, target, gl.rng)
 		case 2: // Ranged
-			_, msg, ok = ResolveRanged(player, target)
+			_, msg, ok = ResolveRanged(player, target, gl.rng)
 		case 3: // Magic
-			_, msg, ok = ResolveMagic(player, target)
+			_, msg, ok = ResolveMagic(player, target, gl.rng)
 		}
 		if !ok {
 			return // not enough resources
@@ -557,8 +895,11 @@ func (gl *GameLoop) advanceCombatTurn(fight *Fight) {
 	// Check if all enemies are dead
 	if fight.AllEnemiesDead() {
 		fight.Phase = PhaseVictory
-		fight.ResultTimer = CombatResultDelay
+		fight.ResultTimer = gl.timing.CombatResultDelay
 		fight.AddLog("Victory! All enemies defeated!")
+		gl.awardEXP(fight)
+		gl.awardLoot(fight)
+		gl.creditKills(fight)
 		return
 	}
 
@@ -604,6 +945,13 @@ func (gl *GameLoop) tickCombat() {
 			}
 
 		case PhasePlayerTurn:
+			// Players who dropped out defend straight away
+			if p, ok := gl.players[fight.CurrentTurnPlayerID()]; ok && p.Disconnected && !p.Dead {
+				fight.AddLog(ResolveDefend(p) + " (away)")
+				gl.advanceCombatTurn(fight)
+				break
+			}
+
 			// Turn timer countdown
 			fight.TurnTimer--
 			if fight.TurnTimer <= 0 {
@@ -664,27 +1012,33 @@ func (gl *GameLoop) tickEnemyActions(fight *Fight) {
 		if len(living) == 0 {
 			// All players dead
 			fight.Phase = PhaseDefeat
-			fight.ResultTimer = CombatResultDelay
+			fight.ResultTimer = gl.timing.CombatResultDelay
 			fight.AddLog("Defeat! All players have fallen!")
 			return
 		}
 
-		targetID := living[rand.Intn(len(living))]
-		target := gl.players[targetID]
-		_, msg := ResolveEnemyAttack(enemy, target)
-		fight.AddLog(msg)
+		if enemy.Hesitates(gl.rng) {
+			fight.AddLog(fmt.Sprintf("%s hesitates...", enemy.Label))
+		} else {
+			targets := make([]*Player, len(living))
+			for i, pid := range living {
+				targets[i] = gl.players[pid]
+			}
+			_, msg := ResolveEnemyAttack(enemy, enemy.ChooseTarget(targets, gl.rng), gl.rng)
+			fight.AddLog(msg)
+		}
 
 		// Check if all players are now dead
 		if fight.LivingPlayerCount(gl.players) == 0 {
 			fight.Phase = PhaseDefeat
-			fight.ResultTimer = CombatResultDelay
+			fight.ResultTimer = gl.timing.CombatResultDelay
 			fight.AddLog("Defeat! All players have fallen!")
 			return
 		}
 
 		// Delay before next enemy
 		fight.Phase = PhaseEnemyActing
-		fight.EnemyTimer = CombatEnemyActDelay
+		fight.EnemyTimer = gl.timing.CombatEnemyActDelay
 		return
 	}
 
@@ -693,14 +1047,10 @@ func (gl *GameLoop) tickEnemyActions(fight *Fight) {
 	fight.StartPlayerPhase(gl.players)
 }
 
-// resolveFightVictory awards EXP and returns players to the overworld.
+// resolveFightVictory returns players to the overworld.
 func (gl *GameLoop) resolveFightVictory(fight *Fight) {
-	totalEXP := fight.TotalEXP()
 	for _, pid := range fight.PlayerIDs {
 		if p, ok := gl.players[pid]; ok {
-			if !p.Dead {
-				p.EXP += totalEXP
-			}
 			p.FightID = 0
 			p.Dead = false
 			p.Defending = false
@@ -713,21 +1063,37 @@ func (gl *GameLoop) resolveFightVictory(fight *Fight) {
 
 // resolveFightDefeat respawns all players at town with full stats.
 func (gl *GameLoop) resolveFightDefeat(fight *Fight) {
-	mapName, spawnX, spawnY := gl.world.SpawnPoint()
 	for _, pid := range fight.PlayerIDs {
 		if p, ok := gl.players[pid]; ok {
-			p.FightID = 0
-			p.Dead = false
-			p.Defending = false
-			p.CombatAction = 0
-			p.CombatTarget = 0
-			p.CombatTransition = 0
-			p.HP = p.MaxHP
-			p.Stamina = p.MaxStamina
-			p.MP = p.MaxMP
-			p.MapName = mapName
-			p.X = spawnX
-			p.Y = spawnY
+			gl.respawn(p)
+		}
+	}
+}
+
+// respawn clears a player's combat state and returns them to town with
+// full stats.
+func (gl *GameLoop) respawn(p *Player) {
+	p.FightID = 0
+	p.Dead = false
+	p.Defending = false
+	p.CombatAction = 0
+	p.CombatTarget = 0
+	p.CombatTransition = 0
+	p.HP = p.MaxHP
+	p.Stamina = p.MaxStamina
+	p.MP = p.MaxMP
+	p.MapName, p.X, p.Y = gl.world.SpawnPoint()
+}
+
+// leaveFight takes a player out of their fight, passing the turn on if it
+// was theirs.
+func (gl *GameLoop) leaveFight(p *Player) {
+	if fight, ok := gl.fights[p.FightID]; ok {
+		wasTurn := fight.CurrentTurnPlayerID() == p.ID
+		fight.RemovePlayer(p.ID)
+		if wasTurn {
+			gl.advanceCombatTurn(fight)
 		}
 	}
+	p.FightID = 0
 }
@@ -0,0 +1,367 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"happy-place-2/internal/maps"
+)
+
+// room is a 5x5 walled room with the spawn in the middle.
+var room = mapSpec{
+	Name: "Room",
+	Rows: []string{
+		"#####",
+		"#...#",
+		"#...#",
+		"#...#",
+		"#####",
+	},
+	SpawnX: 2, SpawnY: 2,
+}
+
+func TestStepAdvancesOneTick(t *testing.T) {
+	h := newHarness(t, 1, room)
+	id := h.join("alice")
+	before := h.state(id).World.Tick
+
+	h.step(3)
+	if got := h.state(id).World.Tick; got != before+3 {
+		t.Fatalf("tick %d after 3 steps from %d", got, before)
+	}
+}
+
+func TestMovement(t *testing.T) {
+	h := newHarness(t, 1, room)
+	id := h.join("alice")
+	h.at(id, "Room", 2, 2)
+
+	// The first press of a new direction only turns the player
+	h.press(id, ActionRight)
+	h.at(id, "Room", 2, 2)
+	if dir := h.self(id).Dir; dir != DirRight {
+		t.Fatalf("facing %v after pressing right, want %v", dir, DirRight)
+	}
+
+	h.press(id, ActionRight)
+	h.at(id, "Room", 3, 2)
+	if anim := h.self(id).Anim; anim != AnimWalking {
+		t.Fatalf("anim %v after a step, want walking", anim)
+	}
+
+	// Holding a key repeats no faster than the move cooldown
+	h.press(id, ActionUp)
+	h.press(id, ActionUp)
+	h.at(id, "Room", 3, 2)
+	h.step(h.timing.MoveRepeatDelay)
+	h.press(id, ActionUp)
+	h.at(id, "Room", 3, 1)
+
+	// Walls block
+	h.walk(id, ActionUp)
+	h.at(id, "Room", 3, 1)
+
+	// The walk animation settles back to idle
+	h.step(h.timing.WalkAnimDuration)
+	if anim := h.self(id).Anim; anim != AnimIdle {
+		t.Fatalf("anim %v long after the last step, want idle", anim)
+	}
+}
+
+func TestPlayersSeeEachOther(t *testing.T) {
+	h := newHarness(t, 1, room)
+	alice := h.join("alice")
+	bob := h.join("bob")
+
+	if n := len(h.state(alice).Map.Players); n != 2 {
+		t.Fatalf("alice sees %d players, want 2", n)
+	}
+	if total := h.state(bob).World.TotalPlayers; total != 2 {
+		t.Fatalf("total players %d, want 2", total)
+	}
+}
+
+func TestSameNameJoinsGetTheirOwnIDs(t *testing.T) {
+	h := newHarness(t, 1, room)
+	ids := map[string]bool{}
+	for range 3 {
+		id, ch := h.loop.AddPlayer("guest-bob", JoinOptions{Guest: true})
+		if ids[id] {
+			t.Fatalf("two joins got the ID %q", id)
+		}
+		ids[id] = true
+		h.chans[id] = ch
+	}
+	h.step(1)
+	for id := range ids {
+		if total := h.state(id).World.TotalPlayers; total != 3 {
+			t.Fatalf("total players %d, want 3", total)
+		}
+	}
+}
+
+func TestPortals(t *testing.T) {
+	town := mapSpec{
+		Name: "Town",
+		Rows: []string{
+			"#####",
+			"#...#",
+			"#...#",
+			"#####",
+		},
+		SpawnX: 1, SpawnY: 1,
+		Portals: []maps.Portal{{X: 3, Y: 1, TargetMap: "Field", TargetX: 1, TargetY: 2}},
+	}
+	field := mapSpec{
+		Name: "Field",
+		Rows: []string{
+			"####",
+			"#..#",
+			"#..#",
+			"####",
+		},
+		SpawnX: 1, SpawnY: 1,
+		Portals: []maps.Portal{{X: 2, Y: 2, TargetMap: "Town", TargetX: 2, TargetY: 2}},
+	}
+	h := newHarness(t, 1, town, field)
+	alice := h.join("alice")
+	bob := h.join("bob")
+
+	h.press(alice, ActionRight) // turn
+	h.walk(alice, ActionRight)
+	h.walk(alice, ActionRight)
+	h.at(alice, "Field", 1, 2)
+
+	// Snapshots only carry players on the viewer's own map
+	if n := len(h.state(alice).Map.Players); n != 1 {
+		t.Fatalf("alice sees %d players in the field, want 1", n)
+	}
+	if n := len(h.state(bob).Map.Players); n != 1 {
+		t.Fatalf("bob sees %d players in town, want 1", n)
+	}
+
+	// And back again
+	h.walk(alice, ActionRight)
+	h.at(alice, "Town", 2, 2)
+	if n := len(h.state(bob).Map.Players); n != 2 {
+		t.Fatalf("bob sees %d players after alice returns, want 2", n)
+	}
+}
+
+func TestEncounterOnTallGrass(t *testing.T) {
+	meadow := mapSpec{
+		Name: "Meadow",
+		Rows: []string{
+			"#####",
+			"#.,.#",
+			"#####",
+		},
+		SpawnX: 1, SpawnY: 1,
+		Encounters: []map[string]any{encounterOn("dummy")},
+	}
+	h := newHarness(t, 1, meadow)
+	id := h.join("alice")
+
+	h.press(id, ActionRight)
+	h.press(id, ActionRight)
+	c := h.combat(id)
+	if c.Phase != PhaseTransition || !c.Transitioning {
+		t.Fatalf("phase %v (transitioning %v) right after the encounter, want transition", c.Phase, c.Transitioning)
+	}
+	if len(c.Enemies) != 1 || c.Enemies[0].Label != "Dummy" {
+		t.Fatalf("enemies %+v, want one Dummy", c.Enemies)
+	}
+}
+
+func TestNoEncounterOffTallGrass(t *testing.T) {
+	meadow := mapSpec{
+		Name: "Meadow",
+		Rows: []string{
+			"######",
+			"#....#",
+			"######",
+		},
+		SpawnX: 1, SpawnY: 1,
+		Encounters: []map[string]any{encounterOn("dummy")},
+	}
+	h := newHarness(t, 1, meadow)
+	id := h.join("alice")
+
+	h.press(id, ActionRight)
+	for i := 0; i < 3; i++ {
+		h.walk(id, ActionRight)
+	}
+	h.at(id, "Meadow", 4, 1)
+	if h.state(id).Combat != nil {
+		t.Fatal("a fight started on plain grass")
+	}
+}
+
+func TestEncounterChanceUsesLoopRand(t *testing.T) {
+	// A 50% table: whether each step starts a fight depends only on the seed
+	meadow := mapSpec{
+		Name: "Meadow",
+		Rows: []string{
+			"#######",
+			"#,,,,,#",
+			"#######",
+		},
+		SpawnX: 1, SpawnY: 1,
+		Encounters: []map[string]any{{"chance": 50, "enemies": []map[string]any{{"id": "dummy"}}}},
+	}
+	run := func() []bool {
+		h := newHarness(t, 42, meadow)
+		id := h.join("alice")
+		h.press(id, ActionRight)
+		var fights []bool
+		for i := 0; i < 4; i++ {
+			h.walk(id, ActionRight)
+			fights = append(fights, h.state(id).Combat != nil)
+			if h.state(id).Combat != nil {
+				break
+			}
+		}
+		return fights
+	}
+	if first, second := run(), run(); !slices.Equal(first, second) {
+		t.Fatalf("same seed, different runs: %v vs %v", first, second)
+	}
+}
+
+// TestConcurrentSessions plays many sessions at once against a ticking
+// loop: joining, moving, fighting, chatting, admin commands, take-overs and
+// leaving, then a shutdown. It is meant to be run with -race.
+func TestDefaultEncountersAreRats(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	w := NewWorld(nil, map[string]EnemyDef{"rat": EnemyRat, "brute": testEnemies["brute"]}, nil, nil, nil, "")
+	for range 20 {
+		for _, def := range w.RollEncounter(&w.DefaultEncounters, 3, 1, rng) {
+			if def.ID != "rat" {
+				t.Fatalf("the default table rolled %q", def.ID)
+			}
+		}
+	}
+
+	// A catalog without rats still gets fights
+	w = NewWorld(nil, testEnemies, nil, nil, nil, "")
+	if defs := w.RollEncounter(&w.DefaultEncounters, 1, 1, rng); len(defs) != 1 || defs[0].ID == "" {
+		t.Fatalf("rolled %v from a catalog without rats", defs)
+	}
+}
+
+func TestConcurrentSessions(t *testing.T) {
+	field := mapSpec{
+		Name: "Field",
+		Rows: []string{
+			"##########",
+			"#..,,,,..#",
+			"#.,,..,,.#",
+			"#..,,,,..#",
+			"##########",
+		},
+		SpawnX: 1, SpawnY: 1,
+		Encounters: []map[string]any{{"chance": 20, "enemies": []map[string]any{{"id": "post"}, {"id": "dummy"}}}},
+	}
+	h := newHarness(t, 1, field)
+
+	// Tick every millisecond, like a fast server
+	ticking := make(chan struct{})
+	ticked := make(chan struct{})
+	go func() {
+		defer close(ticked)
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticking:
+				return
+			case <-ticker.C:
+				h.loop.Step()
+			}
+		}
+	}()
+
+	actions := []Action{
+		ActionUp, ActionDown, ActionLeft, ActionRight, ActionConfirm, ActionDefend,
+		ActionDebugPage1, ActionDebugPage2, ActionDebugPage3, ActionInventory, ActionCharacter,
+		ActionJournal, ActionItem, ActionDebug, ActionDebugCombat,
+	}
+	const sessions = 32
+	var wg sync.WaitGroup
+	for i := 0; i < sessions; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(int64(i)))
+			// A few accounts are shared, so IDs clash and take-overs happen
+			name := fmt.Sprintf("player%d", i%(sessions/2))
+			id, ch := h.loop.AddPlayer(name, JoinOptions{Admin: i%4 == 0})
+			for n := 0; n < 200; n++ {
+				ev := InputEvent{PlayerID: id, Action: actions[rng.Intn(len(actions))]}
+				if n%25 == 0 {
+					ev = InputEvent{PlayerID: id, Action: ActionChat, Text: "hello"}
+				}
+				h.loop.InputChan() <- ev
+				select {
+				case s, ok := <-ch:
+					if ok && s.Combat != nil {
+						_ = len(s.Combat.Log)
+					}
+				default:
+				}
+				switch n {
+				case 50:
+					h.loop.Inspect(name)
+					h.loop.Fights()
+				case 100:
+					h.loop.GiveEXP(name, 10)
+					h.loop.Broadcast("hi")
+				case 150:
+					if next, ok := h.loop.TakeOver(id, JoinOptions{}); ok {
+						ch = next
+					}
+				}
+			}
+			h.loop.RemovePlayer(id)
+		}(i)
+	}
+	wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := h.loop.Shutdown(ctx, time.Second); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	close(ticking)
+	<-ticked
+}
+
+func TestSnapshotsKeepTheirMapVersion(t *testing.T) {
+	h := newHarness(t, 1, room)
+	id := h.join("alice")
+	before := h.state(id).Map.Map
+
+	var err error
+	h.loop.do(func() { err = h.loop.world.SetTiles("Room", maps.TileChange{X: 3, Y: 2, Tile: 1}) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.step(1)
+	after := h.state(id).Map.Map
+	if !before.IsWalkable(3, 2) || after.IsWalkable(3, 2) {
+		t.Fatalf("(3,2) walkable before %v after %v, want a wall only in the new snapshot", before.IsWalkable(3, 2), after.IsWalkable(3, 2))
+	}
+	if tiles, ok := after.TilesChangedSince(before.Version); !ok || !slices.Equal(tiles, [][2]int{{3, 2}}) {
+		t.Fatalf("changes since the last snapshot: %v %v", tiles, ok)
+	}
+
+	// The new wall blocks movement
+	h.press(id, ActionRight)
+	h.walk(id, ActionRight)
+	h.at(id, "Room", 2, 2)
+}
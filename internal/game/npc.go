@@ -0,0 +1,189 @@
+package game
+
+import "happy-place-2/internal/maps"
+
+// NPC is the live state of a non-player character defined in a map file.
+type NPC struct {
+	Def       *maps.NPC
+	MapName   string
+	X, Y      int
+	Dir       Direction
+	Anim      AnimState
+	AnimFrame int
+
+	pathIdx   int // waypoint the NPC is walking toward
+	moveTimer int // ticks until the next step or the end of a pause
+	animTimer int // ticks left of the walk animation
+}
+
+// NPCSnapshot is a read-only view of an NPC for rendering.
+type NPCSnapshot struct {
+	ID        string
+	Name      string
+	X, Y      int
+	Dir       Direction
+	Anim      AnimState
+	AnimFrame int
+	Color     [3]uint8
+}
+
+// spawnNPCs places every map's NPCs at their starting positions.
+func spawnNPCs(w *World, t *Timing) map[string][]*NPC {
+	byMap := make(map[string][]*NPC)
+	for name, m := range w.Maps {
+		for i := range m.NPCs {
+			def := &m.NPCs[i]
+			byMap[name] = append(byMap[name], &NPC{
+				Def:       def,
+				MapName:   name,
+				X:         def.X,
+				Y:         def.Y,
+				moveTimer: t.NPCPauseTime,
+			})
+		}
+	}
+	return byMap
+}
+
+// Snapshot returns a read-only copy of the NPC.
+func (n *NPC) Snapshot() NPCSnapshot {
+	return NPCSnapshot{
+		ID:        n.Def.ID,
+		Name:      n.Def.Name,
+		X:         n.X,
+		Y:         n.Y,
+		Dir:       n.Dir,
+		Anim:      n.Anim,
+		AnimFrame: n.AnimFrame,
+		Color:     n.Def.Color,
+	}
+}
+
+// npcAt returns the NPC standing on a tile, or nil.
+func (gl *GameLoop) npcAt(mapName string, x, y int) *NPC {
+	for _, n := range gl.npcs[mapName] {
+		if n.X == x && n.Y == y {
+			return n
+		}
+	}
+	return nil
+}
+
+// playerAt reports whether any player stands on a tile.
+func (gl *GameLoop) playerAt(mapName string, x, y int) bool {
+	for _, p := range gl.players {
+		if p.MapName == mapName && p.X == x && p.Y == y {
+			return true
+		}
+	}
+	return false
+}
+
+// updateNPCs walks each NPC along its path. NPCs hold still while someone
+// is talking to them and wait when a player or another NPC is in the way.
+func (gl *GameLoop) updateNPCs() {
+	talking := make(map[*NPC]bool)
+	for _, p := range gl.players {
+		if p.Talk != nil {
+			talking[p.Talk.NPC] = true
+		}
+	}
+	for _, npcs := range gl.npcs {
+		for _, n := range npcs {
+			if n.animTimer > 0 {
+				n.animTimer--
+				if n.animTimer == 0 {
+					n.Anim = AnimIdle
+					n.AnimFrame = 0
+				}
+			}
+			if len(n.Def.Path) == 0 || talking[n] {
+				continue
+			}
+			if n.moveTimer > 0 {
+				n.moveTimer--
+				continue
+			}
+			gl.stepNPC(n)
+		}
+	}
+}
+
+// stepNPC moves the NPC one tile toward its current waypoint, pausing when
+// it arrives.
+func (gl *GameLoop) stepNPC(n *NPC) {
+	target := n.Def.Path[n.pathIdx]
+	if n.X == target.X && n.Y == target.Y {
+		n.pathIdx = (n.pathIdx + 1) % len(n.Def.Path)
+		n.moveTimer = gl.timing.NPCPauseTime
+		return
+	}
+
+	dx, dy := sign(target.X-n.X), sign(target.Y-n.Y)
+	// Prefer the longer axis; fall back to the other one when blocked
+	steps := [][2]int{{dx, 0}, {0, dy}}
+	if abs(target.Y-n.Y) > abs(target.X-n.X) {
+		steps[0], steps[1] = steps[1], steps[0]
+	}
+	for _, s := range steps {
+		if s == [2]int{0, 0} {
+			continue
+		}
+		nx, ny := n.X+s[0], n.Y+s[1]
+		if !gl.world.CanMoveTo(n.MapName, nx, ny) || gl.npcAt(n.MapName, nx, ny) != nil || gl.playerAt(n.MapName, nx, ny) {
+			continue
+		}
+		n.Dir = dirFromStep(s[0], s[1])
+		n.X, n.Y = nx, ny
+		n.Anim = AnimWalking
+		n.AnimFrame = (n.AnimFrame + 1) % 2
+		n.animTimer = gl.timing.WalkAnimDuration
+		break
+	}
+	n.moveTimer = gl.timing.NPCMoveInterval
+}
+
+// dirFromStep returns the facing for a one-tile step.
+func dirFromStep(dx, dy int) Direction {
+	switch {
+	case dx < 0:
+		return DirLeft
+	case dx > 0:
+		return DirRight
+	case dy < 0:
+		return DirUp
+	default:
+		return DirDown
+	}
+}
+
+// opposite returns the direction facing back the other way.
+func (d Direction) opposite() Direction {
+	switch d {
+	case DirUp:
+		return DirDown
+	case DirDown:
+		return DirUp
+	case DirLeft:
+		return DirRight
+	default:
+		return DirLeft
+	}
+}
+
+func sign(v int) int {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	}
+	return 0
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
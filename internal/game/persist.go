@@ -0,0 +1,142 @@
+package game
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"happy-place-2/internal/store"
+)
+
+// record builds the persisted form of the player, stamped with the time it
+// was saved.
+func (p *Player) record(savedAt time.Time) *store.PlayerRecord {
+	inv := make([]store.ItemRecord, len(p.Inventory))
+	for i, s := range p.Inventory {
+		inv[i] = store.ItemRecord{ID: s.ID, Qty: s.Qty}
+	}
+	var equip map[string]string
+	for slot, id := range p.Equipment {
+		if id != "" {
+			if equip == nil {
+				equip = make(map[string]string)
+			}
+			equip[EquipSlot(slot).String()] = id
+		}
+	}
+	var flags []string
+	for f := range p.Flags {
+		flags = append(flags, f)
+	}
+	sort.Strings(flags)
+	var quests []store.QuestRecord
+	for _, q := range p.Quests {
+		quests = append(quests, store.QuestRecord{ID: q.ID, Stage: q.Stage, Count: q.Count, Done: q.Done})
+	}
+	return &store.PlayerRecord{
+		Name:    p.Name,
+		SavedAt: savedAt,
+		MapName: p.MapName,
+		X:       p.X,
+		Y:       p.Y,
+		Color:   p.Color,
+
+		HP:         p.HP,
+		MaxHP:      p.MaxHP,
+		Stamina:    p.Stamina,
+		MaxStamina: p.MaxStamina,
+		MP:         p.MP,
+		MaxMP:      p.MaxMP,
+		Attack:     p.Attack,
+		Defense:    p.Defense,
+		EXP:        p.EXP,
+		Level:      p.Level,
+		StatPoints: p.StatPoints,
+
+		Inventory: inv,
+		Equipment: equip,
+		Flags:     flags,
+		Quests:    quests,
+	}
+}
+
+// applyRecord restores persisted fields onto the player.
+func (p *Player) applyRecord(rec *store.PlayerRecord) {
+	p.MapName = rec.MapName
+	p.X = rec.X
+	p.Y = rec.Y
+	p.Color = rec.Color
+	p.HP = rec.HP
+	p.MaxHP = rec.MaxHP
+	p.Stamina = rec.Stamina
+	p.MaxStamina = rec.MaxStamina
+	p.MP = rec.MP
+	p.MaxMP = rec.MaxMP
+	p.Attack = rec.Attack
+	p.Defense = rec.Defense
+	p.EXP = rec.EXP
+	p.Level = max(rec.Level, 1)
+	p.StatPoints = rec.StatPoints
+	p.Inventory = nil
+	for _, it := range rec.Inventory {
+		if it.Qty > 0 {
+			p.Inventory = append(p.Inventory, ItemStack{ID: it.ID, Qty: it.Qty})
+		}
+	}
+	p.Flags = nil
+	for _, f := range rec.Flags {
+		p.SetFlag(f, true)
+	}
+	p.Quests = nil
+	for _, q := range rec.Quests {
+		p.Quests = append(p.Quests, &QuestProgress{ID: q.ID, Stage: q.Stage, Count: q.Count, Done: q.Done})
+	}
+	p.Equipment = [NumEquipSlots]string{}
+	for name, id := range rec.Equipment {
+		if slot, ok := slotByName(name); ok {
+			p.Equipment[slot] = id
+		}
+	}
+}
+
+// loadRecord fetches a saved record. A record that exists but can't be
+// read, say a truncated file or a save from a newer version, is reported
+// as broken: the player starts fresh, and nothing is saved over the record
+// so it can still be repaired.
+func (gl *GameLoop) loadRecord(name string) (rec *store.PlayerRecord, broken bool) {
+	gl.saver.wait(name)
+	rec, err := gl.store.LoadPlayer(name)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, false
+		}
+		log.Printf("Load %s: %v — starting fresh without saving", name, err)
+		return nil, true
+	}
+	return rec, false
+}
+
+// savePlayer queues the player's current state to be written to the
+// store. Guests are never saved, and neither are players whose save
+// couldn't be read.
+func (gl *GameLoop) savePlayer(p *Player) {
+	if p.Guest || p.SaveBroken {
+		return
+	}
+	gl.saver.queue(p.record(gl.clock.Now()))
+}
+
+// SaveAll writes every online player to the store and waits until every
+// save queued so far is on disk.
+func (gl *GameLoop) SaveAll() {
+	if gl.do(gl.saveAll) == nil {
+		gl.saver.flush()
+	}
+}
+
+func (gl *GameLoop) saveAll() {
+	for _, p := range gl.players {
+		gl.savePlayer(p)
+	}
+}
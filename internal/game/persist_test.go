@@ -0,0 +1,103 @@
+package game
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"happy-place-2/internal/store"
+)
+
+func TestCorruptSaveIsNeverOverwritten(t *testing.T) {
+	dir := t.TempDir()
+	fs, err := store.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "players", "alice.json")
+	corrupt := []byte(`{"version": 2, "name": "alice", "lev`)
+	if err := os.WriteFile(path, corrupt, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := newStoreHarness(t, 1, fs, room)
+	id := h.join("alice")
+	if msg := h.lastChat(id); !strings.Contains(msg, "couldn't be loaded") {
+		t.Fatalf("last chat %q, want a warning about the broken save", msg)
+	}
+
+	// Neither leaving nor a save of everyone writes over the broken record.
+	// SaveAll also waits for anything RemovePlayer queued.
+	h.loop.RemovePlayer(id)
+	h.loop.SaveAll()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(corrupt) {
+		t.Fatalf("save is now %q, want it left as it was", data)
+	}
+}
+
+// slowStore holds every player save until release is closed.
+type slowStore struct {
+	*store.MemoryStore
+	release chan struct{}
+}
+
+func (s slowStore) SavePlayer(rec *store.PlayerRecord) error {
+	<-s.release
+	return s.MemoryStore.SavePlayer(rec)
+}
+
+func TestSlowSavesDontStallTicks(t *testing.T) {
+	st := slowStore{store.NewMemoryStore(), make(chan struct{})}
+	h := newStoreHarness(t, 1, st, room)
+	id := h.join("alice")
+	h.press(id, ActionRight) // turn
+	h.walk(id, ActionRight)
+
+	// Autosaves and leaving queue saves the store can't take yet; the
+	// loop keeps ticking regardless
+	h.step(h.timing.AutosaveInterval)
+	h.loop.RemovePlayer(id)
+	h.join("bob")
+	h.step(h.timing.AutosaveInterval)
+
+	// Shutdown waits for the writes
+	shut := make(chan error, 1)
+	go func() { shut <- h.loop.Shutdown(context.Background(), time.Second) }()
+	for started := false; !started; {
+		h.loop.do(func() { started = h.loop.shuttingDown() })
+	}
+	h.step(h.timing.Ticks(1))
+	select {
+	case err := <-shut:
+		t.Fatalf("Shutdown returned %v before the saves were written", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(st.release)
+	if err := <-shut; err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"alice", "bob"} {
+		if _, err := st.LoadPlayer(name); err != nil {
+			t.Fatalf("load %s after shutdown: %v", name, err)
+		}
+	}
+	if rec, _ := st.LoadPlayer("alice"); rec.X != 3 {
+		t.Fatalf("alice saved at x=%d, want the last position 3", rec.X)
+	}
+}
+
+func TestUnrunLoopStartsNoWriter(t *testing.T) {
+	before := runtime.NumGoroutine()
+	NewGameLoop(NewWorld(nil, testEnemies, nil, nil, nil, ""), nil, LoopOptions{})
+	if after := runtime.NumGoroutine(); after > before {
+		t.Fatalf("%d goroutines after building a loop, %d before", after, before)
+	}
+}
@@ -1,5 +1,7 @@
 package game
 
+import "happy-place-2/internal/maps"
+
 // Action represents a player input action.
 type Action int
 
@@ -17,6 +19,11 @@ const (
 	ActionConfirm
 	ActionDefend
 	ActionDebugCombat
+	ActionChat      // InputEvent.Text holds the line typed in chat mode
+	ActionInventory // toggle the inventory screen
+	ActionItem      // key '5' = use an item in combat
+	ActionCharacter // toggle the character sheet
+	ActionJournal   // toggle the quest journal
 )
 
 // Direction the player is facing.
@@ -47,6 +54,7 @@ type ActiveInteraction struct {
 type InputEvent struct {
 	PlayerID string
 	Action   Action
+	Text     string // payload for text actions such as ActionChat
 }
 
 // Player holds the game state for a connected player.
@@ -56,6 +64,13 @@ type Player struct {
 	X, Y    int
 	Color   int // index into the render color palette
 	MapName string
+	Guest   bool // not bound to an account; never persisted
+	Admin   bool // granted by the server's admin list for this session
+
+	Disconnected   bool // connection dropped mid-fight; place held for CombatReconnectGrace
+	ReconnectTimer int  // ticks left to reconnect before forfeiting the fight
+
+	SaveBroken bool // the saved record couldn't be read, so nothing is saved over it
 
 	Dir          Direction
 	Anim         AnimState
@@ -66,6 +81,8 @@ type Player struct {
 	DebugView         bool
 	DebugPage         int
 	ActiveInteraction *ActiveInteraction
+	PortalFade        int          // ticks left of a portal fade transition
+	FadingTo          *maps.Portal // portal the fade moves the player through; nil once moved
 
 	// Stats
 	HP, MaxHP           int
@@ -73,13 +90,45 @@ type Player struct {
 	MP, MaxMP           int
 	Attack, Defense     int
 	EXP                 int
+	Level               int // levels whose growth has been applied
+	StatPoints          int // earned on level-up, spent on the character sheet
+	LevelUpFlash        int // ticks left of the HUD level-up celebration
+	Regen               maps.Regen // rates in effect this tick, zero in combat
+	RegenTick           int        // ticks since the last regen pulse
+
+	// Inventory
+	Inventory       []ItemStack
+	InventoryOpen   bool // inventory screen shown in the overworld
+	InventoryCursor int  // selected stack index
+
+	// Equipment
+	Equipment   [NumEquipSlots]string // item ID per slot, "" = empty
+	Gear        StatBonus             // derived from Equipment by refreshGear
+	SheetOpen   bool                  // character sheet shown in the overworld
+	SheetCursor int                   // selected sheet row: slots, then stats
+
+	// Dialogue
+	Talk  *Conversation   // open NPC conversation, nil = none
+	Flags map[string]bool // story flags read and set by dialogue
+
+	// Quests
+	Quests        []*QuestProgress // taken quests, in the order they were started
+	JournalOpen   bool             // quest journal shown in the overworld
+	JournalCursor int              // selected journal entry
+
+	// Chat
+	Chat        []ChatMessage // recent messages delivered to this player
+	Speech      string        // bubble shown above the sprite, "" = none
+	SpeechTimer int           // ticks until the bubble disappears
+	ChatTokens  int           // messages that can be sent right now
+	ChatRefill  int           // ticks since the last token was refilled
 
 	// Combat state
 	FightID          int  // 0 = not in combat
 	CombatTransition int  // ticks remaining in transition effect
 	Defending        bool // halves incoming damage this round
 	Dead             bool // dead in current fight (spectating)
-	CombatAction     int  // selected action index (1-4)
+	CombatAction     int  // selected action index (1-5)
 	CombatTarget     int  // selected enemy target index
 }
 
@@ -98,13 +147,9 @@ const DefaultAttack = 6
 // DefaultDefense is the starting defense stat.
 const DefaultDefense = 3
 
-// Level returns the player's level derived from EXP.
-func (p *Player) Level() int {
-	return p.EXP/50 + 1
-}
-
 // InitStats sets default stats for a new player.
 func (p *Player) InitStats() {
+	p.Level = 1
 	p.HP = DefaultHP
 	p.MaxHP = DefaultHP
 	p.Stamina = DefaultStamina
@@ -115,6 +160,11 @@ func (p *Player) InitStats() {
 	p.Defense = DefaultDefense
 }
 
+// initSession resets per-connection state that is never persisted.
+func (p *Player) initSession() {
+	p.ChatTokens = chatBurst
+}
+
 // PlayerSnapshot is a read-only copy of player state for rendering.
 type PlayerSnapshot struct {
 	ID                string
@@ -128,19 +178,27 @@ type PlayerSnapshot struct {
 	DebugView         bool
 	DebugPage         int
 	ActiveInteraction *ActiveInteraction
+	Speech            string
+	Fade              float64 // portal transition: 0 = clear, 1 = black
 
 	HP, MaxHP           int
 	Stamina, MaxStamina int
 	MP, MaxMP           int
 	EXP                 int
 	Level               int
+	EXPInLevel          int // EXP earned since the current level began
+	EXPToNext           int // EXP the current level spans, 0 at the cap
+	StatPoints          int
+	LevelUpFlash        int
+	Regen               maps.Regen
 	FightID             int
 	CombatTransition    int
 	Dead                bool
 }
 
 // Snapshot returns a read-only copy of the player.
-func (p *Player) Snapshot() PlayerSnapshot {
+func (p *Player) Snapshot(prog *Progression, t *Timing) PlayerSnapshot {
+	into, span := prog.LevelProgress(p.Level, p.EXP)
 	return PlayerSnapshot{
 		ID:                p.ID,
 		Name:              p.Name,
@@ -154,6 +212,8 @@ func (p *Player) Snapshot() PlayerSnapshot {
 		DebugView:         p.DebugView,
 		DebugPage:         p.DebugPage,
 		ActiveInteraction: p.ActiveInteraction,
+		Speech:            p.Speech,
+		Fade:              p.fade(t),
 		HP:                p.HP,
 		MaxHP:             p.MaxHP,
 		Stamina:           p.Stamina,
@@ -161,7 +221,12 @@ func (p *Player) Snapshot() PlayerSnapshot {
 		MP:                p.MP,
 		MaxMP:             p.MaxMP,
 		EXP:               p.EXP,
-		Level:             p.Level(),
+		Level:             p.Level,
+		EXPInLevel:        into,
+		EXPToNext:         span,
+		StatPoints:        p.StatPoints,
+		LevelUpFlash:      p.LevelUpFlash,
+		Regen:             p.Regen,
 		FightID:           p.FightID,
 		CombatTransition:  p.CombatTransition,
 		Dead:              p.Dead,
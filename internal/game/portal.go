@@ -0,0 +1,108 @@
+package game
+
+import (
+	"fmt"
+
+	"happy-place-2/internal/maps"
+)
+
+// Stepping onto a portal moves the player to its target tile. A portal
+// with requirements stays shut to players who don't meet them: they bump
+// into it like a wall, and the popup says why while they face it. A fade
+// portal holds the player on it while their screen darkens, moves them
+// halfway through the fade, and then the screen brightens on the new map.
+
+// portalLock returns why the player can't take the portal, or "" if they
+// can.
+func (gl *GameLoop) portalLock(p *Player, portal *maps.Portal) string {
+	var why string
+	switch {
+	case portal.Level > 0 && p.Level < portal.Level:
+		why = fmt.Sprintf("You need to be level %d to pass.", portal.Level)
+	case portal.Item != "" && p.ItemCount(portal.Item) == 0:
+		why = fmt.Sprintf("You need a %s to pass.", gl.world.Item(portal.Item).Name)
+	case !p.meets(portal.Conditions):
+		why = "The way is shut."
+	default:
+		return ""
+	}
+	if portal.LockedText != "" {
+		return portal.LockedText
+	}
+	return why
+}
+
+// portalPrompt returns the popup text for a locked portal the player
+// faces, or "" if there's none.
+func (gl *GameLoop) portalPrompt(p *Player, x, y int) string {
+	portal := gl.world.PortalAt(p.MapName, x, y)
+	if portal == nil {
+		return ""
+	}
+	return gl.portalLock(p, portal)
+}
+
+// enterPortal sends the player through a portal they just stepped onto.
+func (gl *GameLoop) enterPortal(p *Player, portal *maps.Portal) {
+	if portal.Transition == maps.TransitionFade && gl.timing.PortalFadeLen >= 2 {
+		p.PortalFade = gl.timing.PortalFadeLen
+		p.FadingTo = portal
+		return
+	}
+	arrive(p, portal)
+}
+
+// tickPortalFade advances a portal fade, moving the player once the
+// screen is black. A target that is gone or can't be stood on leaves the
+// player where they are.
+func (gl *GameLoop) tickPortalFade(p *Player) {
+	if p.PortalFade == 0 {
+		return
+	}
+	p.PortalFade--
+	if p.FadingTo != nil && p.PortalFade <= gl.timing.PortalFadeLen/2 {
+		to := p.FadingTo
+		if m := gl.world.GetMap(to.TargetMap); m != nil && m.IsWalkable(to.TargetX, to.TargetY) {
+			arrive(p, to)
+		}
+		p.FadingTo = nil
+	}
+}
+
+// arrive puts the player on the portal's target tile, facing the way the
+// portal says.
+func arrive(p *Player, portal *maps.Portal) {
+	p.MapName, p.X, p.Y = portal.TargetMap, portal.TargetX, portal.TargetY
+	if dir, ok := facingDir(portal.Facing); ok {
+		p.Dir = dir
+	}
+}
+
+// fade is how dark the player's screen is during a portal fade, from 0
+// (clear) to 1 (black).
+func (p *Player) fade(t *Timing) float64 {
+	if p.PortalFade == 0 {
+		return 0
+	}
+	half := t.PortalFadeLen / 2
+	if p.FadingTo != nil {
+		return float64(t.PortalFadeLen-p.PortalFade) / float64(t.PortalFadeLen-half)
+	}
+	return float64(p.PortalFade) / float64(half)
+}
+
+// facingDir turns a portal's arrival facing into a direction. It reports
+// false for "", which keeps the player's own.
+func facingDir(f string) (Direction, bool) {
+	switch f {
+	case maps.FaceUp:
+		return DirUp, true
+	case maps.FaceDown:
+		return DirDown, true
+	case maps.FaceLeft:
+		return DirLeft, true
+	case maps.FaceRight:
+		return DirRight, true
+	}
+	return DirDown, false
+}
@@ -0,0 +1,152 @@
+package game
+
+import (
+	"testing"
+
+	"happy-place-2/internal/maps"
+)
+
+// gatehouse returns a town whose exit at (3,1) leads to (1,2) in a field,
+// then the field. exit sets the portal's other options.
+func gatehouse(exit maps.Portal) []mapSpec {
+	exit.X, exit.Y = 3, 1
+	exit.TargetMap, exit.TargetX, exit.TargetY = "Field", 1, 2
+	town := mapSpec{
+		Name: "Town",
+		Rows: []string{
+			"#####",
+			"#...#",
+			"#...#",
+			"#####",
+		},
+		SpawnX: 1, SpawnY: 1,
+		Portals: []maps.Portal{exit},
+	}
+	field := mapSpec{
+		Name: "Field",
+		Rows: []string{
+			"####",
+			"#..#",
+			"#..#",
+			"####",
+		},
+		SpawnX: 1, SpawnY: 1,
+	}
+	return []mapSpec{town, field}
+}
+
+// popup returns the text of the player's interaction popup, or "".
+func (h *harness) popup(id string) string {
+	h.t.Helper()
+	if a := h.self(id).ActiveInteraction; a != nil {
+		return a.Text
+	}
+	return ""
+}
+
+func TestLockedPortal(t *testing.T) {
+	h := newHarness(t, 1, gatehouse(maps.Portal{Level: 2, Item: "key"})...)
+	h.loop.world.Items = map[string]ItemDef{"key": {ID: "key", Name: "Gate Key", Kind: ItemMaterial, MaxStack: 1}}
+	id := h.join("alice")
+
+	h.press(id, ActionRight) // turn
+	h.walk(id, ActionRight)
+	h.walk(id, ActionRight)
+	h.at(id, "Town", 2, 1)
+	if got := h.popup(id); got != "You need to be level 2 to pass." {
+		t.Fatalf("popup %q at a portal needing level 2", got)
+	}
+
+	// Each requirement is checked in turn
+	h.loop.do(func() { h.loop.players[id].Level = 2 })
+	h.walk(id, ActionRight)
+	h.at(id, "Town", 2, 1)
+	if got := h.popup(id); got != "You need a Gate Key to pass." {
+		t.Fatalf("popup %q at level 2 without the key", got)
+	}
+
+	if _, err := h.loop.GiveItem("alice", "key", 1); err != nil {
+		t.Fatal(err)
+	}
+	h.walk(id, ActionRight)
+	h.at(id, "Field", 1, 2)
+}
+
+func TestPortalLockedText(t *testing.T) {
+	h := newHarness(t, 1, gatehouse(maps.Portal{
+		Conditions: []maps.Condition{{Flag: "gate_open"}},
+		LockedText: "The gate is barred.",
+	})...)
+	id := h.join("alice")
+
+	h.press(id, ActionRight) // turn
+	h.walk(id, ActionRight)
+	h.walk(id, ActionRight)
+	h.at(id, "Town", 2, 1)
+	if got := h.popup(id); got != "The gate is barred." {
+		t.Fatalf("popup %q at a barred gate", got)
+	}
+
+	h.loop.do(func() { h.loop.players[id].SetFlag("gate_open", true) })
+	h.step(1)
+	if got := h.popup(id); got != "" {
+		t.Fatalf("popup %q once the gate is open", got)
+	}
+	h.walk(id, ActionRight)
+	h.at(id, "Field", 1, 2)
+}
+
+func TestFadePortal(t *testing.T) {
+	h := newHarness(t, 1, gatehouse(maps.Portal{Transition: maps.TransitionFade, Facing: maps.FaceUp})...)
+	id := h.join("alice")
+	half := h.timing.PortalFadeLen / 2
+
+	h.press(id, ActionRight) // turn
+	h.walk(id, ActionRight)
+	h.press(id, ActionRight)
+
+	// The screen darkens while the player waits on the portal, and input
+	// is ignored
+	h.at(id, "Town", 3, 1)
+	if fade := h.self(id).Fade; fade <= 0 || fade >= 1 {
+		t.Fatalf("fade %v right after stepping on, want it starting", fade)
+	}
+	h.press(id, ActionLeft)
+	h.at(id, "Town", 3, 1)
+	left := h.timing.PortalFadeLen - 2 // ticks of fade to go
+	h.step(left - half - 1)
+	h.at(id, "Town", 3, 1)
+
+	// Halfway through, the player arrives in the dark, facing up
+	h.step(1)
+	h.at(id, "Field", 1, 2)
+	p := h.self(id)
+	if p.Fade != 1 || p.Dir != DirUp {
+		t.Fatalf("fade %v facing %v on arrival, want black and up", p.Fade, p.Dir)
+	}
+
+	h.step(half)
+	if fade := h.self(id).Fade; fade != 0 {
+		t.Fatalf("fade %v after the transition", fade)
+	}
+}
+
+func TestTwoWayPortal(t *testing.T) {
+	h := newHarness(t, 1, gatehouse(maps.Portal{TwoWay: true, Facing: maps.FaceRight})...)
+	id := h.join("alice")
+
+	h.press(id, ActionRight) // turn
+	h.walk(id, ActionRight)
+	h.walk(id, ActionRight)
+	h.at(id, "Field", 1, 2)
+
+	// Arriving on the return portal doesn't use it; stepping back on does
+	h.walk(id, ActionRight)
+	h.at(id, "Field", 2, 2)
+	h.press(id, ActionLeft) // turn
+	h.walk(id, ActionLeft)
+	h.at(id, "Town", 3, 1)
+	if dir := h.self(id).Dir; dir != DirLeft {
+		t.Fatalf("facing %v back in town, want left", dir)
+	}
+}
@@ -0,0 +1,244 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"happy-place-2/internal/maps"
+)
+
+// Objective types.
+const (
+	ObjectiveKill    = "kill"    // defeat Count enemies of type Target
+	ObjectiveReach   = "reach"   // stand within Radius of (X,Y) on map Target
+	ObjectiveTalk    = "talk"    // talk to NPC Target
+	ObjectiveCollect = "collect" // hold Count of item Target, or hand them to DeliverTo
+)
+
+// Objective is one step of a quest. A quest's objectives are completed in
+// order.
+type Objective struct {
+	Kind      string // one of the Objective* types
+	Target    string // enemy ID, map name, NPC ID or item ID, by Kind
+	X, Y      int    // reach only
+	Radius    int    // reach only: tiles of slack in each direction
+	Count     int    // kill and collect
+	DeliverTo string // collect only: NPC who takes the items, "" = just carry them
+	Text      string // journal line; "" = generated from the fields above
+}
+
+// QuestDef defines a quest.
+type QuestDef struct {
+	ID          string
+	Name        string
+	Description string
+	Objectives  []Objective
+	RewardEXP   int
+	RewardItems []ItemStack
+	Effects     []maps.Effect // applied when the quest is completed
+}
+
+// QuestProgress is a player's state on one quest they have taken.
+type QuestProgress struct {
+	ID    string
+	Stage int  // index of the current objective
+	Count int  // kills toward the current objective
+	Done  bool // all objectives complete and the reward paid
+}
+
+// quest returns the player's progress on a quest, or nil if not taken.
+func (p *Player) quest(id string) *QuestProgress {
+	for _, q := range p.Quests {
+		if q.ID == id {
+			return q
+		}
+	}
+	return nil
+}
+
+// questStatus returns maps.QuestActive or maps.QuestDone, or "" if the
+// player has not taken the quest.
+func (p *Player) questStatus(id string) string {
+	q := p.quest(id)
+	switch {
+	case q == nil:
+		return ""
+	case q.Done:
+		return maps.QuestDone
+	}
+	return maps.QuestActive
+}
+
+// startQuest gives the player a quest. It does nothing if the quest is
+// unknown or the player already has it.
+func (gl *GameLoop) startQuest(p *Player, id string) bool {
+	def, ok := gl.world.Quests[id]
+	if !ok || p.quest(id) != nil {
+		return false
+	}
+	q := &QuestProgress{ID: id}
+	p.Quests = append(p.Quests, q)
+	gl.systemMessage(p, fmt.Sprintf("New quest: %s — press J to open your journal.", def.Name))
+	gl.settleQuest(p, q, def, false)
+	return true
+}
+
+// questEvent credits n kills of an enemy type or a talk with an NPC to the
+// player's active quests.
+func (gl *GameLoop) questEvent(p *Player, kind, target string, n int) {
+	for _, q := range p.Quests {
+		def, ok := gl.world.Quests[q.ID]
+		if !ok || q.Done || q.Stage >= len(def.Objectives) {
+			continue
+		}
+		o := def.Objectives[q.Stage]
+		switch {
+		case kind == ObjectiveKill && o.Kind == ObjectiveKill && o.Target == target:
+			q.Count = min(q.Count+n, o.Count)
+			if q.Count < o.Count {
+				gl.systemMessage(p, fmt.Sprintf("%s: %s%s", def.Name, gl.objectiveText(o), gl.objectiveProgress(p, q, o)))
+				continue
+			}
+		case kind == ObjectiveTalk && o.Kind == ObjectiveTalk && o.Target == target:
+		case kind == ObjectiveTalk && o.Kind == ObjectiveCollect && o.DeliverTo == target:
+			if !p.RemoveItem(o.Target, o.Count) {
+				continue
+			}
+			gl.systemMessage(p, fmt.Sprintf("You hand over %d× %s.", o.Count, gl.world.Item(o.Target).Name))
+		default:
+			continue
+		}
+		q.Stage++
+		q.Count = 0
+		gl.settleQuest(p, q, def, true)
+	}
+}
+
+// updateQuests re-checks objectives that depend on where the player stands
+// or what they carry. It runs every tick outside combat.
+func (gl *GameLoop) updateQuests(p *Player) {
+	for _, q := range p.Quests {
+		if def, ok := gl.world.Quests[q.ID]; ok && !q.Done {
+			gl.settleQuest(p, q, def, false)
+		}
+	}
+}
+
+// settleQuest skips past objectives that are already met, then completes
+// the quest or, if it moved on, announces the next objective.
+func (gl *GameLoop) settleQuest(p *Player, q *QuestProgress, def QuestDef, advanced bool) {
+	for q.Stage < len(def.Objectives) && gl.objectiveMet(p, def.Objectives[q.Stage]) {
+		q.Stage++
+		q.Count = 0
+		advanced = true
+	}
+	switch {
+	case q.Stage >= len(def.Objectives):
+		gl.completeQuest(p, q, def)
+	case advanced:
+		o := def.Objectives[q.Stage]
+		gl.systemMessage(p, fmt.Sprintf("%s: %s%s", def.Name, gl.objectiveText(o), gl.objectiveProgress(p, q, o)))
+	}
+}
+
+// objectiveMet reports whether a state-based objective already holds.
+// Kills, talks and deliveries only complete through questEvent.
+func (gl *GameLoop) objectiveMet(p *Player, o Objective) bool {
+	switch o.Kind {
+	case ObjectiveReach:
+		return p.MapName == o.Target && abs(p.X-o.X) <= o.Radius && abs(p.Y-o.Y) <= o.Radius
+	case ObjectiveCollect:
+		return o.DeliverTo == "" && p.ItemCount(o.Target) >= o.Count
+	}
+	return false
+}
+
+// completeQuest pays out the reward and applies the quest's effects.
+func (gl *GameLoop) completeQuest(p *Player, q *QuestProgress, def QuestDef) {
+	q.Done = true
+	q.Count = 0
+	msg := fmt.Sprintf("Quest complete: %s!", def.Name)
+	if def.RewardEXP > 0 {
+		msg += fmt.Sprintf(" +%d EXP", def.RewardEXP)
+	}
+	var full []string
+	for _, it := range def.RewardItems {
+		item := gl.world.Item(it.ID)
+		got := p.AddItem(item, it.Qty)
+		if got > 0 {
+			msg += fmt.Sprintf(", %d× %s", got, item.Name)
+		}
+		if got < it.Qty {
+			full = append(full, item.Name)
+		}
+	}
+	gl.systemMessage(p, msg)
+	if len(full) > 0 {
+		gl.systemMessage(p, "Your bag is full — no room for "+strings.Join(full, ", ")+".")
+	}
+	for _, up := range p.GainEXP(def.RewardEXP, gl.world.Progress) {
+		gl.announceLevelUp(p, up)
+	}
+	gl.apply(p, def.Effects)
+}
+
+// creditKills counts a won fight's enemies toward each survivor's kill
+// objectives.
+func (gl *GameLoop) creditKills(fight *Fight) {
+	kills := make(map[string]int)
+	for _, e := range fight.Enemies {
+		kills[e.Def.ID]++
+	}
+	ids := make([]string, 0, len(kills))
+	for id := range kills {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, pid := range fight.LivingPlayers(gl.players) {
+		p := gl.players[pid]
+		for _, id := range ids {
+			gl.questEvent(p, ObjectiveKill, id, kills[id])
+		}
+	}
+}
+
+// objectiveText is the journal line for an objective.
+func (gl *GameLoop) objectiveText(o Objective) string {
+	if o.Text != "" {
+		return o.Text
+	}
+	switch o.Kind {
+	case ObjectiveKill:
+		return fmt.Sprintf("Defeat %d× %s", o.Count, gl.enemyName(o.Target))
+	case ObjectiveReach:
+		return "Travel to " + o.Target
+	case ObjectiveTalk:
+		return "Talk to " + gl.world.NPCName(o.Target)
+	}
+	item := gl.world.Item(o.Target).Name
+	if o.DeliverTo != "" {
+		return fmt.Sprintf("Bring %d× %s to %s", o.Count, item, gl.world.NPCName(o.DeliverTo))
+	}
+	return fmt.Sprintf("Collect %d× %s", o.Count, item)
+}
+
+// objectiveProgress is the "(n/max)" counter shown after the current
+// objective, or "" for objectives without one.
+func (gl *GameLoop) objectiveProgress(p *Player, q *QuestProgress, o Objective) string {
+	switch o.Kind {
+	case ObjectiveKill:
+		return fmt.Sprintf(" (%d/%d)", q.Count, o.Count)
+	case ObjectiveCollect:
+		return fmt.Sprintf(" (%d/%d)", min(p.ItemCount(o.Target), o.Count), o.Count)
+	}
+	return ""
+}
+
+// enemyName returns an enemy type's display name, or its ID if unknown.
+func (gl *GameLoop) enemyName(id string) string {
+	if def, ok := gl.world.Enemies[id]; ok {
+		return def.Name
+	}
+	return id
+}
@@ -0,0 +1,265 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"happy-place-2/internal/maps"
+)
+
+// jsonQuest is the on-disk format of an assets/quests/*.json file.
+type jsonQuest struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Objectives  []jsonObjective `json:"objectives"`
+	Reward      jsonReward      `json:"reward"`
+	Effects     []maps.Effect   `json:"effects,omitempty"`
+}
+
+type jsonObjective struct {
+	Type   string `json:"type"`
+	Enemy  string `json:"enemy,omitempty"`
+	Item   string `json:"item,omitempty"`
+	NPC    string `json:"npc,omitempty"`
+	Map    string `json:"map,omitempty"`
+	X      int    `json:"x,omitempty"`
+	Y      int    `json:"y,omitempty"`
+	Radius int    `json:"radius,omitempty"`
+	Count  int    `json:"count,omitempty"`
+	Text   string `json:"text,omitempty"`
+}
+
+type jsonReward struct {
+	EXP   int              `json:"exp,omitempty"`
+	Items []jsonRewardItem `json:"items,omitempty"`
+}
+
+type jsonRewardItem struct {
+	ID  string `json:"id"`
+	Qty int    `json:"qty,omitempty"`
+}
+
+// LoadQuest reads and validates a single quest definition file.
+func LoadQuest(path string) (QuestDef, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return QuestDef{}, fmt.Errorf("read quest file: %w", err)
+	}
+
+	var jq jsonQuest
+	if err := json.Unmarshal(data, &jq); err != nil {
+		return QuestDef{}, fmt.Errorf("parse quest JSON: %w", err)
+	}
+
+	def := QuestDef{
+		ID:          jq.ID,
+		Name:        jq.Name,
+		Description: jq.Description,
+		RewardEXP:   jq.Reward.EXP,
+		Effects:     jq.Effects,
+	}
+	for _, jo := range jq.Objectives {
+		o := Objective{Kind: jo.Type, X: jo.X, Y: jo.Y, Radius: jo.Radius, Count: jo.Count, Text: jo.Text}
+		switch jo.Type {
+		case ObjectiveKill:
+			o.Target = jo.Enemy
+		case ObjectiveReach:
+			o.Target = jo.Map
+		case ObjectiveTalk:
+			o.Target = jo.NPC
+		case ObjectiveCollect:
+			o.Target = jo.Item
+			o.DeliverTo = jo.NPC
+		}
+		if o.Count == 0 {
+			o.Count = 1
+		}
+		def.Objectives = append(def.Objectives, o)
+	}
+	for _, ji := range jq.Reward.Items {
+		qty := ji.Qty
+		if qty == 0 {
+			qty = 1
+		}
+		def.RewardItems = append(def.RewardItems, ItemStack{ID: ji.ID, Qty: qty})
+	}
+	if err := def.validate(); err != nil {
+		return QuestDef{}, err
+	}
+	return def, nil
+}
+
+// validate checks the fields LoadQuest cannot fill in with defaults.
+// References to maps, enemies, items and NPCs are checked by CheckQuests.
+func (d QuestDef) validate() error {
+	switch {
+	case d.ID == "":
+		return fmt.Errorf("missing id")
+	case d.Name == "":
+		return fmt.Errorf("quest %q: missing name", d.ID)
+	case len(d.Objectives) == 0:
+		return fmt.Errorf("quest %q: no objectives", d.ID)
+	case d.RewardEXP < 0:
+		return fmt.Errorf("quest %q: negative reward exp", d.ID)
+	}
+	for i, o := range d.Objectives {
+		switch o.Kind {
+		case ObjectiveKill, ObjectiveReach, ObjectiveTalk, ObjectiveCollect:
+		default:
+			return fmt.Errorf("quest %q: objective %d: unknown type %q", d.ID, i+1, o.Kind)
+		}
+		switch {
+		case o.Target == "":
+			return fmt.Errorf("quest %q: objective %d: missing %s", d.ID, i+1, o.targetField())
+		case o.Count < 1:
+			return fmt.Errorf("quest %q: objective %d: count must be positive, got %d", d.ID, i+1, o.Count)
+		case o.Radius < 0:
+			return fmt.Errorf("quest %q: objective %d: negative radius", d.ID, i+1)
+		}
+	}
+	for _, it := range d.RewardItems {
+		if it.ID == "" || it.Qty < 1 {
+			return fmt.Errorf("quest %q: reward items need an id and a positive qty", d.ID)
+		}
+	}
+	for _, e := range d.Effects {
+		if err := e.Validate(); err != nil {
+			return fmt.Errorf("quest %q: %w", d.ID, err)
+		}
+	}
+	return nil
+}
+
+// targetField names the JSON field that holds an objective's target.
+func (o Objective) targetField() string {
+	switch o.Kind {
+	case ObjectiveKill:
+		return "enemy"
+	case ObjectiveReach:
+		return "map"
+	case ObjectiveTalk:
+		return "npc"
+	default:
+		return "item"
+	}
+}
+
+// LoadQuests scans a directory for *.json quest files and returns them
+// indexed by ID.
+func LoadQuests(dir string) (map[string]QuestDef, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read quests directory: %w", err)
+	}
+
+	catalog := make(map[string]QuestDef)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		def, err := LoadQuest(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("load %s: %w", entry.Name(), err)
+		}
+		if _, exists := catalog[def.ID]; exists {
+			return nil, fmt.Errorf("duplicate quest id %q in %s", def.ID, entry.Name())
+		}
+		catalog[def.ID] = def
+	}
+	return catalog, nil
+}
+
+// CheckQuests reports quest objectives and rewards that reference unknown
+// maps, tiles, enemies, items or NPCs, and map dialogue, interactions or
+// portals that reference unknown quests. Such quests can still be taken but may
+// never complete.
+func CheckQuests(quests map[string]QuestDef, allMaps map[string]*maps.Map, enemies map[string]EnemyDef, items map[string]ItemDef) []error {
+	var errs []error
+	npcExists := func(id string) bool {
+		for _, m := range allMaps {
+			if m.NPCByID(id) != nil {
+				return true
+			}
+		}
+		return false
+	}
+	for _, q := range quests {
+		for i, o := range q.Objectives {
+			where := fmt.Sprintf("quest %q objective %d", q.ID, i+1)
+			switch o.Kind {
+			case ObjectiveKill:
+				if _, ok := enemies[o.Target]; !ok {
+					errs = append(errs, fmt.Errorf("%s: unknown enemy %q", where, o.Target))
+				}
+			case ObjectiveReach:
+				m, ok := allMaps[o.Target]
+				if !ok {
+					errs = append(errs, fmt.Errorf("%s: unknown map %q", where, o.Target))
+				} else if !m.IsWalkable(o.X, o.Y) {
+					errs = append(errs, fmt.Errorf("%s: (%d,%d) in %q is not walkable", where, o.X, o.Y, o.Target))
+				}
+			case ObjectiveTalk:
+				if !npcExists(o.Target) {
+					errs = append(errs, fmt.Errorf("%s: unknown NPC %q", where, o.Target))
+				}
+			case ObjectiveCollect:
+				if _, ok := items[o.Target]; !ok {
+					errs = append(errs, fmt.Errorf("%s: unknown item %q", where, o.Target))
+				}
+				if o.DeliverTo != "" && !npcExists(o.DeliverTo) {
+					errs = append(errs, fmt.Errorf("%s: unknown NPC %q", where, o.DeliverTo))
+				}
+			}
+		}
+		for _, it := range q.RewardItems {
+			if _, ok := items[it.ID]; !ok {
+				errs = append(errs, fmt.Errorf("quest %q rewards unknown item %q", q.ID, it.ID))
+			}
+		}
+		errs = append(errs, checkQuestRefs(fmt.Sprintf("quest %q", q.ID), nil, q.Effects, quests)...)
+	}
+
+	for name, m := range allMaps {
+		for _, inter := range m.Interactions {
+			if inter.Type != maps.InteractionQuest {
+				continue
+			}
+			if _, ok := quests[inter.Quest]; !ok {
+				errs = append(errs, fmt.Errorf("map %q interaction at (%d,%d) gives unknown quest %q", name, inter.X, inter.Y, inter.Quest))
+			}
+		}
+		for _, p := range m.Portals {
+			errs = append(errs, checkQuestRefs(fmt.Sprintf("map %q portal at (%d,%d)", name, p.X, p.Y), p.Conditions, nil, quests)...)
+		}
+		for _, n := range m.NPCs {
+			for id, node := range n.Dialogue.Nodes {
+				where := fmt.Sprintf("map %q npc %q node %q", name, n.ID, id)
+				errs = append(errs, checkQuestRefs(where, nil, node.Effects, quests)...)
+				for _, c := range node.Choices {
+					errs = append(errs, checkQuestRefs(where, c.Conditions, c.Effects, quests)...)
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// checkQuestRefs reports conditions and effects naming unknown quests.
+func checkQuestRefs(where string, conds []maps.Condition, effects []maps.Effect, quests map[string]QuestDef) []error {
+	var errs []error
+	for _, c := range conds {
+		if _, ok := quests[c.Quest]; c.Quest != "" && !ok {
+			errs = append(errs, fmt.Errorf("%s: condition on unknown quest %q", where, c.Quest))
+		}
+	}
+	for _, e := range effects {
+		if _, ok := quests[e.StartQuest]; e.StartQuest != "" && !ok {
+			errs = append(errs, fmt.Errorf("%s: starts unknown quest %q", where, e.StartQuest))
+		}
+	}
+	return errs
+}
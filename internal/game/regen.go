@@ -0,0 +1,44 @@
+package game
+
+import "happy-place-2/internal/maps"
+
+// DefaultRegen applies on tiles whose legend entry has no regen rates.
+var DefaultRegen = maps.Regen{HP: 1, Stamina: 2, MP: 1}
+
+// RegenAt returns the regeneration rates for the tile at (x, y).
+func (w *World) RegenAt(mapName string, x, y int) maps.Regen {
+	m := w.GetMap(mapName)
+	if m == nil {
+		return maps.Regen{}
+	}
+	if r := m.TileAt(x, y).Regen; r != nil {
+		return *r
+	}
+	return DefaultRegen
+}
+
+// updateRegen restores resources every RegenInterval ticks outside combat.
+// p.Regen holds the rates currently in effect, for the HUD.
+func (gl *GameLoop) updateRegen(p *Player) {
+	if p.FightID != 0 {
+		p.Regen = maps.Regen{}
+		p.RegenTick = 0
+		return
+	}
+	p.Regen = gl.world.RegenAt(p.MapName, p.X, p.Y)
+	p.RegenTick++
+	if p.RegenTick < gl.timing.RegenInterval {
+		return
+	}
+	p.RegenTick = 0
+	p.HP = min(p.HP+p.Regen.HP, p.MaxHP)
+	p.Stamina = min(p.Stamina+p.Regen.Stamina, p.MaxStamina)
+	p.MP = min(p.MP+p.Regen.MP, p.MaxMP)
+}
+
+// Rest fully restores HP, stamina and MP.
+func (p *Player) Rest() {
+	p.HP = p.MaxHP
+	p.Stamina = p.MaxStamina
+	p.MP = p.MaxMP
+}
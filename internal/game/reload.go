@@ -0,0 +1,117 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"happy-place-2/internal/maps"
+)
+
+// ReloadMaps loads every map in dir, validates the set, and swaps it into
+// the world between ticks. Players left on a missing map or an unwalkable
+// tile are moved to the spawn point; the count is returned. If loading or
+// validation fails, the current maps stay in place.
+func (gl *GameLoop) ReloadMaps(dir string) (int, error) {
+	newMaps, err := maps.LoadMaps(dir)
+	if err != nil {
+		return 0, err
+	}
+	if err := gl.checkMaps(newMaps); err != nil {
+		return 0, err
+	}
+
+	var relocated int
+	if err := gl.do(func() { relocated = gl.applyMapSwap(newMaps) }); err != nil {
+		return 0, err
+	}
+	return relocated, nil
+}
+
+// checkMaps validates a freshly loaded map set before it replaces the
+// current one. Quest and item references are only logged, as at startup.
+func (gl *GameLoop) checkMaps(newMaps map[string]*maps.Map) error {
+	if _, ok := newMaps[gl.world.DefaultMap]; !ok {
+		return fmt.Errorf("default map %q is missing", gl.world.DefaultMap)
+	}
+	var errs []error
+	for name, m := range newMaps {
+		for _, err := range m.Validate(newMaps) {
+			errs = append(errs, fmt.Errorf("map %q: %w", name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	for _, err := range CheckQuests(gl.world.Quests, newMaps, gl.world.Enemies, gl.world.Items) {
+		log.Printf("Quests: %v", err)
+	}
+	for _, err := range CheckTiles(newMaps, gl.world.Items) {
+		log.Printf("Tiles: %v", err)
+	}
+	return nil
+}
+
+// applyMapSwap replaces the world's maps, respawns NPCs from the new
+// definitions and relocates players whose position is no longer valid.
+func (gl *GameLoop) applyMapSwap(newMaps map[string]*maps.Map) int {
+	reset := gl.carryTiles(newMaps)
+	gl.world.Maps = newMaps
+	gl.npcs = spawnNPCs(gl.world, gl.timing)
+
+	relocated := 0
+	for _, p := range gl.players {
+		p.Talk = nil // the NPC may be gone or have new lines
+		if p.FadingTo != nil {
+			// Mid-fade: follow the portal now on the tile, or stay put
+			p.FadingTo = gl.world.PortalAt(p.MapName, p.X, p.Y)
+			if p.FadingTo == nil {
+				p.PortalFade = 0
+			}
+		}
+		if m := newMaps[p.MapName]; m != nil && m.IsWalkable(p.X, p.Y) {
+			if reset[p.MapName] {
+				gl.systemMessage(p, "The map was redrawn — doors, levers and rocks here are back as they started.")
+			}
+			continue
+		}
+		from := p.MapName
+		p.MapName, p.X, p.Y = gl.world.SpawnPoint()
+		p.PortalFade, p.FadingTo = 0, nil
+		relocated++
+		log.Printf("Map reload: moved %s from %s to the spawn point", p.Name, from)
+		gl.systemMessage(p, "The world shifted under your feet — you've been returned to "+p.MapName+".")
+	}
+	return relocated
+}
+
+// carryTiles keeps the doors, levers and rocks players have changed on
+// maps whose tiles and legend the reload left alone. A map that was edited
+// starts over from its file; those are returned so players on them can be
+// told.
+func (gl *GameLoop) carryTiles(newMaps map[string]*maps.Map) (reset map[string]bool) {
+	for name, m := range newMaps {
+		old := gl.world.Maps[name]
+		if old == nil {
+			continue
+		}
+		changes := old.TileChanges()
+		if len(changes) == 0 {
+			continue
+		}
+		if m.SameLayout(old) {
+			next, err := m.WithTiles(changes)
+			if err == nil {
+				newMaps[name] = next
+				continue
+			}
+			log.Printf("Map reload: keep tile changes on %s: %v", name, err)
+		}
+		if reset == nil {
+			reset = make(map[string]bool)
+		}
+		reset[name] = true
+		log.Printf("Map reload: %s changed, %d runtime tile changes reset", name, len(changes))
+	}
+	return reset
+}
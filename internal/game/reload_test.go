@@ -0,0 +1,97 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"happy-place-2/internal/maps"
+)
+
+func TestReloadKeepsOpenDoors(t *testing.T) {
+	h := newHarness(t, 1, hall)
+	alice := h.join("alice")
+	bob := h.join("bob")
+	h.press(alice, ActionRight) // turn
+	h.press(alice, ActionConfirm)
+	h.walk(alice, ActionRight)
+	h.at(alice, "Hall", 3, 1)
+
+	// The map file hasn't changed, so the door stays open with alice in it
+	moved, err := h.loop.ReloadMaps(mapDir(t, hall))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.step(1)
+	if moved != 0 {
+		t.Fatalf("reload moved %d players", moved)
+	}
+	h.at(alice, "Hall", 3, 1)
+	if name := h.tileName(bob, 3, 1); name != "door_open" {
+		t.Fatalf("door %q after reloading the same map", name)
+	}
+
+	// An edited map starts over: the door closes, alice is moved out of it,
+	// and bob is told why the door shut
+	edited := hall
+	edited.Rows = []string{
+		"#######",
+		"#..3.##",
+		"#######",
+	}
+	moved, err = h.loop.ReloadMaps(mapDir(t, edited))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.step(1)
+	if moved != 1 {
+		t.Fatalf("reload moved %d players, want alice", moved)
+	}
+	h.at(alice, "Hall", 2, 1)
+	if name := h.tileName(bob, 3, 1); name != "door" {
+		t.Fatalf("door %q after the map was edited", name)
+	}
+	if msg := h.lastChat(bob); !strings.Contains(msg, "back as they started") {
+		t.Fatalf("bob was told %q", msg)
+	}
+}
+
+func TestReloadDuringPortalFade(t *testing.T) {
+	fade := maps.Portal{Transition: maps.TransitionFade}
+	// start puts alice on the fade portal, her screen darkening
+	start := func() (*harness, string) {
+		h := newHarness(t, 1, gatehouse(fade)...)
+		id := h.join("alice")
+		h.press(id, ActionRight) // turn
+		h.walk(id, ActionRight)
+		h.press(id, ActionRight)
+		h.at(id, "Town", 3, 1)
+		return h, id
+	}
+
+	// The reload walls off the old landing tile and moves the portal's
+	// target; the fade follows the new portal
+	h, id := start()
+	moved := gatehouse(fade)
+	moved[0].Portals[0].TargetX, moved[0].Portals[0].TargetY = 2, 1
+	moved[1].Rows[2] = "##.#"
+	if _, err := h.loop.ReloadMaps(mapDir(t, moved...)); err != nil {
+		t.Fatal(err)
+	}
+	h.step(h.timing.PortalFadeLen)
+	h.at(id, "Field", 2, 1)
+
+	// A reload that takes the portal away ends the fade where alice stands
+	h, id = start()
+	gone := gatehouse(fade)
+	gone[0].Portals = nil
+	if _, err := h.loop.ReloadMaps(mapDir(t, gone...)); err != nil {
+		t.Fatal(err)
+	}
+	h.step(1)
+	h.at(id, "Town", 3, 1)
+	if fade := h.self(id).Fade; fade != 0 {
+		t.Fatalf("fade %v after the portal went away", fade)
+	}
+	h.step(h.timing.PortalFadeLen)
+	h.at(id, "Town", 3, 1)
+}
@@ -0,0 +1,108 @@
+package game
+
+import (
+	"log"
+	"strings"
+	"sync"
+
+	"happy-place-2/internal/store"
+)
+
+// saver writes player records to the store on its own goroutine, so a slow
+// disk never holds up a tick. The loop builds each record, which is a cheap
+// copy, and queues it. Records queued for the same player before the
+// writer gets to them collapse into the newest.
+type saver struct {
+	store store.Store
+
+	mu       sync.Mutex
+	cond     *sync.Cond                     // signalled when work arrives and when a write ends
+	pending  map[string]*store.PlayerRecord // by lowercased name, as the store keys them
+	order    []string                       // keys in pending, oldest first
+	inFlight string                         // key being written, "" when idle
+	closed   bool
+	done     chan struct{}
+}
+
+// newSaver returns a writer for st. Records queue up until run is started.
+func newSaver(st store.Store) *saver {
+	s := &saver{
+		store:   st,
+		pending: make(map[string]*store.PlayerRecord),
+		done:    make(chan struct{}),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// run writes queued records until close. The game loop runs it alongside
+// Run, so a loop that is never run starts no goroutine.
+func (s *saver) run() {
+	defer close(s.done)
+	s.mu.Lock()
+	for {
+		for len(s.order) == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if len(s.order) == 0 {
+			s.mu.Unlock()
+			return
+		}
+		key := s.order[0]
+		s.order = s.order[1:]
+		rec := s.pending[key]
+		delete(s.pending, key)
+		s.inFlight = key
+		s.mu.Unlock()
+
+		if err := s.store.SavePlayer(rec); err != nil {
+			log.Printf("Save %s: %v", rec.Name, err)
+		}
+
+		s.mu.Lock()
+		s.inFlight = ""
+		s.cond.Broadcast()
+	}
+}
+
+// queue hands a record to the writer. It never blocks on the store.
+func (s *saver) queue(rec *store.PlayerRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := strings.ToLower(rec.Name)
+	if _, ok := s.pending[key]; !ok {
+		s.order = append(s.order, key)
+	}
+	s.pending[key] = rec
+	s.cond.Broadcast()
+}
+
+// wait blocks until nothing is queued or being written for name, so a
+// load that follows sees the latest save.
+func (s *saver) wait(name string) {
+	key := strings.ToLower(name)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for s.pending[key] != nil || s.inFlight == key {
+		s.cond.Wait()
+	}
+}
+
+// flush blocks until every record queued so far has been written.
+func (s *saver) flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.order) > 0 || s.inFlight != "" {
+		s.cond.Wait()
+	}
+}
+
+// close writes whatever is still queued and stops the writer. run must
+// have been started.
+func (s *saver) close() {
+	s.mu.Lock()
+	s.closed = true
+	s.cond.Broadcast()
+	s.mu.Unlock()
+	<-s.done
+}
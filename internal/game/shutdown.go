@@ -0,0 +1,101 @@
+package game
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Shutdown announces a countdown of d to every session, then settles any
+// fights still running, saves all players and closes every render channel so
+// sessions exit. It returns once sessions have been released and every save
+// is written, or when ctx expires. New encounters are suppressed for the
+// duration of the countdown.
+func (gl *GameLoop) Shutdown(ctx context.Context, d time.Duration) error {
+	err := gl.do(func() {
+		if gl.shutdownTimer == 0 && !gl.drained {
+			gl.shutdownTimer = gl.timing.Ticks(d.Seconds())
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-gl.drainedCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	// The drain only queued the final saves; wait for the writer
+	saved := make(chan struct{})
+	go func() {
+		gl.saver.flush()
+		close(saved)
+	}()
+	select {
+	case <-saved:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// shuttingDown reports whether a shutdown countdown is in progress.
+func (gl *GameLoop) shuttingDown() bool {
+	return gl.shutdownTimer > 0 || gl.drained
+}
+
+// tickShutdown advances the shutdown countdown and drains the world when it
+// reaches zero.
+func (gl *GameLoop) tickShutdown() {
+	if gl.shutdownTimer == 0 || gl.drained {
+		return
+	}
+	gl.shutdownTimer--
+	if gl.shutdownTimer > 0 {
+		return
+	}
+
+	// Settle fights: finished ones pay out as usual, the rest are called off
+	for fid, fight := range gl.fights {
+		switch fight.Phase {
+		case PhaseVictory:
+			gl.resolveFightVictory(fight)
+		case PhaseDefeat:
+			gl.resolveFightDefeat(fight)
+		default:
+			gl.abortFight(fight)
+		}
+		delete(gl.fights, fid)
+	}
+
+	for _, p := range gl.players {
+		gl.savePlayer(p)
+	}
+	for id, ch := range gl.renderChans {
+		close(ch)
+		delete(gl.renderChans, id)
+	}
+	gl.drained = true
+	close(gl.drainedCh)
+	log.Printf("Game loop drained: saving %d players", len(gl.players))
+}
+
+// abortFight releases every player from a fight without rewards or penalties.
+// They stay where they were standing when the fight began.
+func (gl *GameLoop) abortFight(fight *Fight) {
+	for _, pid := range fight.PlayerIDs {
+		if p, ok := gl.players[pid]; ok {
+			p.FightID = 0
+			p.Dead = false
+			p.Defending = false
+			p.CombatAction = 0
+			p.CombatTarget = 0
+			p.CombatTransition = 0
+			if p.HP <= 0 {
+				p.HP = 1
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"happy-place-2/internal/maps"
+)
+
+// Some tiles can be used by facing them and pressing Enter. Doors toggle
+// between open and closed, levers toggle themselves and every tile they
+// are wired to, and rocks break if the player carries the right item.
+// Each change makes a new version of the map (see World.SetTiles), so
+// walkability follows at once and everyone on the map sees it in their
+// next snapshot. Changes last until the server restarts, and through a
+// map reload unless that map's tiles or legend were edited (see
+// carryTiles).
+
+// tilePrompt returns the popup text for a usable tile the player faces,
+// or "" if there's nothing to do with it.
+func (gl *GameLoop) tilePrompt(p *Player, x, y int) string {
+	m := gl.world.GetMap(p.MapName)
+	if m == nil {
+		return ""
+	}
+	tile := m.TileAt(x, y)
+	switch {
+	case m.SwitchAt(x, y) != nil:
+		return tileLabel(tile) + " — Enter to pull"
+	case tile.ToggleTo != nil && !tile.LeverOnly:
+		if tile.Walkable {
+			return tileLabel(tile) + " — Enter to close"
+		}
+		return tileLabel(tile) + " — Enter to open"
+	case tile.BreakWith != "":
+		item := gl.world.Item(tile.BreakWith)
+		if p.ItemCount(tile.BreakWith) == 0 {
+			return fmt.Sprintf("%s — needs a %s to break", tileLabel(tile), item.Name)
+		}
+		return fmt.Sprintf("%s — Enter to break with your %s", tileLabel(tile), item.Name)
+	}
+	return ""
+}
+
+// useTile handles Enter on the tile the player faces, if it can be used.
+func (gl *GameLoop) useTile(p *Player, x, y int) {
+	m := gl.world.GetMap(p.MapName)
+	if m == nil {
+		return
+	}
+	if sw := m.SwitchAt(x, y); sw != nil {
+		changes, ok := gl.toggles(m, append([]maps.Point{{X: x, Y: y}}, sw.Targets...))
+		if !ok {
+			gl.systemMessage(p, "The lever won't budge — something is in the way.")
+			return
+		}
+		gl.setTiles(p.MapName, changes)
+		gl.systemMessage(p, "You pull the lever. Something shifts nearby.")
+		return
+	}
+
+	tile := m.TileAt(x, y)
+	switch {
+	case tile.ToggleTo != nil && !tile.LeverOnly:
+		changes, ok := gl.toggles(m, []maps.Point{{X: x, Y: y}})
+		if !ok {
+			gl.systemMessage(p, "Something is in the way.")
+			return
+		}
+		gl.setTiles(p.MapName, changes)
+	case tile.BreakWith != "":
+		item := gl.world.Item(tile.BreakWith)
+		if p.ItemCount(tile.BreakWith) == 0 {
+			gl.systemMessage(p, fmt.Sprintf("You need a %s to break this.", item.Name))
+			return
+		}
+		gl.setTiles(p.MapName, []maps.TileChange{{X: x, Y: y, Tile: tile.BreakTo}})
+		gl.systemMessage(p, fmt.Sprintf("You break the %s with your %s.", strings.ToLower(tileLabel(tile)), item.Name))
+	}
+}
+
+// toggles works out the changes that toggle each tile at pts, skipping
+// tiles that don't toggle. It reports false, changing nothing, if a tile
+// would close on a player or NPC standing in it.
+func (gl *GameLoop) toggles(m *maps.Map, pts []maps.Point) ([]maps.TileChange, bool) {
+	var changes []maps.TileChange
+	for _, pt := range pts {
+		to := m.TileAt(pt.X, pt.Y).ToggleTo
+		if to == nil {
+			continue
+		}
+		if !m.Legend[*to].Walkable && (gl.playerAt(m.Name, pt.X, pt.Y) || gl.npcAt(m.Name, pt.X, pt.Y) != nil) {
+			return nil, false
+		}
+		changes = append(changes, maps.TileChange{X: pt.X, Y: pt.Y, Tile: *to})
+	}
+	return changes, true
+}
+
+// setTiles applies tile changes to a map. The changes come from the map's
+// own legend, so a failure means a bug rather than bad input.
+func (gl *GameLoop) setTiles(mapName string, changes []maps.TileChange) {
+	if err := gl.world.SetTiles(mapName, changes...); err != nil {
+		log.Printf("Tile change: %v", err)
+	}
+}
+
+// tileLabel names a usable tile for prompts: the first word of its legend
+// name, capitalised, so "door_open" reads "Door".
+func tileLabel(tile maps.TileDef) string {
+	word, _, _ := strings.Cut(tile.Name, "_")
+	if word == "" {
+		return "It"
+	}
+	return strings.ToUpper(word[:1]) + word[1:]
+}
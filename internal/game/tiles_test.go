@@ -0,0 +1,170 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+// doorLegend is a door that starts closed (3) and opens to 4.
+var doorLegend = map[string]map[string]any{
+	"3": {"char": "+", "fg": "yellow", "walkable": false, "name": "door", "toggle_to": "4"},
+	"4": {"char": "/", "fg": "yellow", "walkable": true, "name": "door_open", "toggle_to": "3"},
+}
+
+// hall is a corridor with a closed door in the middle.
+var hall = mapSpec{
+	Name: "Hall",
+	Rows: []string{
+		"#######",
+		"#..3..#",
+		"#######",
+	},
+	SpawnX: 2, SpawnY: 1,
+	Legend: doorLegend,
+}
+
+// tileName returns the name of the tile at (x, y) in the player's latest
+// snapshot of their map.
+func (h *harness) tileName(id string, x, y int) string {
+	h.t.Helper()
+	return h.state(id).Map.Map.TileAt(x, y).Name
+}
+
+// lastChat returns the newest chat line the player has.
+func (h *harness) lastChat(id string) string {
+	h.t.Helper()
+	chat := h.state(id).Chat
+	if len(chat) == 0 {
+		h.t.Fatalf("%s has no chat", id)
+	}
+	return chat[len(chat)-1].Text
+}
+
+func TestDoorOpensAndCloses(t *testing.T) {
+	h := newHarness(t, 1, hall)
+	alice := h.join("alice")
+	bob := h.join("bob")
+
+	// The closed door blocks, and facing it offers to open it
+	h.press(alice, ActionRight)
+	h.walk(alice, ActionRight)
+	h.at(alice, "Hall", 2, 1)
+	if ai := h.self(alice).ActiveInteraction; ai == nil || !strings.Contains(ai.Text, "Enter to open") {
+		t.Fatalf("prompt %+v facing a closed door", ai)
+	}
+
+	h.press(alice, ActionConfirm)
+	if name := h.tileName(alice, 3, 1); name != "door_open" {
+		t.Fatalf("tile %q after opening the door", name)
+	}
+	// Everyone on the map sees it
+	if name := h.tileName(bob, 3, 1); name != "door_open" {
+		t.Fatalf("bob sees %q after alice opened the door", name)
+	}
+	h.walk(alice, ActionRight)
+	h.at(alice, "Hall", 3, 1)
+
+	// Nobody can close the door on her
+	h.press(bob, ActionRight)
+	h.press(bob, ActionConfirm)
+	if name := h.tileName(bob, 3, 1); name != "door_open" {
+		t.Fatalf("door %q with alice standing in it", name)
+	}
+	if msg := h.lastChat(bob); !strings.Contains(msg, "in the way") {
+		t.Fatalf("bob was told %q", msg)
+	}
+
+	h.walk(alice, ActionRight)
+	h.press(bob, ActionConfirm)
+	if name := h.tileName(bob, 3, 1); name != "door" {
+		t.Fatalf("tile %q after closing the door", name)
+	}
+}
+
+func TestLeverTogglesItsTargets(t *testing.T) {
+	vault := mapSpec{
+		Name: "Vault",
+		Rows: []string{
+			"######",
+			"#5..3#",
+			"######",
+		},
+		SpawnX: 2, SpawnY: 1,
+		Legend: map[string]map[string]any{
+			"3": {"char": "+", "fg": "yellow", "walkable": false, "name": "door", "toggle_to": "4", "lever_only": true},
+			"4": {"char": "/", "fg": "yellow", "walkable": true, "name": "door_open", "toggle_to": "3", "lever_only": true},
+			"5": {"char": "\\", "fg": "gray", "walkable": false, "name": "lever", "toggle_to": "6"},
+			"6": {"char": "/", "fg": "gray", "walkable": false, "name": "lever_pulled", "toggle_to": "5"},
+		},
+		Switches: []map[string]any{{"x": 1, "y": 1, "targets": []map[string]int{{"x": 4, "y": 1}}}},
+	}
+	h := newHarness(t, 1, vault)
+	id := h.join("alice")
+
+	// The gate only answers to the lever
+	h.press(id, ActionRight)
+	h.walk(id, ActionRight)
+	h.at(id, "Vault", 3, 1)
+	if ai := h.self(id).ActiveInteraction; ai != nil {
+		t.Fatalf("prompt %+v facing a lever-only gate", ai)
+	}
+	h.press(id, ActionConfirm)
+	if name := h.tileName(id, 4, 1); name != "door" {
+		t.Fatalf("gate %q after pressing Enter at it", name)
+	}
+
+	h.press(id, ActionLeft)
+	h.walk(id, ActionLeft)
+	h.at(id, "Vault", 2, 1)
+	if ai := h.self(id).ActiveInteraction; ai == nil || !strings.Contains(ai.Text, "Enter to pull") {
+		t.Fatalf("prompt %+v facing the lever", ai)
+	}
+	h.press(id, ActionConfirm)
+	if lever, gate := h.tileName(id, 1, 1), h.tileName(id, 4, 1); lever != "lever_pulled" || gate != "door_open" {
+		t.Fatalf("lever %q gate %q after pulling the lever", lever, gate)
+	}
+	h.press(id, ActionConfirm)
+	if lever, gate := h.tileName(id, 1, 1), h.tileName(id, 4, 1); lever != "lever" || gate != "door" {
+		t.Fatalf("lever %q gate %q after pulling the lever back", lever, gate)
+	}
+}
+
+func TestRockBreaksWithTheRightItem(t *testing.T) {
+	quarry := mapSpec{
+		Name: "Quarry",
+		Rows: []string{
+			"#####",
+			"#.3.#",
+			"#####",
+		},
+		SpawnX: 1, SpawnY: 1,
+		Legend: map[string]map[string]any{
+			"3": {"char": "o", "fg": "gray", "walkable": false, "name": "rock", "break_with": "pickaxe", "break_to": "0"},
+		},
+	}
+	h := newHarness(t, 1, quarry)
+	h.loop.world.Items = map[string]ItemDef{"pickaxe": {ID: "pickaxe", Name: "Pickaxe", Kind: ItemMaterial, MaxStack: 1}}
+	id := h.join("alice")
+
+	h.press(id, ActionRight)
+	if ai := h.self(id).ActiveInteraction; ai == nil || !strings.Contains(ai.Text, "needs a Pickaxe") {
+		t.Fatalf("prompt %+v facing a rock without a pickaxe", ai)
+	}
+	h.press(id, ActionConfirm)
+	if name := h.tileName(id, 2, 1); name != "rock" {
+		t.Fatalf("tile %q after hitting the rock bare-handed", name)
+	}
+
+	if _, err := h.loop.GiveItem("alice", "pickaxe", 1); err != nil {
+		t.Fatal(err)
+	}
+	h.press(id, ActionConfirm)
+	if name := h.tileName(id, 2, 1); name != "grass" {
+		t.Fatalf("tile %q after breaking the rock", name)
+	}
+	if msg := h.lastChat(id); !strings.Contains(msg, "break the rock") {
+		t.Fatalf("told %q after breaking the rock", msg)
+	}
+	h.walk(id, ActionRight)
+	h.at(id, "Quarry", 2, 1)
+}
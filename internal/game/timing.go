@@ -1,32 +1,146 @@
 package game
 
-const TickRate = 20 // ticks per second
+import "time"
 
-// SecsToTicks converts a duration in seconds to game ticks.
-func SecsToTicks(s float64) int {
-	t := int(s * TickRate)
-	if t < 1 {
-		t = 1
+// DefaultTickRate is the number of game ticks per second the game was
+// designed around.
+const DefaultTickRate = 20
+
+// Tuning holds the operator-adjustable pacing of the game. Times are in
+// seconds.
+type Tuning struct {
+	TickRate int // ticks per second
+
+	CombatTurnTimeout    float64
+	CombatEnemyActDelay  float64
+	CombatTransition     float64
+	CombatCoopTransition float64
+	CombatResultDelay    float64
+	CombatReconnectGrace float64
+}
+
+// DefaultTuning returns the pacing the game was designed around.
+func DefaultTuning() Tuning {
+	return Tuning{
+		TickRate:             DefaultTickRate,
+		CombatTurnTimeout:    15.0,
+		CombatEnemyActDelay:  1.0,
+		CombatTransition:     1.0,
+		CombatCoopTransition: 0.5,
+		CombatResultDelay:    3.0,
+		CombatReconnectGrace: 60.0,
 	}
-	return t
 }
 
-// Timing constants — all expressed in seconds, converted to ticks at init.
-var (
-	MoveRepeatDelay   = SecsToTicks(0.15) // min ticks between moves when holding a key
-	WalkAnimDuration  = SecsToTicks(0.4)  // how long walk animation plays after a move
-	WalkFrameInterval = SecsToTicks(0.2)  // ticks between walk animation frames
-	IdleFrameInterval = SecsToTicks(1.0)  // ticks between idle animation frames
-	WaterAnimInterval = SecsToTicks(0.4)  // ticks between water animation frames
-	GrassAnimInterval = SecsToTicks(2.0)  // ticks between grass wind sway frames
+// Timing is the game's timing model: every duration in ticks at one tick
+// rate. A GameLoop owns one, and nothing changes it once the loop exists.
+type Timing struct {
+	TickRate int // ticks per game second
+
+	// Interval is the wall-clock time between ticks in Run. It is
+	// 1s/TickRate by default; shorter runs the game faster than real time,
+	// and zero stops Run from ticking on its own.
+	Interval time.Duration
+
+	MoveRepeatDelay   int // min ticks between moves when holding a key
+	WalkAnimDuration  int // how long walk animation plays after a move
+	WalkFrameInterval int // ticks between walk animation frames
+	IdleFrameInterval int // ticks between idle animation frames
+	LevelUpFlash      int // how long the overworld HUD celebrates a level-up
+	PortalFadeLen     int // a fade portal darkens the screen, moves the player, then brightens it
+
+	// Tile animation
+	GrassAnimInterval      int // ticks between grass sway frames
+	TallGrassAnimInterval  int // ticks between tall grass sway frames
+	SandAnimInterval       int // ticks between drifting sand frames
+	WaterAnimInterval      int // ticks between water wave frames
+	WaterShimmerInterval   int // ticks between water highlight shifts
+	ShallowAnimInterval    int // ticks between shallow water wave frames
+	ShallowShimmerInterval int // ticks between shallow water highlight shifts
 
 	// Combat timing
-	CombatTurnTimeout   = SecsToTicks(15.0) // auto-defend after this many ticks
-	CombatEnemyActDelay = SecsToTicks(1.0)  // pause between enemy actions
-	CombatTransitionLen = SecsToTicks(1.0)  // screen flash duration for trigger player
-	CombatCoopTransLen  = SecsToTicks(0.5)  // shorter transition for pulled-in players
-	CombatResultDelay   = SecsToTicks(3.0)  // victory/defeat screen duration
-)
-
-// EncounterChance is the percent chance per tall_grass step.
-const EncounterChance = 15
+	CombatTurnTimeout    int // auto-defend after this many ticks
+	CombatEnemyActDelay  int // pause between enemy actions
+	CombatTransitionLen  int // screen flash duration for trigger player
+	CombatCoopTransLen   int // shorter transition for pulled-in players
+	CombatResultDelay    int // victory/defeat screen duration
+	CombatReconnectGrace int // a dropped player's place in a fight is held this long
+
+	// Chat
+	SpeechDuration     int // how long a speech bubble stays up
+	ChatRefillInterval int // ticks to earn back one message of burst
+	ChatFadeTime       int // messages stay in the panel this long
+
+	// Regeneration
+	RegenInterval int // ticks between out-of-combat regen pulses
+
+	// NPCs
+	NPCMoveInterval int // ticks between NPC steps along a path
+	NPCPauseTime    int // NPCs linger this long at each waypoint
+
+	// Persistence
+	AutosaveInterval int // ticks between autosaves of online players
+}
+
+// NewTiming converts a tuning, given in seconds, to ticks at its tick rate.
+// A tick rate below 1 is treated as DefaultTickRate.
+func NewTiming(tn Tuning) *Timing {
+	if tn.TickRate < 1 {
+		tn.TickRate = DefaultTickRate
+	}
+	t := &Timing{TickRate: tn.TickRate, Interval: time.Second / time.Duration(tn.TickRate)}
+
+	t.MoveRepeatDelay = t.Ticks(0.15)
+	t.WalkAnimDuration = t.Ticks(0.4)
+	t.WalkFrameInterval = t.Ticks(0.2)
+	t.IdleFrameInterval = t.Ticks(1.0)
+	t.LevelUpFlash = t.Ticks(5.0)
+	t.PortalFadeLen = t.Ticks(0.6)
+
+	t.GrassAnimInterval = t.Ticks(0.4)
+	t.TallGrassAnimInterval = t.Ticks(0.35)
+	t.SandAnimInterval = t.Ticks(0.5)
+	t.WaterAnimInterval = t.Ticks(0.4)
+	t.WaterShimmerInterval = t.Ticks(0.3)
+	t.ShallowAnimInterval = t.Ticks(0.5)
+	t.ShallowShimmerInterval = t.Ticks(0.4)
+
+	t.CombatTurnTimeout = t.Ticks(tn.CombatTurnTimeout)
+	t.CombatEnemyActDelay = t.Ticks(tn.CombatEnemyActDelay)
+	t.CombatTransitionLen = t.Ticks(tn.CombatTransition)
+	t.CombatCoopTransLen = t.Ticks(tn.CombatCoopTransition)
+	t.CombatResultDelay = t.Ticks(tn.CombatResultDelay)
+	t.CombatReconnectGrace = t.Ticks(tn.CombatReconnectGrace)
+
+	t.SpeechDuration = t.Ticks(4.0)
+	t.ChatRefillInterval = t.Ticks(2.0)
+	t.ChatFadeTime = t.Ticks(30.0)
+
+	t.RegenInterval = t.Ticks(2.0)
+
+	t.NPCMoveInterval = t.Ticks(0.5)
+	t.NPCPauseTime = t.Ticks(2.0)
+
+	t.AutosaveInterval = t.Ticks(60.0)
+	return t
+}
+
+// DefaultTiming returns the timing model for DefaultTuning.
+func DefaultTiming() *Timing {
+	return NewTiming(DefaultTuning())
+}
+
+// Ticks converts a duration in seconds to ticks, never less than one.
+func (t *Timing) Ticks(secs float64) int {
+	n := int(secs * float64(t.TickRate))
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// Secs converts ticks to whole seconds, rounding up, for countdowns shown
+// to players.
+func (t *Timing) Secs(ticks int) int {
+	return (ticks + t.TickRate - 1) / t.TickRate
+}
@@ -1,16 +1,59 @@
 package game
 
-import "happy-place-2/internal/maps"
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+
+	"happy-place-2/internal/maps"
+)
 
 // World wraps multiple Maps and provides game-level helpers.
 type World struct {
 	Maps       map[string]*maps.Map
 	DefaultMap string
+	Enemies    map[string]EnemyDef // enemy catalog by ID
+	Items      map[string]ItemDef  // item catalog by ID
+	Quests     map[string]QuestDef // quest catalog by ID
+	Progress   *Progression        // EXP curve and level-up growth
+
+	// DefaultEncounters applies to maps without their own encounter tables
+	DefaultEncounters maps.EncounterTable
+
+	enemyIDs []string // sorted catalog keys for stable random picks
+}
+
+// NewWorld creates a world from the given map registry and catalogs.
+// A nil or empty enemy catalog falls back to DefaultEnemies, and a nil
+// progression to DefaultProgression.
+func NewWorld(allMaps map[string]*maps.Map, enemies map[string]EnemyDef, items map[string]ItemDef, quests map[string]QuestDef, prog *Progression, defaultMap string) *World {
+	if len(enemies) == 0 {
+		enemies = DefaultEnemies()
+	}
+	ids := make([]string, 0, len(enemies))
+	for id := range enemies {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	if items == nil {
+		items = make(map[string]ItemDef)
+	}
+	if quests == nil {
+		quests = make(map[string]QuestDef)
+	}
+	if prog == nil {
+		prog = DefaultProgression()
+	}
+	encounters := DefaultEncounters
+	encounters.Tiles = slices.Clone(encounters.Tiles)
+	encounters.Enemies = slices.Clone(encounters.Enemies)
+	return &World{Maps: allMaps, DefaultMap: defaultMap, Enemies: enemies, Items: items, Quests: quests, Progress: prog, DefaultEncounters: encounters, enemyIDs: ids}
 }
 
-// NewWorld creates a world from the given map registry.
-func NewWorld(allMaps map[string]*maps.Map, defaultMap string) *World {
-	return &World{Maps: allMaps, DefaultMap: defaultMap}
+// RandomEnemy picks an enemy definition uniformly from the catalog.
+func (w *World) RandomEnemy(rng *rand.Rand) EnemyDef {
+	return w.Enemies[w.enemyIDs[rng.Intn(len(w.enemyIDs))]]
 }
 
 // SpawnPoint returns the default map's name and spawn coordinates.
@@ -46,7 +89,43 @@ func (w *World) InteractionAt(mapName string, x, y int) *maps.Interaction {
 	return m.InteractionAt(x, y)
 }
 
+// Item returns the item definition for an ID. Unknown IDs (e.g. items
+// removed from the catalog after being saved) get a placeholder material.
+func (w *World) Item(id string) ItemDef {
+	if def, ok := w.Items[id]; ok {
+		return def
+	}
+	return ItemDef{ID: id, Name: id, Kind: ItemMaterial, MaxStack: defaultMaxStack}
+}
+
+// NPCName returns the display name of the NPC with the given ID on any map,
+// or the ID itself if there is none.
+func (w *World) NPCName(id string) string {
+	for _, m := range w.Maps {
+		if n := m.NPCByID(id); n != nil {
+			return n.Name
+		}
+	}
+	return id
+}
+
 // GetMap returns the map with the given name, or nil.
 func (w *World) GetMap(name string) *maps.Map {
 	return w.Maps[name]
 }
+
+// SetTiles swaps the named map for a new version with the given tiles
+// changed. Snapshots already sent keep the old version; the next ones
+// carry the new one, and TilesChangedSince tells a renderer what moved.
+func (w *World) SetTiles(mapName string, changes ...maps.TileChange) error {
+	m := w.Maps[mapName]
+	if m == nil {
+		return fmt.Errorf("no map named %q", mapName)
+	}
+	next, err := m.WithTiles(changes)
+	if err != nil {
+		return fmt.Errorf("map %q: %w", mapName, err)
+	}
+	w.Maps[mapName] = next
+	return nil
+}
@@ -0,0 +1,186 @@
+package maps
+
+import "fmt"
+
+// Rect is a rectangular region of a map in tile coordinates.
+type Rect struct {
+	X, Y, W, H int
+}
+
+// Contains reports whether (x, y) lies inside the rectangle.
+func (r Rect) Contains(x, y int) bool {
+	return x >= r.X && x < r.X+r.W && y >= r.Y && y < r.Y+r.H
+}
+
+// EncounterEnemy is one weighted entry in an encounter table.
+type EncounterEnemy struct {
+	ID     string // enemy catalog ID
+	Weight int
+}
+
+// GroupSize controls how many enemies an encounter spawns.
+// A solo level-1 player meets Min enemies; each extra party member adds
+// PerPlayer, and every LevelStep levels of average party level above 1
+// adds one more. The total is capped at Max.
+type GroupSize struct {
+	Min       int
+	Max       int
+	PerPlayer int
+	LevelStep int // 0 = group size ignores level
+}
+
+// DefaultGroupSize spawns one enemy per player, matching the original rule.
+var DefaultGroupSize = GroupSize{Min: 1, Max: 6, PerPlayer: 1}
+
+// Size returns the number of enemies for a party of the given size and
+// average level.
+func (g GroupSize) Size(partySize, avgLevel int) int {
+	n := g.Min
+	if partySize > 1 {
+		n += (partySize - 1) * g.PerPlayer
+	}
+	if g.LevelStep > 0 && avgLevel > 1 {
+		n += (avgLevel - 1) / g.LevelStep
+	}
+	if n > g.Max {
+		n = g.Max
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// EncounterTable declares random encounters for a whole map or a region.
+type EncounterTable struct {
+	Region  *Rect    // nil = whole map
+	Chance  int      // percent chance per step on a trigger tile
+	Tiles   []string // tile names that can trigger the encounter
+	Enemies []EncounterEnemy
+	Group   GroupSize
+}
+
+// Covers reports whether a step onto (x, y) with the given tile name can
+// trigger this table.
+func (t *EncounterTable) Covers(x, y int, tileName string) bool {
+	if t.Region != nil && !t.Region.Contains(x, y) {
+		return false
+	}
+	for _, name := range t.Tiles {
+		if name == tileName {
+			return true
+		}
+	}
+	return false
+}
+
+// EncounterAt returns the encounter table that applies to (x, y), or nil.
+// Region tables take precedence over map-wide ones; within each group the
+// first listed table wins.
+func (m *Map) EncounterAt(x, y int) *EncounterTable {
+	name := m.TileAt(x, y).Name
+	for i := range m.Encounters {
+		if t := &m.Encounters[i]; t.Region != nil && t.Covers(x, y, name) {
+			return t
+		}
+	}
+	for i := range m.Encounters {
+		if t := &m.Encounters[i]; t.Region == nil && t.Covers(x, y, name) {
+			return t
+		}
+	}
+	return nil
+}
+
+type jsonEncounter struct {
+	Region  *jsonRect            `json:"region,omitempty"`
+	Chance  int                  `json:"chance"`
+	Tiles   []string             `json:"tiles,omitempty"`
+	Enemies []jsonEncounterEnemy `json:"enemies"`
+	Group   *jsonGroup           `json:"group,omitempty"`
+}
+
+type jsonRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type jsonEncounterEnemy struct {
+	ID     string `json:"id"`
+	Weight int    `json:"weight,omitempty"`
+}
+
+type jsonGroup struct {
+	Min       *int `json:"min,omitempty"`
+	Max       *int `json:"max,omitempty"`
+	PerPlayer *int `json:"per_player,omitempty"`
+	LevelStep int  `json:"level_step,omitempty"`
+}
+
+// buildEncounters converts and validates the JSON encounter tables for a map.
+// Omitted fields default to tall_grass tiles, weight 1 and DefaultGroupSize.
+func buildEncounters(jes []jsonEncounter, width, height int, legend []TileDef) ([]EncounterTable, error) {
+	tileNames := make(map[string]bool, len(legend))
+	for _, td := range legend {
+		tileNames[td.Name] = true
+	}
+
+	tables := make([]EncounterTable, len(jes))
+	for i, je := range jes {
+		t := EncounterTable{
+			Chance: je.Chance,
+			Tiles:  je.Tiles,
+			Group:  DefaultGroupSize,
+		}
+		if je.Chance < 0 || je.Chance > 100 {
+			return nil, fmt.Errorf("encounter %d: chance %d outside 0..100", i, je.Chance)
+		}
+		if je.Region != nil {
+			r := Rect{X: je.Region.X, Y: je.Region.Y, W: je.Region.W, H: je.Region.H}
+			if r.W <= 0 || r.H <= 0 || r.X < 0 || r.Y < 0 || r.X+r.W > width || r.Y+r.H > height {
+				return nil, fmt.Errorf("encounter %d: region %+v outside %dx%d map", i, r, width, height)
+			}
+			t.Region = &r
+		}
+		if len(t.Tiles) == 0 {
+			t.Tiles = []string{"tall_grass"}
+		}
+		for _, name := range t.Tiles {
+			if !tileNames[name] {
+				return nil, fmt.Errorf("encounter %d: tile %q not in legend", i, name)
+			}
+		}
+		if len(je.Enemies) == 0 {
+			return nil, fmt.Errorf("encounter %d: no enemies listed", i)
+		}
+		for _, ee := range je.Enemies {
+			w := ee.Weight
+			if w == 0 {
+				w = 1
+			}
+			if ee.ID == "" || w < 0 {
+				return nil, fmt.Errorf("encounter %d: enemy entry needs an id and a positive weight", i)
+			}
+			t.Enemies = append(t.Enemies, EncounterEnemy{ID: ee.ID, Weight: w})
+		}
+		if g := je.Group; g != nil {
+			if g.Min != nil {
+				t.Group.Min = *g.Min
+			}
+			if g.Max != nil {
+				t.Group.Max = *g.Max
+			}
+			if g.PerPlayer != nil {
+				t.Group.PerPlayer = *g.PerPlayer
+			}
+			t.Group.LevelStep = g.LevelStep
+		}
+		if t.Group.Min < 1 || t.Group.Max < t.Group.Min || t.Group.PerPlayer < 0 || t.Group.LevelStep < 0 {
+			return nil, fmt.Errorf("encounter %d: invalid group size %+v", i, t.Group)
+		}
+		tables[i] = t
+	}
+	return tables, nil
+}
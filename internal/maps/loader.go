@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -38,25 +40,94 @@ func resolveColor(name string) int {
 
 // TileDef defines the visual and gameplay properties of a tile type.
 type TileDef struct {
-	Char     rune
-	Fg       int
-	Bg       int
-	Walkable bool
-	Name     string
+	Char      rune
+	Fg        int
+	Bg        int
+	Walkable  bool
+	Name      string
+	Regen     *Regen // resources restored per regen pulse; nil = game default
+	ToggleTo  *int   // legend index this tile becomes when used, e.g. a door opening; nil = fixed
+	LeverOnly bool   // only a Switch can toggle it, not a player pressing Enter
+	BreakWith string // item ID that breaks this tile; "" = unbreakable
+	BreakTo   int    // legend index left behind when broken
 }
 
-// Portal defines a teleport point linking two maps.
+// Regen is how much HP, stamina and MP a player standing on a tile gets
+// back each regeneration pulse.
+type Regen struct {
+	HP      int `json:"hp,omitempty"`
+	Stamina int `json:"stamina,omitempty"`
+	MP      int `json:"mp,omitempty"`
+}
+
+// Portal defines a teleport point linking two maps. A portal with
+// requirements is locked to players who don't meet them all.
 type Portal struct {
-	X, Y              int
-	TargetMap         string
-	TargetX, TargetY  int
+	X, Y             int
+	TargetMap        string
+	TargetX, TargetY int
+	Conditions       []Condition // flag and quest tests that must all hold
+	Item             string      // item ID the player must carry; "" = none
+	Level            int         // minimum player level; 0 = none
+	LockedText       string      // popup while locked; "" = say what's missing
+	Transition       string      // TransitionFade, or "" = instant
+	Facing           string      // direction the player faces on arrival; "" = keep
+	TwoWay           bool        // the target tile holds a portal straight back
 }
 
+// Portal transitions.
+const (
+	TransitionFade = "fade" // the screen fades out, then back in on arrival
+)
+
+// Directions a portal can turn the player to face on arrival.
+const (
+	FaceUp    = "up"
+	FaceDown  = "down"
+	FaceLeft  = "left"
+	FaceRight = "right"
+)
+
+// oppositeFacing returns the direction facing back the other way.
+func oppositeFacing(f string) string {
+	switch f {
+	case FaceUp:
+		return FaceDown
+	case FaceDown:
+		return FaceUp
+	case FaceLeft:
+		return FaceRight
+	case FaceRight:
+		return FaceLeft
+	}
+	return ""
+}
+
+// Locked reports whether the portal has any requirements.
+func (p *Portal) Locked() bool {
+	return len(p.Conditions) > 0 || p.Item != "" || p.Level > 0
+}
+
+// Switch is a lever. Using it toggles its own tile and every target tile
+// (see TileDef.ToggleTo).
+type Switch struct {
+	X, Y    int
+	Targets []Point
+}
+
+// Interaction types.
+const (
+	InteractionSign  = "sign"  // shows its text while faced
+	InteractionInn   = "inn"   // Enter fully restores the player
+	InteractionQuest = "quest" // Enter gives the player the quest named by Quest
+)
+
 // Interaction defines a world object the player can interact with by facing it.
 type Interaction struct {
-	X, Y int
-	Type string
-	Text string
+	X, Y  int
+	Type  string
+	Text  string
+	Quest string // quest ID for InteractionQuest
 }
 
 // Spawn defines the spawn point coordinates.
@@ -65,9 +136,12 @@ type Spawn struct {
 	Y int `json:"y"`
 }
 
-// Map represents a loaded tile map.
+// Map represents a loaded tile map. A Map is never modified once loaded,
+// so the game loop and every session can read the same one without
+// locking. Tile changes go through WithTiles, which returns a new version.
 type Map struct {
 	Name           string
+	Version        uint64 // unique to this snapshot of the map
 	Width          int
 	Height         int
 	SpawnX         int
@@ -78,6 +152,12 @@ type Map struct {
 	portalIdx      map[[2]int]*Portal      // built at load time for O(1) lookup
 	Interactions   []Interaction
 	interactionIdx map[[2]int]*Interaction // built at load time for O(1) lookup
+	Switches       []Switch
+	switchIdx      map[[2]int]*Switch // built at load time for O(1) lookup
+	Encounters     []EncounterTable        // empty = game default (tall_grass)
+	NPCs           []NPC
+	edits          []tileEdit // recent WithTiles steps leading to this version
+	loaded         [][]int    // Tiles as the map was loaded, before any WithTiles
 }
 
 // jsonMap is the on-disk JSON format.
@@ -90,13 +170,23 @@ type jsonMap struct {
 	Legend       map[string]jsonTile `json:"legend"`
 	Portals      []jsonPortal        `json:"portals,omitempty"`
 	Interactions []jsonInteraction   `json:"interactions,omitempty"`
+	Switches     []jsonSwitch        `json:"switches,omitempty"`
+	Encounters   []jsonEncounter     `json:"encounters,omitempty"`
+	NPCs         []jsonNPC           `json:"npcs,omitempty"`
 }
 
 type jsonInteraction struct {
-	X    int    `json:"x"`
-	Y    int    `json:"y"`
-	Type string `json:"type"`
-	Text string `json:"text"`
+	X     int    `json:"x"`
+	Y     int    `json:"y"`
+	Type  string `json:"type"`
+	Text  string `json:"text"`
+	Quest string `json:"quest,omitempty"`
+}
+
+type jsonSwitch struct {
+	X       int     `json:"x"`
+	Y       int     `json:"y"`
+	Targets []Point `json:"targets"`
 }
 
 type jsonPortal struct {
@@ -105,6 +195,14 @@ type jsonPortal struct {
 	TargetMap string `json:"target_map"`
 	TargetX   int    `json:"target_x"`
 	TargetY   int    `json:"target_y"`
+
+	If         []Condition `json:"if,omitempty"`
+	Item       string      `json:"item,omitempty"`
+	Level      int         `json:"level,omitempty"`
+	LockedText string      `json:"locked_text,omitempty"`
+	Transition string      `json:"transition,omitempty"`
+	Facing     string      `json:"facing,omitempty"`
+	TwoWay     bool        `json:"two_way,omitempty"`
 }
 
 type jsonTile struct {
@@ -113,6 +211,12 @@ type jsonTile struct {
 	Bg       string `json:"bg,omitempty"`
 	Walkable bool   `json:"walkable"`
 	Name     string `json:"name"`
+	Regen    *Regen `json:"regen,omitempty"`
+
+	ToggleTo  string `json:"toggle_to,omitempty"` // legend key
+	LeverOnly bool   `json:"lever_only,omitempty"`
+	BreakWith string `json:"break_with,omitempty"` // item ID
+	BreakTo   string `json:"break_to,omitempty"`   // legend key
 }
 
 // LoadMap reads a JSON map file from disk.
@@ -146,11 +250,34 @@ func LoadMap(path string) (*Map, error) {
 			ch = rune(jt.Char[0])
 		}
 		legend[idx] = TileDef{
-			Char:     ch,
-			Fg:       resolveColor(jt.Fg),
-			Bg:       resolveColor(jt.Bg),
-			Walkable: jt.Walkable,
-			Name:     jt.Name,
+			Char:      ch,
+			Fg:        resolveColor(jt.Fg),
+			Bg:        resolveColor(jt.Bg),
+			Walkable:  jt.Walkable,
+			Name:      jt.Name,
+			Regen:     jt.Regen,
+			LeverOnly: jt.LeverOnly,
+		}
+		if r := jt.Regen; r != nil && (r.HP < 0 || r.Stamina < 0 || r.MP < 0) {
+			return nil, fmt.Errorf("legend %q (%s): regen must not be negative", k, jt.Name)
+		}
+		if jt.ToggleTo != "" {
+			to, err := legendIndex(jm.Legend, jt.ToggleTo)
+			if err != nil {
+				return nil, fmt.Errorf("legend %q (%s): toggle_to: %w", k, jt.Name, err)
+			}
+			legend[idx].ToggleTo = &to
+		} else if jt.LeverOnly {
+			return nil, fmt.Errorf("legend %q (%s): lever_only needs toggle_to", k, jt.Name)
+		}
+		if (jt.BreakWith == "") != (jt.BreakTo == "") {
+			return nil, fmt.Errorf("legend %q (%s): break_with and break_to go together", k, jt.Name)
+		}
+		if jt.BreakWith != "" {
+			if legend[idx].BreakTo, err = legendIndex(jm.Legend, jt.BreakTo); err != nil {
+				return nil, fmt.Errorf("legend %q (%s): break_to: %w", k, jt.Name, err)
+			}
+			legend[idx].BreakWith = jt.BreakWith
 		}
 	}
 
@@ -170,31 +297,66 @@ func LoadMap(path string) (*Map, error) {
 			X: jp.X, Y: jp.Y,
 			TargetMap: jp.TargetMap,
 			TargetX: jp.TargetX, TargetY: jp.TargetY,
+			Conditions: jp.If,
+			Item:       jp.Item,
+			Level:      jp.Level,
+			LockedText: jp.LockedText,
+			Transition: jp.Transition,
+			Facing:     jp.Facing,
+			TwoWay:     jp.TwoWay,
+		}
+		if err := portals[i].check(); err != nil {
+			return nil, fmt.Errorf("portal at (%d,%d): %w", jp.X, jp.Y, err)
 		}
 	}
 
 	interactions := make([]Interaction, len(jm.Interactions))
 	for i, ji := range jm.Interactions {
+		if ji.Type == InteractionQuest && ji.Quest == "" {
+			return nil, fmt.Errorf("interaction at (%d,%d): quest type needs a quest", ji.X, ji.Y)
+		}
 		interactions[i] = Interaction{
 			X: ji.X, Y: ji.Y,
-			Type: ji.Type,
-			Text: ji.Text,
+			Type:  ji.Type,
+			Text:  ji.Text,
+			Quest: ji.Quest,
 		}
 	}
 
+	switches := make([]Switch, len(jm.Switches))
+	for i, js := range jm.Switches {
+		if len(js.Targets) == 0 {
+			return nil, fmt.Errorf("switch at (%d,%d) has no targets", js.X, js.Y)
+		}
+		switches[i] = Switch{X: js.X, Y: js.Y, Targets: js.Targets}
+	}
+
+	encounters, err := buildEncounters(jm.Encounters, jm.Width, jm.Height, legend)
+	if err != nil {
+		return nil, err
+	}
+
 	m := &Map{
 		Name:         jm.Name,
+		Version:      nextVersion(),
 		Width:        jm.Width,
 		Height:       jm.Height,
 		SpawnX:       jm.Spawn.X,
 		SpawnY:       jm.Spawn.Y,
 		Tiles:        jm.Tiles,
+		loaded:       jm.Tiles,
 		Legend:       legend,
 		Portals:      portals,
 		Interactions: interactions,
+		Switches:     switches,
+		Encounters:   encounters,
 	}
 	m.buildPortalIndex()
 	m.buildInteractionIndex()
+	m.buildSwitchIndex()
+	if m.NPCs, err = buildNPCs(jm.NPCs, m); err != nil {
+		return nil, err
+	}
 	return m, nil
 }
 
@@ -229,6 +391,25 @@ func (m *Map) PortalAt(x, y int) *Portal {
 	return m.portalIdx[[2]int{x, y}]
 }
 
+// check rejects portal options that can't be used.
+func (p *Portal) check() error {
+	for _, c := range p.Conditions {
+		if err := c.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.Level < 0 {
+		return fmt.Errorf("level must not be negative")
+	}
+	if p.Transition != "" && p.Transition != TransitionFade {
+		return fmt.Errorf("unknown transition %q", p.Transition)
+	}
+	if p.Facing != "" && oppositeFacing(p.Facing) == "" {
+		return fmt.Errorf("unknown facing %q", p.Facing)
+	}
+	return nil
+}
+
 // buildInteractionIndex populates the O(1) interaction lookup map.
 func (m *Map) buildInteractionIndex() {
 	m.interactionIdx = make(map[[2]int]*Interaction, len(m.Interactions))
@@ -242,8 +423,34 @@ func (m *Map) InteractionAt(x, y int) *Interaction {
 	return m.interactionIdx[[2]int{x, y}]
 }
 
+// buildSwitchIndex populates the O(1) switch lookup map.
+func (m *Map) buildSwitchIndex() {
+	m.switchIdx = make(map[[2]int]*Switch, len(m.Switches))
+	for i := range m.Switches {
+		m.switchIdx[[2]int{m.Switches[i].X, m.Switches[i].Y}] = &m.Switches[i]
+	}
+}
+
+// SwitchAt returns the switch at the given coordinates, or nil if none.
+func (m *Map) SwitchAt(x, y int) *Switch {
+	return m.switchIdx[[2]int{x, y}]
+}
+
+// legendIndex resolves a legend key that one tile uses to name another.
+func legendIndex(legend map[string]jsonTile, key string) (int, error) {
+	if _, ok := legend[key]; !ok {
+		return 0, fmt.Errorf("no legend entry %q", key)
+	}
+	idx, err := strconv.Atoi(key)
+	if err != nil {
+		return 0, fmt.Errorf("legend key %q is not a number", key)
+	}
+	return idx, nil
+}
+
 // LoadMaps scans a directory for *.json files, loads each as a Map,
-// and returns them indexed by Name. Validates portal target_map references.
+// and returns them indexed by Name. Validates portal target_map references
+// and adds the return portal of every two-way portal that lacks one.
 func LoadMaps(dir string) (map[string]*Map, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
@@ -274,10 +481,45 @@ func LoadMaps(dir string) (map[string]*Map, error) {
 			}
 		}
 	}
+	addReturnPortals(allMaps)
 
 	return allMaps, nil
 }
 
+// addReturnPortals gives each two-way portal its way back: a portal on the
+// target tile leading to the portal's own tile. The return portal keeps the
+// transition, faces the other way and has no requirements. A target tile
+// that already has a portal is left alone; Validate reports it if it
+// doesn't lead back. Maps are visited in name order, so the same files
+// always load the same way.
+func addReturnPortals(allMaps map[string]*Map) {
+	names := make([]string, 0, len(allMaps))
+	for name := range allMaps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		m := allMaps[name]
+		for i := 0; i < len(m.Portals); i++ {
+			p := m.Portals[i]
+			tm := allMaps[p.TargetMap]
+			if !p.TwoWay || tm.PortalAt(p.TargetX, p.TargetY) != nil {
+				continue
+			}
+			tm.Portals = append(tm.Portals, Portal{
+				X: p.TargetX, Y: p.TargetY,
+				TargetMap: m.Name,
+				TargetX: p.X, TargetY: p.Y,
+				Transition: p.Transition,
+				Facing:     oppositeFacing(p.Facing),
+				TwoWay:     true,
+			})
+			tm.buildPortalIndex()
+		}
+	}
+}
+
 // DefaultMap returns a simple fallback map if no JSON file is available.
 func DefaultMap() *Map {
 	w, h := 60, 30
@@ -294,13 +536,15 @@ func DefaultMap() *Map {
 	}
 
 	return &Map{
-		Name:   "Default",
-		Width:  w,
-		Height: h,
-		SpawnX: w / 2,
-		SpawnY: h / 2,
-		Tiles:  tiles,
-		Legend: []TileDef{
+		Name:    "Default",
+		Version: nextVersion(),
+		Width:   w,
+		Height:  h,
+		SpawnX:  w / 2,
+		SpawnY:  h / 2,
+		Tiles:   tiles,
+		loaded:  tiles,
+		Legend:  []TileDef{
 			{Char: '.', Fg: 32, Walkable: true, Name: "grass"},
 			{Char: '#', Fg: 90, Walkable: false, Name: "wall"},
 		},
@@ -0,0 +1,277 @@
+package maps
+
+import "fmt"
+
+// MaxDialogueChoices is the most choices a dialogue node can offer. They are
+// picked with the number keys 1-5.
+const MaxDialogueChoices = 5
+
+// Point is a tile coordinate.
+type Point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// NPC is a non-player character placed on a map.
+type NPC struct {
+	ID       string
+	Name     string
+	X, Y     int // starting position
+	Color    [3]uint8
+	Path     []Point // waypoints walked in a loop; empty = stands still
+	Dialogue Dialogue
+}
+
+// Dialogue is a branching conversation tree.
+type Dialogue struct {
+	Start string
+	Nodes map[string]*DialogueNode
+}
+
+// DialogueNode is one line of dialogue. Enter moves to Next when the node
+// has no choices; an empty Next ends the conversation.
+type DialogueNode struct {
+	ID      string
+	Text    string
+	Next    string
+	Choices []DialogueChoice
+	Effects []Effect // applied when the node is shown
+}
+
+// DialogueChoice is one answer the player can pick.
+type DialogueChoice struct {
+	Text       string
+	Next       string      // "" = end the conversation
+	Conditions []Condition // all must hold for the choice to be offered
+	Effects    []Effect    // applied when the choice is picked
+}
+
+// Quest statuses a Condition can test for.
+const (
+	QuestActive = "active" // taken but not finished
+	QuestDone   = "done"   // finished and rewarded
+)
+
+// Condition is a test against the player's state: either a flag or a
+// quest's status.
+type Condition struct {
+	Flag   string `json:"flag,omitempty"`
+	Quest  string `json:"quest,omitempty"`
+	Status string `json:"status,omitempty"` // QuestActive, QuestDone, or "" = taken at all
+	Not    bool   `json:"not,omitempty"`    // true = the test must fail
+}
+
+// Effect changes the player's state.
+type Effect struct {
+	Set        string `json:"set,omitempty"`         // flag to set
+	Clear      string `json:"clear,omitempty"`       // flag to clear
+	StartQuest string `json:"start_quest,omitempty"` // quest to give the player
+}
+
+// Validate checks that the effect does exactly one thing.
+func (e Effect) Validate() error {
+	n := 0
+	for _, s := range []string{e.Set, e.Clear, e.StartQuest} {
+		if s != "" {
+			n++
+		}
+	}
+	if n != 1 {
+		return fmt.Errorf("effect needs exactly one of set, clear or start_quest")
+	}
+	return nil
+}
+
+// Validate checks that the condition tests exactly one thing.
+func (c Condition) Validate() error {
+	switch {
+	case (c.Flag == "") == (c.Quest == ""):
+		return fmt.Errorf("condition needs exactly one of flag or quest")
+	case c.Flag != "" && c.Status != "":
+		return fmt.Errorf("condition on flag %q: status only applies to quests", c.Flag)
+	case c.Status != "" && c.Status != QuestActive && c.Status != QuestDone:
+		return fmt.Errorf("condition on quest %q: unknown status %q", c.Quest, c.Status)
+	}
+	return nil
+}
+
+type jsonNPC struct {
+	ID       string       `json:"id"`
+	Name     string       `json:"name"`
+	X        int          `json:"x"`
+	Y        int          `json:"y"`
+	Color    string       `json:"color"`
+	Path     []Point      `json:"path,omitempty"`
+	Dialogue jsonDialogue `json:"dialogue"`
+}
+
+type jsonDialogue struct {
+	Start string                    `json:"start"`
+	Nodes map[string]jsonDialogNode `json:"nodes"`
+}
+
+type jsonDialogNode struct {
+	Text    string       `json:"text"`
+	Next    string       `json:"next,omitempty"`
+	Choices []jsonChoice `json:"choices,omitempty"`
+	Effects []Effect     `json:"effects,omitempty"`
+}
+
+type jsonChoice struct {
+	Text    string      `json:"text"`
+	Next    string      `json:"next,omitempty"`
+	If      []Condition `json:"if,omitempty"`
+	Effects []Effect    `json:"effects,omitempty"`
+}
+
+// defaultNPCColor is used when an NPC omits its sprite color.
+var defaultNPCColor = [3]uint8{150, 110, 170}
+
+// buildNPCs converts and validates the NPCs of a map.
+func buildNPCs(raw []jsonNPC, m *Map) ([]NPC, error) {
+	npcs := make([]NPC, 0, len(raw))
+	seen := make(map[string]bool)
+	for i, jn := range raw {
+		where := fmt.Sprintf("npc %d", i)
+		if jn.ID != "" {
+			where = fmt.Sprintf("npc %q", jn.ID)
+		}
+		switch {
+		case jn.ID == "":
+			return nil, fmt.Errorf("%s: missing id", where)
+		case seen[jn.ID]:
+			return nil, fmt.Errorf("%s: duplicate id", where)
+		case jn.Name == "":
+			return nil, fmt.Errorf("%s: missing name", where)
+		case !m.IsWalkable(jn.X, jn.Y):
+			return nil, fmt.Errorf("%s: position (%d,%d) is not walkable", where, jn.X, jn.Y)
+		}
+		seen[jn.ID] = true
+
+		color := defaultNPCColor
+		if jn.Color != "" {
+			c, err := ParseHexColor(jn.Color)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", where, err)
+			}
+			color = c
+		}
+		for _, pt := range jn.Path {
+			if !m.IsWalkable(pt.X, pt.Y) {
+				return nil, fmt.Errorf("%s: path point (%d,%d) is not walkable", where, pt.X, pt.Y)
+			}
+		}
+		dlg, err := buildDialogue(jn.Dialogue)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", where, err)
+		}
+		npcs = append(npcs, NPC{
+			ID:       jn.ID,
+			Name:     jn.Name,
+			X:        jn.X,
+			Y:        jn.Y,
+			Color:    color,
+			Path:     jn.Path,
+			Dialogue: dlg,
+		})
+	}
+	return npcs, nil
+}
+
+// buildDialogue converts a dialogue tree and checks that every link
+// resolves.
+func buildDialogue(jd jsonDialogue) (Dialogue, error) {
+	if jd.Start == "" {
+		return Dialogue{}, fmt.Errorf("dialogue: missing start")
+	}
+	d := Dialogue{Start: jd.Start, Nodes: make(map[string]*DialogueNode, len(jd.Nodes))}
+	for id, jn := range jd.Nodes {
+		node := &DialogueNode{ID: id, Text: jn.Text, Next: jn.Next, Effects: jn.Effects}
+		for _, jc := range jn.Choices {
+			node.Choices = append(node.Choices, DialogueChoice{
+				Text:       jc.Text,
+				Next:       jc.Next,
+				Conditions: jc.If,
+				Effects:    jc.Effects,
+			})
+		}
+		d.Nodes[id] = node
+	}
+
+	link := func(from, to string) error {
+		if to == "" {
+			return nil
+		}
+		if _, ok := d.Nodes[to]; !ok {
+			return fmt.Errorf("dialogue node %q: links to unknown node %q", from, to)
+		}
+		return nil
+	}
+	if err := link("start", d.Start); err != nil {
+		return Dialogue{}, err
+	}
+	for id, node := range d.Nodes {
+		switch {
+		case node.Text == "":
+			return Dialogue{}, fmt.Errorf("dialogue node %q: missing text", id)
+		case len(node.Choices) > 0 && node.Next != "":
+			return Dialogue{}, fmt.Errorf("dialogue node %q: has both next and choices", id)
+		case len(node.Choices) > MaxDialogueChoices:
+			return Dialogue{}, fmt.Errorf("dialogue node %q: %d choices, at most %d allowed", id, len(node.Choices), MaxDialogueChoices)
+		}
+		if err := link(id, node.Next); err != nil {
+			return Dialogue{}, err
+		}
+		if err := checkEffects(id, node.Effects); err != nil {
+			return Dialogue{}, err
+		}
+		for _, c := range node.Choices {
+			if c.Text == "" {
+				return Dialogue{}, fmt.Errorf("dialogue node %q: choice with no text", id)
+			}
+			if err := link(id, c.Next); err != nil {
+				return Dialogue{}, err
+			}
+			for _, cond := range c.Conditions {
+				if err := cond.Validate(); err != nil {
+					return Dialogue{}, fmt.Errorf("dialogue node %q: %w", id, err)
+				}
+			}
+			if err := checkEffects(id, c.Effects); err != nil {
+				return Dialogue{}, err
+			}
+		}
+	}
+	return d, nil
+}
+
+func checkEffects(node string, effects []Effect) error {
+	for _, e := range effects {
+		if err := e.Validate(); err != nil {
+			return fmt.Errorf("dialogue node %q: %w", node, err)
+		}
+	}
+	return nil
+}
+
+// NPCByID returns the NPC with the given ID, or nil.
+func (m *Map) NPCByID(id string) *NPC {
+	for i := range m.NPCs {
+		if m.NPCs[i].ID == id {
+			return &m.NPCs[i]
+		}
+	}
+	return nil
+}
+
+// ParseHexColor parses "#rrggbb" into RGB components.
+func ParseHexColor(s string) ([3]uint8, error) {
+	var c [3]uint8
+	if len(s) != 7 || s[0] != '#' {
+		return c, fmt.Errorf("color %q: want #rrggbb", s)
+	}
+	if _, err := fmt.Sscanf(s[1:], "%02x%02x%02x", &c[0], &c[1], &c[2]); err != nil {
+		return c, fmt.Errorf("color %q: %w", s, err)
+	}
+	return c, nil
+}
@@ -0,0 +1,92 @@
+package maps
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// loadLinked writes two open 5x3 maps, A and B, with the given raw JSON
+// portals and loads them together.
+func loadLinked(t *testing.T, portalsA, portalsB []map[string]any) (map[string]*Map, error) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, portals := range map[string][]map[string]any{"A": portalsA, "B": portalsB} {
+		data, err := json.Marshal(map[string]any{
+			"name":    name,
+			"width":   5,
+			"height":  3,
+			"spawn":   map[string]int{"x": 2, "y": 1},
+			"tiles":   [][]int{{0, 0, 0, 0, 0}, {0, 0, 0, 0, 0}, {0, 0, 0, 0, 0}},
+			"legend":  map[string]any{"0": map[string]any{"char": ".", "walkable": true, "name": "grass"}},
+			"portals": portals,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name+".json"), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return LoadMaps(dir)
+}
+
+func TestTwoWayPortalAddsTheReturn(t *testing.T) {
+	all, err := loadLinked(t, []map[string]any{{
+		"x": 4, "y": 1, "target_map": "B", "target_x": 0, "target_y": 1,
+		"two_way": true, "transition": "fade", "facing": "right", "level": 3,
+	}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back := all["B"].PortalAt(0, 1)
+	if back == nil {
+		t.Fatal("no return portal in B")
+	}
+	want := Portal{X: 0, Y: 1, TargetMap: "A", TargetX: 4, TargetY: 1, Transition: TransitionFade, Facing: FaceLeft, TwoWay: true}
+	if !reflect.DeepEqual(*back, want) {
+		t.Fatalf("return portal %+v, want %+v", *back, want)
+	}
+	for name, m := range all {
+		if errs := m.Validate(all); len(errs) > 0 {
+			t.Fatalf("map %s: %v", name, errs)
+		}
+	}
+}
+
+func TestTwoWayPortalMustLeadBack(t *testing.T) {
+	all, err := loadLinked(t,
+		[]map[string]any{{"x": 4, "y": 1, "target_map": "B", "target_x": 0, "target_y": 1, "two_way": true}},
+		[]map[string]any{{"x": 0, "y": 1, "target_map": "A", "target_x": 1, "target_y": 1}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(all["B"].Portals); n != 1 {
+		t.Fatalf("B has %d portals, want the existing one left alone", n)
+	}
+	errs := all["A"].Validate(all)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "not back here") {
+		t.Fatalf("errors %v, want one about the portal not leading back", errs)
+	}
+}
+
+func TestPortalOptionsAreChecked(t *testing.T) {
+	for _, bad := range []map[string]any{
+		{"facing": "north"},
+		{"transition": "wipe"},
+		{"level": -1},
+		{"if": []map[string]any{{"flag": "a", "quest": "b"}}},
+	} {
+		p := map[string]any{"x": 4, "y": 1, "target_map": "B", "target_x": 0, "target_y": 1}
+		for k, v := range bad {
+			p[k] = v
+		}
+		if _, err := loadLinked(t, []map[string]any{p}, nil); err == nil {
+			t.Errorf("portal with %v loaded", bad)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package maps
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sync/atomic"
+)
+
+// TileChange sets the tile at (X, Y) to a legend index.
+type TileChange struct {
+	X, Y int
+	Tile int
+}
+
+// tileEdit is one WithTiles step: the version it was made from and the
+// tiles it changed.
+type tileEdit struct {
+	from  uint64
+	tiles [][2]int
+}
+
+// maxTileEdits is how many WithTiles steps a map remembers. A reader that
+// falls further behind than this has to treat every tile as changed.
+const maxTileEdits = 32
+
+// versions numbers map snapshots. It is shared by all maps, so a version
+// never repeats, not even across a reload.
+var versions atomic.Uint64
+
+func nextVersion() uint64 {
+	return versions.Add(1)
+}
+
+// WithTiles returns a new version of the map with the given tiles
+// replaced. m itself is left as it was, so anyone still holding it keeps a
+// consistent picture. Only the rows that change are copied; everything
+// else is shared. Changes that set a tile to what it already is are
+// dropped, and if nothing is left, m is returned.
+func (m *Map) WithTiles(changes []TileChange) (*Map, error) {
+	var changed [][2]int
+	var rows map[int][]int
+	for _, c := range changes {
+		switch {
+		case !m.inBounds(c.X, c.Y):
+			return nil, fmt.Errorf("tile (%d,%d) is out of bounds", c.X, c.Y)
+		case c.Tile < 0 || c.Tile >= len(m.Legend):
+			return nil, fmt.Errorf("tile (%d,%d) index %d out of legend range [0..%d]", c.X, c.Y, c.Tile, len(m.Legend)-1)
+		}
+		row, copied := rows[c.Y]
+		if !copied {
+			row = m.Tiles[c.Y]
+		}
+		if row[c.X] == c.Tile {
+			continue
+		}
+		if !copied {
+			if rows == nil {
+				rows = make(map[int][]int)
+			}
+			row = slices.Clone(row)
+			rows[c.Y] = row
+		}
+		row[c.X] = c.Tile
+		changed = append(changed, [2]int{c.X, c.Y})
+	}
+	if len(changed) == 0 {
+		return m, nil
+	}
+
+	next := *m
+	next.Version = nextVersion()
+	next.Tiles = slices.Clone(m.Tiles)
+	for y, row := range rows {
+		next.Tiles[y] = row
+	}
+	edits := m.edits
+	if len(edits) >= maxTileEdits {
+		edits = edits[len(edits)-maxTileEdits+1:]
+	}
+	next.edits = append(slices.Clone(edits), tileEdit{from: m.Version, tiles: changed})
+	return &next, nil
+}
+
+// TilesChangedSince lists the tiles that differ between version v of this
+// map and m, which may repeat a tile. ok is false if v is not a recent
+// earlier version of this map, for example after a reload or a switch to
+// another map; the caller should then treat every tile as changed.
+func (m *Map) TilesChangedSince(v uint64) (tiles [][2]int, ok bool) {
+	if v == m.Version {
+		return nil, true
+	}
+	for i, e := range m.edits {
+		if e.from != v {
+			continue
+		}
+		for _, e := range m.edits[i:] {
+			tiles = append(tiles, e.tiles...)
+		}
+		return tiles, true
+	}
+	return nil, false
+}
+
+// TileChanges lists the tiles that differ from the map as it was loaded,
+// such as doors opened since, in row order. A map built by hand rather
+// than loaded has nothing to compare against and reports none.
+func (m *Map) TileChanges() []TileChange {
+	var changes []TileChange
+	for y, row := range m.loaded {
+		for x, tile := range row {
+			if m.Tiles[y][x] != tile {
+				changes = append(changes, TileChange{X: x, Y: y, Tile: m.Tiles[y][x]})
+			}
+		}
+	}
+	return changes
+}
+
+// SameLayout reports whether m and old were loaded with the same tiles and
+// legend, so that old's TileChanges mean the same thing on m.
+func (m *Map) SameLayout(old *Map) bool {
+	if m.loaded == nil || old.loaded == nil {
+		return false
+	}
+	return slices.EqualFunc(m.loaded, old.loaded, slices.Equal) && reflect.DeepEqual(m.Legend, old.Legend)
+}
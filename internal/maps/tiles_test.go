@@ -0,0 +1,121 @@
+package maps
+
+import (
+	"slices"
+	"testing"
+)
+
+func testMap() *Map {
+	tiles := [][]int{{0, 0, 0}, {0, 1, 0}}
+	return &Map{
+		Name:    "Test",
+		Version: nextVersion(),
+		Width:   3,
+		Height:  2,
+		Tiles:   tiles,
+		loaded:  tiles,
+		Legend: []TileDef{
+			{Char: '.', Walkable: true, Name: "grass"},
+			{Char: '#', Name: "wall"},
+		},
+	}
+}
+
+func TestWithTilesCopiesOnWrite(t *testing.T) {
+	m := testMap()
+	next, err := m.WithTiles([]TileChange{{X: 2, Y: 0, Tile: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Version == m.Version {
+		t.Fatal("new version has the old version number")
+	}
+	if m.IsWalkable(2, 0) == next.IsWalkable(2, 0) {
+		t.Fatalf("walkable (2,0): old %v new %v, want them to differ", m.IsWalkable(2, 0), next.IsWalkable(2, 0))
+	}
+	if m.Tiles[0][2] != 0 {
+		t.Fatal("the old version changed")
+	}
+	// Untouched rows are shared, not copied
+	if &next.Tiles[1][0] != &m.Tiles[1][0] {
+		t.Fatal("an unchanged row was copied")
+	}
+}
+
+func TestWithTilesNoChange(t *testing.T) {
+	m := testMap()
+	next, err := m.WithTiles([]TileChange{{X: 1, Y: 1, Tile: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != m {
+		t.Fatal("setting a tile to its own value made a new version")
+	}
+}
+
+func TestWithTilesRejectsBadChanges(t *testing.T) {
+	m := testMap()
+	for _, c := range []TileChange{{X: 3, Y: 0}, {X: 0, Y: -1}, {X: 0, Y: 0, Tile: 2}} {
+		if _, err := m.WithTiles([]TileChange{c}); err == nil {
+			t.Errorf("change %+v accepted", c)
+		}
+	}
+}
+
+func TestTilesChangedSince(t *testing.T) {
+	v1 := testMap()
+	v2, _ := v1.WithTiles([]TileChange{{X: 0, Y: 0, Tile: 1}})
+	v3, _ := v2.WithTiles([]TileChange{{X: 2, Y: 1, Tile: 1}})
+
+	if tiles, ok := v3.TilesChangedSince(v3.Version); !ok || len(tiles) != 0 {
+		t.Fatalf("since itself: %v %v", tiles, ok)
+	}
+	if tiles, ok := v3.TilesChangedSince(v2.Version); !ok || !slices.Equal(tiles, [][2]int{{2, 1}}) {
+		t.Fatalf("since v2: %v %v", tiles, ok)
+	}
+	if tiles, ok := v3.TilesChangedSince(v1.Version); !ok || !slices.Equal(tiles, [][2]int{{0, 0}, {2, 1}}) {
+		t.Fatalf("since v1: %v %v", tiles, ok)
+	}
+	// Another map's version, or a fresh load of this one, can't be followed
+	if _, ok := v3.TilesChangedSince(testMap().Version); ok {
+		t.Fatal("followed changes from an unrelated map")
+	}
+
+	// Only the most recent edits are remembered
+	m := v1
+	for i := 0; i < maxTileEdits+1; i++ {
+		m, _ = m.WithTiles([]TileChange{{X: 0, Y: 0, Tile: (i + 1) % 2}})
+	}
+	if _, ok := m.TilesChangedSince(v1.Version); ok {
+		t.Fatal("followed changes further back than maxTileEdits")
+	}
+}
+
+func TestTileChangesAcrossReload(t *testing.T) {
+	m := testMap()
+	if changes := m.TileChanges(); changes != nil {
+		t.Fatalf("fresh map has changes %v", changes)
+	}
+	m, _ = m.WithTiles([]TileChange{{X: 2, Y: 0, Tile: 1}, {X: 1, Y: 1, Tile: 0}})
+	m, _ = m.WithTiles([]TileChange{{X: 2, Y: 0, Tile: 0}, {X: 0, Y: 1, Tile: 1}})
+	want := []TileChange{{X: 0, Y: 1, Tile: 1}, {X: 1, Y: 1, Tile: 0}}
+	if changes := m.TileChanges(); !slices.Equal(changes, want) {
+		t.Fatalf("changes %v, want %v", changes, want)
+	}
+
+	// A reload of the same file matches; an edited one doesn't
+	if !testMap().SameLayout(m) {
+		t.Fatal("a fresh load of the same map has another layout")
+	}
+	edited := testMap()
+	edited.loaded = [][]int{{0, 1, 0}, {0, 1, 0}}
+	if edited.SameLayout(m) {
+		t.Fatal("an edited map has the same layout")
+	}
+	relegended := testMap()
+	relegended.Legend = slices.Clone(relegended.Legend)
+	relegended.Legend[1].Char = 'X'
+	if relegended.SameLayout(m) {
+		t.Fatal("a map with a new legend has the same layout")
+	}
+}
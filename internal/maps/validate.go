@@ -0,0 +1,64 @@
+package maps
+
+import "fmt"
+
+// Validate checks a loaded map against the rest of the world: the spawn
+// must be walkable, every tile index must be in the legend, and portals,
+// interactions and switches must sit inside the map. Portals must land on
+// walkable tiles of their target maps, a two-way portal's target must hold
+// a portal straight back, and switches and their targets must start on
+// tiles that toggle.
+func (m *Map) Validate(allMaps map[string]*Map) []error {
+	var errs []error
+	if !m.IsWalkable(m.SpawnX, m.SpawnY) {
+		errs = append(errs, fmt.Errorf("spawn (%d,%d) is not walkable", m.SpawnX, m.SpawnY))
+	}
+	for y, row := range m.Tiles {
+		for x, idx := range row {
+			if idx < 0 || idx >= len(m.Legend) {
+				errs = append(errs, fmt.Errorf("tile (%d,%d) index %d out of legend range [0..%d]", x, y, idx, len(m.Legend)-1))
+			}
+		}
+	}
+	for _, p := range m.Portals {
+		if !m.inBounds(p.X, p.Y) {
+			errs = append(errs, fmt.Errorf("portal at (%d,%d) is out of bounds", p.X, p.Y))
+		}
+		tm, ok := allMaps[p.TargetMap]
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("portal at (%d,%d) references unknown map %q", p.X, p.Y, p.TargetMap))
+		case !tm.inBounds(p.TargetX, p.TargetY):
+			errs = append(errs, fmt.Errorf("portal at (%d,%d) targets out-of-bounds (%d,%d) in %q", p.X, p.Y, p.TargetX, p.TargetY, p.TargetMap))
+		case !tm.IsWalkable(p.TargetX, p.TargetY):
+			errs = append(errs, fmt.Errorf("portal at (%d,%d) targets non-walkable tile (%d,%d) in %q", p.X, p.Y, p.TargetX, p.TargetY, p.TargetMap))
+		case p.TwoWay:
+			back := tm.PortalAt(p.TargetX, p.TargetY)
+			if back == nil {
+				errs = append(errs, fmt.Errorf("two-way portal at (%d,%d) has no portal back at (%d,%d) in %q", p.X, p.Y, p.TargetX, p.TargetY, p.TargetMap))
+			} else if back.TargetMap != m.Name || back.TargetX != p.X || back.TargetY != p.Y {
+				errs = append(errs, fmt.Errorf("two-way portal at (%d,%d): the portal at (%d,%d) in %q leads to %q (%d,%d), not back here", p.X, p.Y, p.TargetX, p.TargetY, p.TargetMap, back.TargetMap, back.TargetX, back.TargetY))
+			}
+		}
+	}
+	for _, inter := range m.Interactions {
+		if !m.inBounds(inter.X, inter.Y) {
+			errs = append(errs, fmt.Errorf("interaction at (%d,%d) is out of bounds", inter.X, inter.Y))
+		}
+	}
+	for _, sw := range m.Switches {
+		if m.TileAt(sw.X, sw.Y).ToggleTo == nil {
+			errs = append(errs, fmt.Errorf("switch at (%d,%d) is on a tile that doesn't toggle", sw.X, sw.Y))
+		}
+		for _, t := range sw.Targets {
+			if m.TileAt(t.X, t.Y).ToggleTo == nil {
+				errs = append(errs, fmt.Errorf("switch at (%d,%d) targets (%d,%d), which doesn't toggle", sw.X, sw.Y, t.X, t.Y))
+			}
+		}
+	}
+	return errs
+}
+
+func (m *Map) inBounds(x, y int) bool {
+	return x >= 0 && x < m.Width && y >= 0 && y < m.Height
+}
@@ -0,0 +1,56 @@
+package maps
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Watch polls dir every interval and calls onChange whenever a *.json file
+// in it is added, removed or modified. It blocks until ctx is done.
+//
+// Polling keeps the server free of platform-specific file notification
+// code; map edits are rare and a few seconds of delay is fine.
+func Watch(ctx context.Context, dir string, interval time.Duration, onChange func()) {
+	last := dirFingerprint(dir)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		cur := dirFingerprint(dir)
+		if cur == last {
+			continue
+		}
+		last = cur
+		onChange()
+	}
+}
+
+// dirFingerprint summarises the name, size and modification time of every
+// map file in dir. An unreadable directory yields "".
+func dirFingerprint(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	var parts []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s:%d:%d", entry.Name(), info.Size(), info.ModTime().UnixNano()))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "|")
+}
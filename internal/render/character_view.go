@@ -0,0 +1,131 @@
+package render
+
+import "fmt"
+
+const characterPanelW = 44
+
+// EquipSlotInfo is one equipment slot on the character sheet.
+type EquipSlotInfo struct {
+	Slot  string
+	Name  string // "" = empty slot
+	Bonus string
+}
+
+// StatRow is one base stat that stat points can raise.
+type StatRow struct {
+	Label string
+	Value int
+	Gain  int // what one stat point adds
+}
+
+// CharacterSheet is the viewer's equipment, base stats and derived combat
+// stats.
+type CharacterSheet struct {
+	Name       string
+	Level      int
+	Slots      []EquipSlotInfo
+	Stats      []StatRow
+	StatPoints int
+	Cursor     int // equipment slots first, then stats
+
+	Attack, Defense int // base stats
+	Melee, Ranged   int // derived power including gear
+	Magic, Guard    int
+}
+
+// drawCharacterSheet draws the character sheet as a centered box.
+func (e *Engine) drawCharacterSheet(cs *CharacterSheet) {
+	if cs == nil {
+		return
+	}
+	boxW := min(characterPanelW, e.width)
+	slotRows := len(cs.Slots)
+	statRows := len(cs.Stats)
+	// top, slots, divider, stats, divider, 2 derived rows, divider, hint, bottom
+	boxH := slotRows + statRows + 8
+	x0 := (e.width - boxW) / 2
+	y0 := (e.height - HUDRows - boxH) / 2
+	if y0 < 0 {
+		y0 = 0
+	}
+	statTop := slotRows + 2
+	derivedTop := statTop + statRows + 1
+
+	bR, bG, bB := uint8(120), uint8(170), uint8(210)
+	bgR, bgG, bgB := uint8(18), uint8(24), uint8(38)
+	e.drawPanelFrame(x0, y0, boxW, boxH, bR, bG, bB, bgR, bgG, bgB, slotRows+1, derivedTop-1, derivedTop+2)
+	innerR := x0 + boxW - 1
+
+	e.writeText(y0, x0+2, innerR, fmt.Sprintf(" %s  Lv %d ", cs.Name, cs.Level), 220, 235, 255, bgR, bgG, bgB, true)
+	if cs.StatPoints > 0 {
+		pts := fmt.Sprintf(" %d pts ", cs.StatPoints)
+		e.writeText(y0, innerR-len(pts)-1, innerR, pts, 255, 220, 60, bgR, bgG, bgB, true)
+	}
+
+	// selectRow highlights a row when the cursor is on it and returns its
+	// background color.
+	selectRow := func(y, row int) (uint8, uint8, uint8) {
+		if row != cs.Cursor || y >= e.height {
+			return bgR, bgG, bgB
+		}
+		for x := x0 + 1; x < innerR; x++ {
+			e.next[y][x].BgR, e.next[y][x].BgG, e.next[y][x].BgB = 40, 55, 85
+		}
+		e.writeText(y, x0+1, innerR, "▶", 255, 220, 80, 40, 55, 85, true)
+		return 40, 55, 85
+	}
+
+	// Equipment slots
+	for i, slot := range cs.Slots {
+		y := y0 + 1 + i
+		rowBgR, rowBgG, rowBgB := selectRow(y, i)
+		col := e.writeText(y, x0+3, innerR, fmt.Sprintf("%-10s", slot.Slot), 140, 150, 170, rowBgR, rowBgG, rowBgB, false)
+		if slot.Name == "" {
+			e.writeText(y, col, innerR, "(empty)", 90, 95, 110, rowBgR, rowBgG, rowBgB, false)
+			continue
+		}
+		e.writeText(y, col, innerR, slot.Name, 150, 200, 255, rowBgR, rowBgG, rowBgB, i == cs.Cursor)
+		e.writeText(y, innerR-len(slot.Bonus)-1, innerR, slot.Bonus, 180, 180, 195, rowBgR, rowBgG, rowBgB, false)
+	}
+
+	// Base stats, raised by spending stat points
+	for i, st := range cs.Stats {
+		y := y0 + statTop + i
+		rowBgR, rowBgG, rowBgB := selectRow(y, slotRows+i)
+		col := e.writeText(y, x0+3, innerR, fmt.Sprintf("%-10s", st.Label), 140, 150, 170, rowBgR, rowBgG, rowBgB, false)
+		e.writeText(y, col, innerR, fmt.Sprintf("%d", st.Value), 235, 235, 245, rowBgR, rowBgG, rowBgB, true)
+		if cs.StatPoints > 0 && st.Gain > 0 {
+			gain := fmt.Sprintf("+%d per point", st.Gain)
+			e.writeText(y, innerR-len(gain)-1, innerR, gain, 120, 200, 140, rowBgR, rowBgG, rowBgB, false)
+		}
+	}
+
+	// Derived stats: total with gear, and the gear's share
+	stats := []struct {
+		label       string
+		base, total int
+	}{
+		{"Melee ", cs.Attack, cs.Melee},
+		{"Ranged", cs.Attack / 2, cs.Ranged},
+		{"Magic ", cs.Attack * 2, cs.Magic},
+		{"Defense", cs.Defense, cs.Guard},
+	}
+	for i, st := range stats {
+		y := y0 + derivedTop + i/2
+		col := x0 + 2
+		if i%2 == 1 {
+			col = x0 + boxW/2 + 1
+		}
+		col = e.writeText(y, col, innerR, st.label+" ", 140, 150, 170, bgR, bgG, bgB, false)
+		col = e.writeText(y, col, innerR, fmt.Sprintf("%d", st.total), 235, 235, 245, bgR, bgG, bgB, true)
+		if bonus := st.total - st.base; bonus != 0 {
+			e.writeText(y, col, innerR, fmt.Sprintf(" (%+d)", bonus), 120, 200, 140, bgR, bgG, bgB, false)
+		}
+	}
+
+	hint := "↑↓ Select  Enter Unequip  C Close"
+	if cs.Cursor >= slotRows {
+		hint = "↑↓ Select  Enter Spend point  C Close"
+	}
+	e.writeText(y0+derivedTop+3, x0+2, innerR, hint, 130, 130, 145, bgR, bgG, bgB, false)
+}
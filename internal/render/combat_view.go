@@ -16,7 +16,7 @@ const (
 )
 
 // renderCombatView renders the full combat screen.
-func (e *Engine) renderCombatView(combat *CombatRenderData, viewerName string, viewerColor, totalPlayers int, tick uint64, stats HUDStats) string {
+func (e *Engine) renderCombatView(combat *CombatRenderData, viewerName string, viewerColor, totalPlayers int, tick uint64, stats HUDStats, panels Panels, ui *SessionUI) string {
 	// Transition flash effect: fill screen with dark red/black
 	if combat.Transitioning {
 		flashR, flashG, flashB := uint8(40), uint8(5), uint8(5)
@@ -71,7 +71,7 @@ func (e *Engine) renderCombatView(combat *CombatRenderData, viewerName string, v
 	livingIdx := 0
 	isViewerTurn := combat.Phase == cPhasePlayerTurn && combat.CurrentTurn == combat.ViewerID
 	for _, enemy := range combat.Enemies {
-		if curY+2 >= hudY-5 {
+		if curY+enemy.rows() >= hudY-5 {
 			break
 		}
 		targeted := false
@@ -84,7 +84,7 @@ func (e *Engine) renderCombatView(combat *CombatRenderData, viewerName string, v
 		if enemy.Alive {
 			livingIdx++
 		}
-		curY += 2
+		curY += enemy.rows()
 	}
 
 	// ├── BATTLE  Round N ──────┤  enemy/player divider
@@ -127,6 +127,16 @@ func (e *Engine) renderCombatView(combat *CombatRenderData, viewerName string, v
 	if combat.Phase == cPhaseVictory {
 		cy := e.height/2 - 1
 		e.drawCenteredText(cy, "★ VICTORY ★", 255, 220, 50, bgR, bgG, bgB, true)
+		if combat.EXPGained > 0 {
+			e.drawCenteredText(cy+1, fmt.Sprintf("+%d EXP", combat.EXPGained), 60, 200, 180, bgR, bgG, bgB, false)
+		}
+		for i, msg := range combat.LevelUps {
+			fR, fG, fB := uint8(255), uint8(220), uint8(60)
+			if (tick/5+uint64(i))%2 == 1 {
+				fR, fG, fB = 255, 255, 200
+			}
+			e.drawCenteredText(cy+2+i, "▲ "+msg+" ▲", fR, fG, fB, bgR, bgG, bgB, true)
+		}
 	} else if combat.Phase == cPhaseDefeat {
 		cy := e.height/2 - 1
 		e.drawCenteredText(cy, "✖ DEFEAT ✖", 255, 50, 50, bgR, bgG, bgB, true)
@@ -135,6 +145,12 @@ func (e *Engine) renderCombatView(combat *CombatRenderData, viewerName string, v
 	// --- Combat HUD (bottom rows) ---
 	e.drawCombatHUD(combat, viewerName, viewerColor, totalPlayers, stats, bR, bG, bB)
 
+	// Item picker for the Item action
+	e.drawPanels(panels)
+
+	// Command prompt and notices
+	e.drawSessionUI(ui)
+
 	return e.emitDiff()
 }
 
@@ -178,23 +194,23 @@ func (e *Engine) drawEnemyRow(row int, enemy CombatEnemy, tick uint64, targeted
 		}
 	}
 	col := 2
-	if enemy.Alive {
-		// Rat sprite chars
-		ratChars := []rune{'>', '·', '~'}
-		ratFrame := int(tick/8) % 2
-		for i, ch := range ratChars {
-			x := col + i
-			if ratFrame == 1 && i == 2 {
-				ch = '-'
+	if enemy.Alive && len(enemy.Art) > 0 {
+		// Per-enemy art, cycling frames every 8 ticks
+		frame := enemy.Art[int(tick/8)%len(enemy.Art)]
+		for dy, line := range frame {
+			y := row + dy
+			if y >= e.height {
+				break
 			}
-			if x < e.width-1 && row < e.height {
-				e.next[row][x] = Cell{Ch: ch, FgR: 180, FgG: 160, FgB: 140, BgR: bgR, BgG: bgG, BgB: bgB}
+			for i, ch := range []rune(line) {
+				x := col + i
+				if ch != ' ' && x < e.width-1 {
+					e.next[y][x] = Cell{Ch: ch, FgR: enemy.Color[0], FgG: enemy.Color[1], FgB: enemy.Color[2], BgR: bgR, BgG: bgG, BgB: bgB}
+				}
 			}
 		}
-		col += 4
-	} else {
-		col += 4
 	}
+	col += enemy.artWidth() + 1
 
 	// Enemy name
 	label := enemy.Label
@@ -217,7 +233,7 @@ func (e *Engine) drawEnemyRow(row int, enemy CombatEnemy, tick uint64, targeted
 	if barRow >= e.height {
 		return
 	}
-	e.drawHPBar(barRow, 2, 20, enemy.HP, enemy.MaxHP, 200, 50, 50, enemy.Alive)
+	e.drawHPBar(barRow, col, 20, enemy.HP, enemy.MaxHP, 200, 50, 50, enemy.Alive)
 }
 
 // drawHPBar draws a colored HP bar.
@@ -284,12 +300,14 @@ func (e *Engine) drawCombatPlayerRow(row int, cp CombatPlayer) {
 	name := cp.Name
 	if !cp.Alive {
 		name += " (fallen)"
+	} else if cp.Away {
+		name += " (away)"
 	}
 	if cp.IsViewer {
 		name += " ←"
 	}
 	nameR, nameG, nameB := pR, pG, pB
-	if !cp.Alive {
+	if !cp.Alive || cp.Away {
 		nameR, nameG, nameB = 80, 80, 90
 	}
 	for i, r := range []rune(name) {
@@ -429,6 +447,7 @@ func (e *Engine) drawCombatHUD(combat *CombatRenderData, viewerName string, view
 			{"2", "Ranged", 2},
 			{"3", "Magic", 3},
 			{"4", "Defend", 4},
+			{"5", "Item", 5},
 		}
 		col := 1
 		for i, a := range actions {
@@ -445,8 +464,10 @@ func (e *Engine) drawCombatHUD(combat *CombatRenderData, viewerName string, view
 		}
 		if combat.ViewerAction >= 1 && combat.ViewerAction <= 3 {
 			e.writeText(row3, 1, splitCol, "←→:Target  Enter:Confirm", 180, 180, 195, bgR, bgG, bgB, false)
+		} else if combat.ViewerAction == 5 {
+			e.writeText(row3, 1, splitCol, "↑↓:Item  Enter:Use", 180, 180, 195, bgR, bgG, bgB, false)
 		} else {
-			e.writeText(row3, 1, splitCol, "Pick an action (1-4)", 130, 130, 145, bgR, bgG, bgB, false)
+			e.writeText(row3, 1, splitCol, "Pick an action (1-5)", 130, 130, 145, bgR, bgG, bgB, false)
 		}
 	}
 
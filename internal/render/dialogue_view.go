@@ -0,0 +1,70 @@
+package render
+
+import "fmt"
+
+const dialogueBoxMaxW = 60
+
+// DialogueBox is the viewer's open conversation with an NPC.
+type DialogueBox struct {
+	Name    string
+	Color   [3]uint8 // the NPC's sprite color, used for the name
+	Text    string
+	Choices []string
+}
+
+// drawDialogue draws the conversation in a box above the chat panel.
+func (e *Engine) drawDialogue(d *DialogueBox) {
+	if d == nil {
+		return
+	}
+	boxW := min(dialogueBoxMaxW, e.width)
+	textLines := wrapRunes(d.Text, boxW-4)
+	var choiceLines []string
+	for i, c := range d.Choices {
+		for j, line := range wrapRunes(c, boxW-7) {
+			if j == 0 {
+				choiceLines = append(choiceLines, fmt.Sprintf("%d. %s", i+1, line))
+			} else {
+				choiceLines = append(choiceLines, "   "+line)
+			}
+		}
+	}
+
+	// top, text, [divider, choices], divider, hint, bottom
+	boxH := len(textLines) + 4
+	dividers := []int{len(textLines) + 1}
+	if len(choiceLines) > 0 {
+		boxH += len(choiceLines) + 1
+		dividers = []int{len(textLines) + 1, len(textLines) + len(choiceLines) + 2}
+	}
+	x0 := (e.width - boxW) / 2
+	y0 := max(e.height-HUDRows-chatPanelLines-boxH, 1)
+
+	bR, bG, bB := uint8(190), uint8(170), uint8(130)
+	bgR, bgG, bgB := uint8(28), uint8(24), uint8(34)
+	e.drawPanelFrame(x0, y0, boxW, boxH, bR, bG, bB, bgR, bgG, bgB, dividers...)
+	innerR := x0 + boxW - 1
+
+	e.writeText(y0, x0+2, innerR, " "+d.Name+" ", d.Color[0]/2+128, d.Color[1]/2+128, d.Color[2]/2+128, bgR, bgG, bgB, true)
+	y := y0 + 1
+	for _, line := range textLines {
+		e.writeText(y, x0+2, innerR, line, 235, 230, 220, bgR, bgG, bgB, false)
+		y++
+	}
+	if len(choiceLines) > 0 {
+		y++
+		for _, line := range choiceLines {
+			e.writeText(y, x0+2, innerR, line, 255, 220, 120, bgR, bgG, bgB, false)
+			y++
+		}
+	}
+
+	hint := "Enter ▸  Arrows Leave"
+	switch n := len(d.Choices); {
+	case n == 1:
+		hint = "1 Choose  Arrows Leave"
+	case n > 1:
+		hint = fmt.Sprintf("1-%d Choose  Arrows Leave", n)
+	}
+	e.writeText(y+1, x0+2, innerR, hint, 130, 130, 145, bgR, bgG, bgB, false)
+}
@@ -43,8 +43,26 @@ type PlayerInfo struct {
 	MP, MaxMP           int
 	EXP                 int
 	Level               int
+	EXPInLevel          int // EXP earned since the current level began
+	EXPToNext           int // EXP the current level spans, 0 at the cap
+	StatPoints          int // unspent stat points
+	LevelUpFlash        int // ticks left of the level-up celebration
+	RegenHP             int // per regen pulse, 0 = not regenerating
+	RegenStamina        int
+	RegenMP             int
 	InCombat            bool
 	CombatTransition    int
+	Speech              string  // chat bubble text, "" = none
+	Fade                float64 // portal transition: 0 = clear, 1 = black
+}
+
+// NPCInfo is NPC data for rendering.
+type NPCInfo struct {
+	ID    string
+	Name  string
+	X, Y  int
+	Dir   int // 0=down, 1=up, 2=left, 3=right
+	Color [3]uint8
 }
 
 // CombatRenderData holds combat state for the renderer.
@@ -60,6 +78,25 @@ type CombatRenderData struct {
 	Transitioning bool
 	ViewerAction  int   // selected action (1-3, 0=none)
 	ViewerTarget  int   // selected enemy target index
+	EXPGained     int      // shown on the victory screen
+	LevelUps      []string // level-up announcements for the victory screen
+}
+
+// Panels are game screens drawn over the world or combat view.
+// Nil fields are closed.
+type Panels struct {
+	Inventory *InventoryPanel
+	Character *CharacterSheet
+	Dialogue  *DialogueBox
+	Journal   *JournalPanel
+}
+
+// drawPanels draws whichever panels are open.
+func (e *Engine) drawPanels(p Panels) {
+	e.drawInventory(p.Inventory)
+	e.drawCharacterSheet(p.Character)
+	e.drawJournal(p.Journal)
+	e.drawDialogue(p.Dialogue)
 }
 
 // CombatEnemy is enemy data for rendering.
@@ -69,6 +106,34 @@ type CombatEnemy struct {
 	MaxHP int
 	ID    int
 	Alive bool
+	Art   [][]string // animation frames; each frame is a block of lines
+	Color [3]uint8
+}
+
+// rows returns how many screen rows the enemy's entry occupies:
+// the art height, but at least the name row plus the HP bar row.
+func (ce CombatEnemy) rows() int {
+	h := 0
+	if len(ce.Art) > 0 {
+		h = len(ce.Art[0])
+	}
+	if h < 2 {
+		h = 2
+	}
+	return h
+}
+
+// artWidth returns the widest line across all art frames.
+func (ce CombatEnemy) artWidth() int {
+	w := 0
+	for _, frame := range ce.Art {
+		for _, line := range frame {
+			if n := len([]rune(line)); n > w {
+				w = n
+			}
+		}
+	}
+	return w
 }
 
 // CombatPlayer is player data for combat rendering.
@@ -80,6 +145,7 @@ type CombatPlayer struct {
 	Alive    bool
 	Color    int
 	IsViewer bool
+	Away     bool // disconnected, holding their place
 }
 
 // Engine is a per-session double-buffer diff renderer.
@@ -91,14 +157,22 @@ type Engine struct {
 	lastDebugView bool
 	lastDebugPage int
 	lastInCombat  bool
+	anim          TileAnim
+
+	// Sprites of tiles that don't animate, for tileVer of the map drawn
+	// last. Tile changes in later versions drop the affected entries.
+	tileCache map[[2]int]TileSprites
+	tileVer   uint64
 }
 
-// NewEngine creates a renderer for the given terminal dimensions.
-func NewEngine(width, height int) *Engine {
+// NewEngine creates a renderer for the given terminal dimensions, animating
+// tiles at the given intervals.
+func NewEngine(width, height int, anim TileAnim) *Engine {
 	e := &Engine{
 		width:      width,
 		height:     height,
 		firstFrame: true,
+		anim:       anim,
 	}
 	e.current = e.makeBuffer(sentinel)
 	e.next = e.makeBuffer(Cell{})
@@ -114,6 +188,42 @@ func (e *Engine) Resize(width, height int) {
 	e.firstFrame = true
 }
 
+// syncTileCache brings the sprite cache up to tileMap's version. Tiles
+// changed since the last frame lose their sprites, and so do their
+// neighbours, whose connections may have changed. Another map, or a version
+// too far back to follow, empties the cache.
+func (e *Engine) syncTileCache(tileMap *maps.Map) {
+	if e.tileCache != nil && tileMap.Version == e.tileVer {
+		return
+	}
+	changed, ok := tileMap.TilesChangedSince(e.tileVer)
+	if !ok || e.tileCache == nil {
+		e.tileCache = make(map[[2]int]TileSprites)
+	}
+	for _, t := range changed {
+		for _, d := range [][2]int{{0, 0}, {0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+			delete(e.tileCache, [2]int{t[0] + d[0], t[1] + d[1]})
+		}
+	}
+	e.tileVer = tileMap.Version
+}
+
+// dim darkens the top rows of the next frame towards black by amount,
+// from 0 (unchanged) to 1 (black).
+func (e *Engine) dim(rows int, amount float64) {
+	if amount > 1 {
+		amount = 1
+	}
+	scale := func(c uint8) uint8 { return uint8(float64(c) * (1 - amount)) }
+	for y := 0; y < rows && y < e.height; y++ {
+		for x := range e.next[y] {
+			c := &e.next[y][x]
+			c.FgR, c.FgG, c.FgB = scale(c.FgR), scale(c.FgG), scale(c.FgB)
+			c.BgR, c.BgG, c.BgB = scale(c.BgR), scale(c.BgG), scale(c.BgB)
+		}
+	}
+}
+
 func (e *Engine) makeBuffer(fill Cell) [][]Cell {
 	buf := make([][]Cell, e.height)
 	for y := 0; y < e.height; y++ {
@@ -130,10 +240,13 @@ func (e *Engine) Render(
 	viewerID string,
 	tileMap *maps.Map,
 	players []PlayerInfo,
+	npcs []NPCInfo,
 	termW, termH int,
 	tick uint64,
 	totalPlayers int,
 	combat *CombatRenderData,
+	panels Panels,
+	ui *SessionUI,
 ) string {
 	if termW != e.width || termH != e.height {
 		e.Resize(termW, termH)
@@ -149,6 +262,10 @@ func (e *Engine) Render(
 	var viewerSTA, viewerMaxSTA int
 	var viewerMP, viewerMaxMP int
 	var viewerEXP, viewerLevel int
+	var viewerEXPInLevel, viewerEXPToNext int
+	var viewerStatPoints, viewerLevelUpFlash int
+	var viewerRegenHP, viewerRegenSTA, viewerRegenMP int
+	var viewerFade float64
 	for _, p := range players {
 		if p.ID == viewerID {
 			viewerX = p.X
@@ -165,6 +282,14 @@ func (e *Engine) Render(
 			viewerMaxMP = p.MaxMP
 			viewerEXP = p.EXP
 			viewerLevel = p.Level
+			viewerEXPInLevel = p.EXPInLevel
+			viewerEXPToNext = p.EXPToNext
+			viewerStatPoints = p.StatPoints
+			viewerLevelUpFlash = p.LevelUpFlash
+			viewerRegenHP = p.RegenHP
+			viewerRegenSTA = p.RegenStamina
+			viewerRegenMP = p.RegenMP
+			viewerFade = p.Fade
 			break
 		}
 	}
@@ -189,6 +314,9 @@ func (e *Engine) Render(
 		Stamina: viewerSTA, MaxStamina: viewerMaxSTA,
 		MP: viewerMP, MaxMP: viewerMaxMP,
 		EXP: viewerEXP, Level: viewerLevel,
+		EXPInLevel: viewerEXPInLevel, EXPToNext: viewerEXPToNext,
+		StatPoints: viewerStatPoints, LevelUpFlash: viewerLevelUpFlash,
+		RegenHP: viewerRegenHP, RegenStamina: viewerRegenSTA, RegenMP: viewerRegenMP,
 	}
 
 	if viewerDebug {
@@ -196,10 +324,12 @@ func (e *Engine) Render(
 	}
 
 	if combat != nil {
-		return e.renderCombatView(combat, viewerName, viewerColor, totalPlayers, tick, statsInfo)
+		return e.renderCombatView(combat, viewerName, viewerColor, totalPlayers, tick, statsInfo, panels, ui)
 	}
 
 	vp := NewViewport(viewerX, viewerY, termW, termH, tileMap.Width, tileMap.Height, HUDRows)
+	e.syncTileCache(tileMap)
+	clk := AnimClock{Tick: tick, TileAnim: e.anim}
 
 	// Clear next buffer
 	bgCell := Cell{Ch: ' ', BgR: 10, BgG: 10, BgB: 15}
@@ -227,8 +357,14 @@ func (e *Engine) Render(
 			if wx < 0 || wx >= tileMap.Width || wy < 0 || wy >= tileMap.Height {
 				continue
 			}
-			tile := tileMap.TileAt(wx, wy)
-			ts := TileSprite(tile, wx, wy, tick, tileMap)
+			ts, cached := e.tileCache[[2]int{wx, wy}]
+			if !cached {
+				var still bool
+				ts, still = tileSprite(tileMap.TileAt(wx, wy), wx, wy, clk, tileMap)
+				if still {
+					e.tileCache[[2]int{wx, wy}] = ts
+				}
+			}
 			sx := vp.OffsetX + tx*TileWidth
 			sy := vp.OffsetY + ty*TileHeight
 
@@ -248,7 +384,15 @@ func (e *Engine) Render(
 		}
 	}
 
-	// --- Pass 2: Players ---
+	// --- Pass 2: NPCs, then players ---
+	for _, n := range npcs {
+		sx, sy := vp.WorldToScreen(n.X, n.Y)
+		if sx+TileWidth <= 0 || sx >= termW || sy+TileHeight <= 0 || sy >= (termH-HUDRows) {
+			continue
+		}
+		e.stampSprite(sx, sy, NPCSprite(n.Dir, n.Color), true)
+	}
+
 	var viewerPopup *InteractionPopup
 	for _, p := range players {
 		sx, sy := vp.WorldToScreen(p.X, p.Y)
@@ -268,14 +412,32 @@ func (e *Engine) Render(
 		e.stampSprite(ov.sx, ov.sy, ov.sprite, true)
 	}
 
+	// Speech bubbles above players who just spoke on the map channel
+	for _, p := range players {
+		if p.Speech != "" {
+			e.drawSpeechBubble(p, vp, termH)
+		}
+	}
+
 	// Draw interaction popup above sign tile
 	if viewerPopup != nil {
 		e.drawInteractionPopup(viewerPopup, vp, termH)
 	}
 
+	// Darken the world during a portal transition
+	if viewerFade > 0 {
+		e.dim(termH-HUDRows, viewerFade)
+	}
+
 	// Draw HUD
 	e.drawHUD(viewerName, viewerColor, totalPlayers, tileMap.Name, statsInfo)
 
+	// Inventory and character screens over the world view
+	e.drawPanels(panels)
+
+	// Command prompt and notices
+	e.drawSessionUI(ui)
+
 	// Diff current vs next, emit only changed cells
 	var sb strings.Builder
 	sb.Grow(16384)
@@ -364,9 +526,42 @@ func (e *Engine) drawInteractionPopup(popup *InteractionPopup, vp Viewport, term
 	}
 
 	// Colors: warm border, dark bg, light text
-	borderR, borderG, borderB := uint8(200), uint8(180), uint8(120)
-	bgR, bgG, bgB := uint8(30), uint8(25), uint8(45)
-	textR, textG, textB := uint8(240), uint8(230), uint8(200)
+	e.drawPopupBox(popupX, popupY, textRunes,
+		[3]uint8{200, 180, 120}, [3]uint8{30, 25, 45}, [3]uint8{240, 230, 200})
+}
+
+// maxSpeechRunes caps bubble text; the full line is in the chat panel.
+const maxSpeechRunes = 28
+
+// drawSpeechBubble draws a chat bubble centered above a player's sprite.
+func (e *Engine) drawSpeechBubble(p PlayerInfo, vp Viewport, termH int) {
+	sx, sy := vp.WorldToScreen(p.X, p.Y)
+	textRunes := []rune(p.Speech)
+	if len(textRunes) > maxSpeechRunes {
+		textRunes = append(textRunes[:maxSpeechRunes-1], '…')
+	}
+	popupW := len(textRunes) + 4
+	popupX := sx + (TileWidth-popupW)/2
+	popupY := sy - 3
+	if popupX < 0 {
+		popupX = 0
+	}
+	if popupX+popupW > e.width {
+		popupX = e.width - popupW
+	}
+	if popupY < 0 || popupY+3 > termH-HUDRows {
+		return
+	}
+	e.drawPopupBox(popupX, popupY, textRunes,
+		[3]uint8{170, 170, 185}, [3]uint8{235, 235, 240}, [3]uint8{25, 25, 35})
+}
+
+// drawPopupBox draws a 3-row bordered box containing a single line of text.
+func (e *Engine) drawPopupBox(popupX, popupY int, textRunes []rune, border, bg, fg [3]uint8) {
+	popupW := len(textRunes) + 4 // "│ " + text + " │"
+	borderR, borderG, borderB := border[0], border[1], border[2]
+	bgR, bgG, bgB := bg[0], bg[1], bg[2]
+	textR, textG, textB := fg[0], fg[1], fg[2]
 
 	setCell := func(sx, sy int, ch rune, fgR, fgG, fgB, bgR, bgG, bgB uint8) {
 		if sx >= 0 && sx < e.width && sy >= 0 && sy < e.height {
@@ -407,6 +602,13 @@ type HUDStats struct {
 	MP, MaxMP           int
 	EXP                 int
 	Level               int
+	EXPInLevel          int
+	EXPToNext           int // 0 at the level cap
+	StatPoints          int
+	LevelUpFlash        int // ticks left of the level-up celebration
+	RegenHP             int // per regen pulse, shown while below max
+	RegenStamina        int
+	RegenMP             int
 }
 
 // --- HUD ---
@@ -463,18 +665,36 @@ func (e *Engine) drawHUD(playerName string, playerColor, playerCount int, mapNam
 	lvText := fmt.Sprintf("Lv %d", stats.Level)
 	col = e.writeText(row2, 1, splitCol, lvText, 100, 220, 220, bgR, bgG, bgB, true)
 	col += 2
-	expInLevel := stats.EXP % 50
-	expNums := fmt.Sprintf("%d/%d", expInLevel, 50)
-	expBarWidth := splitCol - col - len("EXP") - 1 - 1 - len(expNums)
-	if expBarWidth < 4 {
-		expBarWidth = 4
+	if stats.LevelUpFlash > 0 {
+		// Celebrate in place of the EXP bar, blinking a few times a second
+		fR, fG, fB := uint8(255), uint8(220), uint8(60)
+		if stats.LevelUpFlash/5%2 == 1 {
+			fR, fG, fB = 255, 255, 200
+		}
+		col = e.writeText(row2, col, splitCol, "★ LEVEL UP! ★", fR, fG, fB, bgR, bgG, bgB, true)
+		if stats.StatPoints > 0 {
+			e.writeText(row2, col+2, splitCol, fmt.Sprintf("%d pts (C)", stats.StatPoints), 180, 200, 140, bgR, bgG, bgB, false)
+		}
+	} else if stats.EXPToNext == 0 {
+		e.writeText(row2, col, splitCol, "EXP MAX", 60, 200, 180, bgR, bgG, bgB, true)
+	} else {
+		expNums := fmt.Sprintf("%d/%d", stats.EXPInLevel, stats.EXPToNext)
+		points := ""
+		if stats.StatPoints > 0 {
+			points = fmt.Sprintf("+%d", stats.StatPoints) // unspent stat points
+		}
+		expBarWidth := splitCol - col - len("EXP") - 1 - 1 - len(expNums) - len(points) - 1
+		if expBarWidth < 4 {
+			expBarWidth = 4
+		}
+		col += e.drawStatBar(row2, col, "EXP", stats.EXPInLevel, stats.EXPToNext, expBarWidth,
+			60, 200, 180, 50, 190, 160, bgR, bgG, bgB)
+		e.writeText(row2, col+1, splitCol, points, 255, 220, 60, bgR, bgG, bgB, true)
 	}
-	e.drawStatBar(row2, col, "EXP", expInLevel, 50, expBarWidth,
-		60, 200, 180, 50, 190, 160, bgR, bgG, bgB)
 
 	// Row 3: controls
 	row3 := hudY + 3
-	e.writeText(row3, 1, splitCol, "←↑↓→/WASD Move  │  Q Quit", 130, 130, 145, bgR, bgG, bgB, false)
+	e.writeText(row3, 1, splitCol, "WASD Move │ I Items │ C Gear │ T Chat │ Q Quit", 130, 130, 145, bgR, bgG, bgB, false)
 
 	// --- Right column: stat bars ---
 	rightStart := splitCol + 2
@@ -482,18 +702,33 @@ func (e *Engine) drawHUD(playerName string, playerColor, playerCount int, mapNam
 	staNums := fmt.Sprintf("%d/%d", stats.Stamina, stats.MaxStamina)
 	mpNums := fmt.Sprintf("%d/%d", stats.MP, stats.MaxMP)
 	maxNumLen := max(len(hpNums), max(len(staNums), len(mpNums)))
-	barWidth := (e.width - rightStart) - 9 - maxNumLen
+	hpRegen := regenText(stats.HP, stats.MaxHP, stats.RegenHP)
+	staRegen := regenText(stats.Stamina, stats.MaxStamina, stats.RegenStamina)
+	mpRegen := regenText(stats.MP, stats.MaxMP, stats.RegenMP)
+	maxRegenLen := max(len(hpRegen), max(len(staRegen), len(mpRegen)))
+	barWidth := (e.width - rightStart) - 9 - maxNumLen - maxRegenLen
 	if barWidth < 4 {
 		barWidth = 4
 	}
 
 	hpFillR, hpFillG, hpFillB := hpBarColor(stats.HP, stats.MaxHP)
-	e.drawStatBar(row1, rightStart, "Health ", stats.HP, stats.MaxHP, barWidth,
+	w := e.drawStatBar(row1, rightStart, "Health ", stats.HP, stats.MaxHP, barWidth,
 		255, 80, 80, hpFillR, hpFillG, hpFillB, bgR, bgG, bgB)
-	e.drawStatBar(row2, rightStart, "Stamina", stats.Stamina, stats.MaxStamina, barWidth,
+	e.writeText(row1, rightStart+w, e.width, hpRegen, 120, 200, 120, bgR, bgG, bgB, false)
+	w = e.drawStatBar(row2, rightStart, "Stamina", stats.Stamina, stats.MaxStamina, barWidth,
 		240, 190, 60, 210, 170, 50, bgR, bgG, bgB)
-	e.drawStatBar(row3, rightStart, "Magic  ", stats.MP, stats.MaxMP, barWidth,
+	e.writeText(row2, rightStart+w, e.width, staRegen, 200, 180, 110, bgR, bgG, bgB, false)
+	w = e.drawStatBar(row3, rightStart, "Magic  ", stats.MP, stats.MaxMP, barWidth,
 		100, 140, 255, 90, 110, 240, bgR, bgG, bgB)
+	e.writeText(row3, rightStart+w, e.width, mpRegen, 130, 150, 230, bgR, bgG, bgB, false)
+}
+
+// regenText is the "+n" shown after a stat bar while it is refilling.
+func regenText(current, maximum, rate int) string {
+	if rate <= 0 || current >= maximum {
+		return ""
+	}
+	return fmt.Sprintf(" +%d", rate)
 }
 
 // hpBarColor returns the fill color for an HP bar based on current/max ratio.
@@ -596,6 +831,7 @@ func (e *Engine) writeHUDTextLine(row int, text string, fgR, fgG, fgB, bgR, bgG,
 // renderDebugView draws a paginated debug view of tile and player sprites.
 // Page 0: non-connected tile sprites, Page 1: connected tile sprites, Page 2: player sprites.
 func (e *Engine) renderDebugView(viewerColor, page int, tick uint64) string {
+	clk := AnimClock{Tick: tick, TileAnim: e.anim}
 	// Clear buffer with dark background
 	bgCell := Cell{Ch: ' ', BgR: 18, BgG: 18, BgB: 24}
 	for y := 0; y < e.height; y++ {
@@ -660,7 +896,7 @@ func (e *Engine) renderDebugView(viewerColor, page int, tick uint64) string {
 			maxDY := 0
 			if entry.variants > 0 {
 				wx, wy := variantCoord(0, entry.variants)
-				ts := entry.fn(wx, wy, tick, nil)
+				ts := entry.fn(wx, wy, clk, nil)
 				for _, ov := range ts.Overlays {
 					if ov.DY > maxDY {
 						maxDY = ov.DY
@@ -674,7 +910,7 @@ func (e *Engine) renderDebugView(viewerColor, page int, tick uint64) string {
 
 			for v := 0; v < entry.variants; v++ {
 				wx, wy := variantCoord(v, entry.variants)
-				ts := entry.fn(wx, wy, tick, nil)
+				ts := entry.fn(wx, wy, clk, nil)
 				baseX := sx + v*(TileWidth+gap)
 				baseY := sy + 1 + overlayPixels // push base down so overlays fit above
 				e.stampSprite(baseX, baseY, ts.Base, false)
@@ -728,7 +964,7 @@ func (e *Engine) renderDebugView(viewerColor, page int, tick uint64) string {
 			groupWidth := entry.variants*TileWidth + (entry.variants-1)*gap
 			sx, sy := placeGroup(entry.name, groupWidth)
 			for v := 0; v < entry.variants; v++ {
-				sprite := entry.connFn(ConnE|ConnW, uint(v), tick)
+				sprite := entry.connFn(ConnE|ConnW, uint(v), clk)
 				e.stampSprite(sx+v*(TileWidth+gap), sy+1, sprite, false)
 			}
 
@@ -745,10 +981,10 @@ func (e *Engine) renderDebugView(viewerColor, page int, tick uint64) string {
 					if pattern[py][px] {
 						mask := patMask(px, py)
 						v := TileHash(px, py) % uint(entry.variants)
-						sprite := entry.connFn(mask, v, tick)
+						sprite := entry.connFn(mask, v, clk)
 						e.stampSprite(screenX, screenY, sprite, false)
 					} else {
-						sprite := grassSprite(TileHash(px, py)%4, tick)
+						sprite := grassSprite(TileHash(px, py)%4, clk)
 						e.stampSprite(screenX, screenY, sprite, false)
 					}
 				}
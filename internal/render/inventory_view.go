@@ -0,0 +1,143 @@
+package render
+
+import "fmt"
+
+const (
+	inventoryPanelW    = 44 // columns including borders
+	inventoryPanelRows = 10 // item rows visible before scrolling
+)
+
+// InventoryItem is one inventory row for rendering.
+type InventoryItem struct {
+	Name        string
+	Description string
+	Qty         int
+	Usable      bool
+	Slot        string // gear slot name, "" for non-gear
+	Bonus       string // gear bonus summary
+}
+
+// InventoryPanel is the viewer's open inventory.
+type InventoryPanel struct {
+	Items    []InventoryItem
+	Cursor   int
+	Slots    int  // total slots, for the "n/max" counter
+	InCombat bool // picking an item as a combat action
+}
+
+// drawInventory draws the inventory as a centered box over the current view.
+func (e *Engine) drawInventory(inv *InventoryPanel) {
+	if inv == nil {
+		return
+	}
+	boxW := min(inventoryPanelW, e.width)
+	rows := min(max(len(inv.Items), 1), inventoryPanelRows)
+	boxH := rows + 6 // top, items, divider, description, divider, hint, bottom
+	x0 := (e.width - boxW) / 2
+	y0 := (e.height - HUDRows - boxH) / 2
+	if y0 < 0 {
+		y0 = 0
+	}
+
+	bR, bG, bB := uint8(200), uint8(180), uint8(120)
+	bgR, bgG, bgB := uint8(25), uint8(22), uint8(38)
+
+	e.drawPanelFrame(x0, y0, boxW, boxH, bR, bG, bB, bgR, bgG, bgB, rows+1, rows+3)
+	innerR := x0 + boxW - 1
+
+	title := " Inventory "
+	if inv.InCombat {
+		title = " Use which item? "
+	}
+	e.writeText(y0, x0+2, innerR, title, 255, 230, 160, bgR, bgG, bgB, true)
+	count := fmt.Sprintf(" %d/%d ", len(inv.Items), inv.Slots)
+	e.writeText(y0, innerR-len(count)-1, innerR, count, 160, 150, 120, bgR, bgG, bgB, false)
+
+	// Item rows, scrolled so the cursor stays visible
+	if len(inv.Items) == 0 {
+		e.writeText(y0+1, x0+2, innerR, "Your bag is empty.", 120, 120, 135, bgR, bgG, bgB, false)
+	}
+	first := 0
+	if inv.Cursor >= rows {
+		first = inv.Cursor - rows + 1
+	}
+	for i := 0; i < rows && first+i < len(inv.Items); i++ {
+		idx := first + i
+		it := inv.Items[idx]
+		y := y0 + 1 + i
+		fgR, fgG, fgB := uint8(220), uint8(220), uint8(230)
+		if it.Slot != "" {
+			fgR, fgG, fgB = 150, 200, 255
+		} else if !it.Usable {
+			fgR, fgG, fgB = 140, 140, 155
+		}
+		selected := idx == inv.Cursor
+		rowBgR, rowBgG, rowBgB := bgR, bgG, bgB
+		if selected && y < e.height {
+			rowBgR, rowBgG, rowBgB = 60, 50, 80
+			for x := x0 + 1; x < innerR; x++ {
+				e.next[y][x].BgR, e.next[y][x].BgG, e.next[y][x].BgB = rowBgR, rowBgG, rowBgB
+			}
+			e.writeText(y, x0+1, innerR, "▶", 255, 220, 80, rowBgR, rowBgG, rowBgB, true)
+		}
+		e.writeText(y, x0+3, innerR, it.Name, fgR, fgG, fgB, rowBgR, rowBgG, rowBgB, selected)
+		qty := fmt.Sprintf("×%d", it.Qty)
+		if it.Slot != "" {
+			qty = it.Slot + " " + qty
+		}
+		e.writeText(y, innerR-len([]rune(qty))-1, innerR, qty, 180, 180, 195, rowBgR, rowBgG, rowBgB, false)
+	}
+
+	// Description of the selected item
+	if inv.Cursor >= 0 && inv.Cursor < len(inv.Items) {
+		it := inv.Items[inv.Cursor]
+		desc := it.Description
+		if it.Bonus != "" {
+			desc = it.Bonus + "  " + desc
+		}
+		e.writeText(y0+rows+2, x0+2, innerR-1, desc, 190, 180, 160, bgR, bgG, bgB, false)
+	}
+
+	hint := "↑↓ Select  Enter Use/Equip  I Close"
+	if inv.InCombat {
+		hint = "↑↓ Select  Enter Use  1-4 Back"
+	}
+	e.writeText(y0+rows+4, x0+2, innerR, hint, 130, 130, 145, bgR, bgG, bgB, false)
+}
+
+// drawPanelFrame fills a box with the background color and draws its border.
+// dividers are row offsets from the top that get a ├───┤ line.
+func (e *Engine) drawPanelFrame(x0, y0, boxW, boxH int, bR, bG, bB, bgR, bgG, bgB uint8, dividers ...int) {
+	isDivider := func(y int) bool {
+		for _, d := range dividers {
+			if y == y0+d {
+				return true
+			}
+		}
+		return false
+	}
+	for y := y0; y < y0+boxH && y < e.height; y++ {
+		for x := x0; x < x0+boxW && x < e.width; x++ {
+			ch := ' '
+			switch {
+			case y == y0 && x == x0:
+				ch = '┌'
+			case y == y0 && x == x0+boxW-1:
+				ch = '┐'
+			case y == y0+boxH-1 && x == x0:
+				ch = '└'
+			case y == y0+boxH-1 && x == x0+boxW-1:
+				ch = '┘'
+			case isDivider(y) && x == x0:
+				ch = '├'
+			case isDivider(y) && x == x0+boxW-1:
+				ch = '┤'
+			case y == y0 || y == y0+boxH-1 || isDivider(y):
+				ch = '─'
+			case x == x0 || x == x0+boxW-1:
+				ch = '│'
+			}
+			e.next[y][x] = Cell{Ch: ch, FgR: bR, FgG: bG, FgB: bB, BgR: bgR, BgG: bgG, BgB: bgB}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package render
+
+import "fmt"
+
+const (
+	journalPanelW    = 56 // columns including borders
+	journalPanelRows = 6  // quest rows visible before scrolling
+)
+
+// JournalObjective is one objective line in the journal.
+type JournalObjective struct {
+	Text string
+	Done bool
+}
+
+// JournalQuest is one journal entry.
+type JournalQuest struct {
+	Name        string
+	Description string
+	Done        bool
+	Objectives  []JournalObjective
+}
+
+// JournalPanel is the viewer's open quest journal.
+type JournalPanel struct {
+	Quests []JournalQuest
+	Cursor int
+}
+
+// journalLine is one wrapped line of the selected quest's details.
+type journalLine struct {
+	text    string
+	r, g, b uint8
+}
+
+// drawJournal draws the quest list with the selected quest's description
+// and objectives below it, as a centered box.
+func (e *Engine) drawJournal(j *JournalPanel) {
+	if j == nil {
+		return
+	}
+	boxW := min(journalPanelW, e.width)
+	rows := min(max(len(j.Quests), 1), journalPanelRows)
+
+	// Details of the selected quest, cut to what fits on screen
+	var details []journalLine
+	if j.Cursor >= 0 && j.Cursor < len(j.Quests) {
+		q := j.Quests[j.Cursor]
+		for _, line := range wrapRunes(q.Description, boxW-4) {
+			details = append(details, journalLine{line, 190, 180, 160})
+		}
+		for _, o := range q.Objectives {
+			mark, r, g, b := "▸ ", uint8(255), uint8(220), uint8(120)
+			if o.Done {
+				mark, r, g, b = "✔ ", 110, 170, 110
+			}
+			for i, line := range wrapRunes(o.Text, boxW-6) {
+				if i == 0 {
+					line = mark + line
+				} else {
+					line = "  " + line
+				}
+				details = append(details, journalLine{line, r, g, b})
+			}
+		}
+	}
+	if len(details) == 0 {
+		details = []journalLine{{"", 0, 0, 0}}
+	}
+	// top, quests, divider, details, divider, hint, bottom
+	details = details[:max(min(len(details), e.height-HUDRows-rows-5), 1)]
+	boxH := rows + len(details) + 5
+	x0 := (e.width - boxW) / 2
+	y0 := max((e.height-HUDRows-boxH)/2, 0)
+
+	bR, bG, bB := uint8(170), uint8(150), uint8(110)
+	bgR, bgG, bgB := uint8(30), uint8(26), uint8(22)
+	e.drawPanelFrame(x0, y0, boxW, boxH, bR, bG, bB, bgR, bgG, bgB, rows+1, rows+len(details)+2)
+	innerR := x0 + boxW - 1
+
+	e.writeText(y0, x0+2, innerR, " Quest Journal ", 255, 230, 170, bgR, bgG, bgB, true)
+	active := 0
+	for _, q := range j.Quests {
+		if !q.Done {
+			active++
+		}
+	}
+	count := fmt.Sprintf(" %d active ", active)
+	e.writeText(y0, innerR-len(count)-1, innerR, count, 160, 150, 120, bgR, bgG, bgB, false)
+
+	// Quest rows, scrolled so the cursor stays visible
+	if len(j.Quests) == 0 {
+		e.writeText(y0+1, x0+2, innerR, "No quests yet. Talk to people around town.", 120, 120, 135, bgR, bgG, bgB, false)
+	}
+	first := 0
+	if j.Cursor >= rows {
+		first = j.Cursor - rows + 1
+	}
+	for i := 0; i < rows && first+i < len(j.Quests); i++ {
+		idx := first + i
+		q := j.Quests[idx]
+		y := y0 + 1 + i
+		fgR, fgG, fgB := uint8(235), uint8(225), uint8(205)
+		if q.Done {
+			fgR, fgG, fgB = 130, 130, 140
+		}
+		selected := idx == j.Cursor
+		rowBgR, rowBgG, rowBgB := bgR, bgG, bgB
+		if selected && y < e.height {
+			rowBgR, rowBgG, rowBgB = 70, 56, 40
+			for x := x0 + 1; x < innerR; x++ {
+				e.next[y][x].BgR, e.next[y][x].BgG, e.next[y][x].BgB = rowBgR, rowBgG, rowBgB
+			}
+			e.writeText(y, x0+1, innerR, "▶", 255, 220, 80, rowBgR, rowBgG, rowBgB, true)
+		}
+		e.writeText(y, x0+3, innerR, q.Name, fgR, fgG, fgB, rowBgR, rowBgG, rowBgB, selected)
+		if q.Done {
+			e.writeText(y, innerR-len("done")-1, innerR, "done", 110, 170, 110, rowBgR, rowBgG, rowBgB, false)
+		}
+	}
+
+	for i, line := range details {
+		e.writeText(y0+rows+2+i, x0+2, innerR, line.text, line.r, line.g, line.b, bgR, bgG, bgB, false)
+	}
+
+	e.writeText(y0+rows+len(details)+3, x0+2, innerR, "↑↓ Select  J Close", 130, 130, 145, bgR, bgG, bgB, false)
+}
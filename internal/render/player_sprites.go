@@ -4,8 +4,17 @@ package render
 // Uses 2-col-wide "pixels" for a clean block-art look.
 func PlayerSprite(dir, anim, frame, color int, isSelf bool, name string) Sprite {
 	colorIdx := color % len(PlayerBGColors)
-	bgR, bgG, bgB := PlayerBGColors[colorIdx][0], PlayerBGColors[colorIdx][1], PlayerBGColors[colorIdx][2]
+	return characterSprite(dir, PlayerBGColors[colorIdx][0], PlayerBGColors[colorIdx][1], PlayerBGColors[colorIdx][2])
+}
+
+// NPCSprite returns the 10x5 sprite for an NPC wearing the given color.
+func NPCSprite(dir int, color [3]uint8) Sprite {
+	return characterSprite(dir, color[0], color[1], color[2])
+}
 
+// characterSprite draws the shared character body facing dir with a shirt
+// of the given color.
+func characterSprite(dir int, bgR, bgG, bgB uint8) Sprite {
 	switch dir {
 	case 1:
 		return playerUp(bgR, bgG, bgB)
@@ -0,0 +1,150 @@
+package render
+
+// Chat kinds mirroring game.ChatChannel values.
+const (
+	chatKindMap     = 0
+	chatKindGlobal  = 1
+	chatKindWhisper = 2
+	chatKindParty   = 3
+	chatKindSystem  = 4
+)
+
+const (
+	chatPanelMaxW      = 64 // columns
+	chatPanelLines     = 5  // fresh lines shown while playing
+	chatPanelOpenLines = 10 // scrollback shown while typing
+)
+
+// ChatLine is one formatted chat message for the scrollback panel.
+type ChatLine struct {
+	Kind  int // maps to game.ChatChannel
+	Text  string
+	Fresh bool // recent enough to show while the prompt is closed
+}
+
+// SessionUI is per-connection interface state that lives outside the game
+// loop: the prompt, chat scrollback, command feedback and announcements.
+type SessionUI struct {
+	Banner       string // server-wide announcement across the top row
+	PromptActive bool
+	PromptLabel  string     // e.g. "Say: " or "> "
+	Prompt       string     // text typed so far
+	Chat         []ChatLine // oldest first
+	Notices      []string   // session-local command output, most recent last
+}
+
+// drawSessionUI draws the chat panel, notices and prompt just above the HUD,
+// and the banner across the top row.
+func (e *Engine) drawSessionUI(ui *SessionUI) {
+	if ui == nil {
+		return
+	}
+	if ui.Banner != "" {
+		e.fillRow(0, 150, 95, 20)
+		e.drawCenteredText(0, ui.Banner, 255, 245, 220, 150, 95, 20, true)
+	}
+
+	bgR, bgG, bgB := uint8(20), uint8(20), uint8(32)
+	panelW := min(e.width, chatPanelMaxW)
+	row := e.height - HUDRows - 1
+
+	if ui.PromptActive {
+		e.fillRow(row, bgR, bgG, bgB)
+		col := e.writeText(row, 1, e.width, ui.PromptLabel, 120, 200, 255, bgR, bgG, bgB, true)
+		// Scroll long input so the cursor stays visible
+		text := []rune(ui.Prompt)
+		if room := e.width - col - 2; room > 0 && len(text) > room {
+			text = text[len(text)-room:]
+		}
+		col = e.writeText(row, col, e.width, string(text), 235, 235, 240, bgR, bgG, bgB, false)
+		e.writeText(row, col, e.width, "█", 120, 200, 255, bgR, bgG, bgB, false)
+		row--
+	}
+
+	// Collect visible lines: chat scrollback, then session notices
+	type panelLine struct {
+		kind int
+		text string
+	}
+	maxLines := chatPanelLines
+	if ui.PromptActive {
+		maxLines = chatPanelOpenLines
+	}
+	var lines []panelLine
+	for _, cl := range ui.Chat {
+		if !cl.Fresh && !ui.PromptActive {
+			continue
+		}
+		for _, wrapped := range wrapRunes(cl.Text, panelW-2) {
+			lines = append(lines, panelLine{cl.Kind, wrapped})
+		}
+	}
+	for _, n := range ui.Notices {
+		for _, wrapped := range wrapRunes(n, panelW-2) {
+			lines = append(lines, panelLine{chatKindSystem, wrapped})
+		}
+	}
+	if len(lines) > maxLines {
+		lines = lines[len(lines)-maxLines:]
+	}
+
+	for i := len(lines) - 1; i >= 0 && row >= 0; i-- {
+		for x := 0; x < panelW; x++ {
+			e.next[row][x] = Cell{Ch: ' ', BgR: bgR, BgG: bgG, BgB: bgB}
+		}
+		fgR, fgG, fgB := chatColor(lines[i].kind)
+		e.writeText(row, 1, panelW, lines[i].text, fgR, fgG, fgB, bgR, bgG, bgB, false)
+		row--
+	}
+}
+
+// chatColor returns the text color for a chat kind.
+func chatColor(kind int) (uint8, uint8, uint8) {
+	switch kind {
+	case chatKindGlobal:
+		return 130, 190, 255
+	case chatKindWhisper:
+		return 230, 140, 230
+	case chatKindParty:
+		return 255, 180, 90
+	case chatKindSystem:
+		return 160, 160, 175
+	default:
+		return 230, 230, 235
+	}
+}
+
+// wrapRunes splits text into lines of at most width runes, breaking at
+// spaces where possible.
+func wrapRunes(text string, width int) []string {
+	if width < 1 {
+		return nil
+	}
+	runes := []rune(text)
+	var out []string
+	for len(runes) > width {
+		cut := width
+		for i := width; i > width/2; i-- {
+			if runes[i] == ' ' {
+				cut = i
+				break
+			}
+		}
+		out = append(out, string(runes[:cut]))
+		runes = runes[cut:]
+		for len(runes) > 0 && runes[0] == ' ' {
+			runes = runes[1:]
+		}
+	}
+	return append(out, string(runes))
+}
+
+// fillRow paints an entire row with a blank background.
+func (e *Engine) fillRow(row int, bgR, bgG, bgB uint8) {
+	if row < 0 || row >= e.height {
+		return
+	}
+	for x := 0; x < e.width; x++ {
+		e.next[row][x] = Cell{Ch: ' ', BgR: bgR, BgG: bgG, BgB: bgB}
+	}
+}
@@ -23,6 +23,30 @@ type AnimatedSprite struct {
 	TickRate int // game ticks per frame advance
 }
 
+// TileAnim holds the game ticks between frames of each animated tile.
+type TileAnim struct {
+	Grass          int
+	TallGrass      int
+	Sand           int
+	Water          int
+	WaterShimmer   int
+	Shallow        int
+	ShallowShimmer int
+}
+
+// AnimClock is the game tick that drives tile animation, with the intervals
+// it advances at.
+type AnimClock struct {
+	Tick uint64
+	TileAnim
+}
+
+// frame returns which of n frames an animation stepping every interval
+// ticks is showing.
+func (c AnimClock) frame(interval, n int) int {
+	return int(c.Tick/uint64(max(interval, 1))) % n
+}
+
 // Overlay is a sprite rendered at a vertical offset above its owning tile.
 type Overlay struct {
 	Sprite Sprite
@@ -2,16 +2,17 @@ package render
 
 import "happy-place-2/internal/maps"
 
-// tileFunc generates sprites for a tile at world position (wx,wy) at the given tick.
-type tileFunc func(wx, wy int, tick uint64, m *maps.Map) TileSprites
+// tileFunc generates sprites for a tile at world position (wx,wy) at the given clock.
+type tileFunc func(wx, wy int, clk AnimClock, m *maps.Map) TileSprites
 
 // tileEntry holds a named tile's sprite generator and its variant count.
 type tileEntry struct {
 	name      string
 	fn        tileFunc
 	variants  int // number of distinct variants (1 = no variation)
+	animated  bool // sprites change with the clock, so can't be cached
 	connected bool
-	connFn    func(mask uint8, v uint, tick uint64) Sprite
+	connFn    func(mask uint8, v uint, clk AnimClock) Sprite
 }
 
 // TileHash maps world coordinates to a deterministic pseudo-random value.
@@ -35,12 +36,12 @@ func variantCoord(v, variants int) (int, int) {
 }
 
 // variantTile builds a tileEntry for tiles whose appearance depends only
-// on a variant index and the tick (the common case).
-func variantTile(name string, n int, fn func(v uint, tick uint64) Sprite) tileEntry {
+// on a variant index and the clock (the common case).
+func variantTile(name string, n int, fn func(v uint, clk AnimClock) Sprite) tileEntry {
 	return tileEntry{
 		name: name,
-		fn: func(wx, wy int, tick uint64, m *maps.Map) TileSprites {
-			return TileSprites{Base: fn(TileHash(wx, wy)%uint(n), tick)}
+		fn: func(wx, wy int, clk AnimClock, m *maps.Map) TileSprites {
+			return TileSprites{Base: fn(TileHash(wx, wy)%uint(n), clk)}
 		},
 		variants: n,
 	}
@@ -48,11 +49,11 @@ func variantTile(name string, n int, fn func(v uint, tick uint64) Sprite) tileEn
 
 // posVariantTile builds a tileEntry for tiles that also need world position
 // beyond variant selection (e.g., wall mortar line offsets).
-func posVariantTile(name string, n int, fn func(wx, wy int, v uint, tick uint64) Sprite) tileEntry {
+func posVariantTile(name string, n int, fn func(wx, wy int, v uint, clk AnimClock) Sprite) tileEntry {
 	return tileEntry{
 		name: name,
-		fn: func(wx, wy int, tick uint64, m *maps.Map) TileSprites {
-			return TileSprites{Base: fn(wx, wy, TileHash(wx, wy)%uint(n), tick)}
+		fn: func(wx, wy int, clk AnimClock, m *maps.Map) TileSprites {
+			return TileSprites{Base: fn(wx, wy, TileHash(wx, wy)%uint(n), clk)}
 		},
 		variants: n,
 	}
@@ -88,12 +89,12 @@ func neighborMask(name string, wx, wy int, m *maps.Map) uint8 {
 }
 
 // connectedTile builds a tileEntry for tiles that adapt based on same-name neighbors.
-func connectedTile(name string, n int, fn func(mask uint8, v uint, tick uint64) Sprite) tileEntry {
+func connectedTile(name string, n int, fn func(mask uint8, v uint, clk AnimClock) Sprite) tileEntry {
 	return tileEntry{
 		name: name,
-		fn: func(wx, wy int, tick uint64, m *maps.Map) TileSprites {
+		fn: func(wx, wy int, clk AnimClock, m *maps.Map) TileSprites {
 			mask := neighborMask(name, wx, wy, m)
-			return TileSprites{Base: fn(mask, TileHash(wx, wy)%uint(n), tick)}
+			return TileSprites{Base: fn(mask, TileHash(wx, wy)%uint(n), clk)}
 		},
 		variants:  n,
 		connected: true,
@@ -102,35 +103,44 @@ func connectedTile(name string, n int, fn func(mask uint8, v uint, tick uint64)
 }
 
 // tallVariantTile builds a tileEntry for tiles that return TileSprites directly
-// (base + overlays), keyed by variant index and tick.
-func tallVariantTile(name string, n int, fn func(v uint, tick uint64) TileSprites) tileEntry {
+// (base + overlays), keyed by variant index and clock.
+func tallVariantTile(name string, n int, fn func(v uint, clk AnimClock) TileSprites) tileEntry {
 	return tileEntry{
 		name: name,
-		fn: func(wx, wy int, tick uint64, m *maps.Map) TileSprites {
-			return fn(TileHash(wx, wy)%uint(n), tick)
+		fn: func(wx, wy int, clk AnimClock, m *maps.Map) TileSprites {
+			return fn(TileHash(wx, wy)%uint(n), clk)
 		},
 		variants: n,
 	}
 }
 
+// animated marks a tile whose sprites change with the clock.
+func animated(e tileEntry) tileEntry {
+	e.animated = true
+	return e
+}
+
 // tileList is the single source of truth for all tile types.
 // Order here determines debug view order. Names must be unique.
 var tileList = []tileEntry{
-	variantTile("grass", 4, func(v uint, tick uint64) Sprite { return grassSprite(v, tick) }),
-	posVariantTile("wall", 4, func(wx, wy int, v uint, _ uint64) Sprite { return wallSprite(wx, wy, v) }),
-	posVariantTile("water", 1, func(wx, wy int, _ uint, tick uint64) Sprite { return waterSprite(wx, wy, tick) }),
-	tallVariantTile("tree", 4, func(v uint, tick uint64) TileSprites { return tallTreeSprite(v, tick) }),
-	variantTile("path", 4, func(v uint, _ uint64) Sprite { return pathSprite(v) }),
-	variantTile("door", 1, func(_ uint, _ uint64) Sprite { return doorSprite() }),
-	variantTile("floor", 4, func(v uint, _ uint64) Sprite { return floorSprite(v) }),
-	connectedTile("fence", 2, func(mask uint8, v uint, tick uint64) Sprite { return fenceSprite(mask, v, tick) }),
-	variantTile("flowers", 6, func(v uint, _ uint64) Sprite { return flowerSprite(v) }),
-	variantTile("sand", 4, func(v uint, tick uint64) Sprite { return sandSprite(v, tick) }),
-	variantTile("tall_grass", 4, func(v uint, tick uint64) Sprite { return tallGrassSprite(v, tick) }),
-	posVariantTile("rock", 4, func(wx, wy int, v uint, _ uint64) Sprite { return rockSprite(wx, wy, v) }),
-	posVariantTile("shallow_water", 1, func(wx, wy int, _ uint, tick uint64) Sprite { return shallowWaterSprite(wx, wy, tick) }),
-	variantTile("dirt", 4, func(v uint, _ uint64) Sprite { return dirtSprite(v) }),
-	variantTile("bridge", 2, func(v uint, _ uint64) Sprite { return bridgeSprite(v) }),
+	animated(variantTile("grass", 4, func(v uint, clk AnimClock) Sprite { return grassSprite(v, clk) })),
+	posVariantTile("wall", 4, func(wx, wy int, v uint, _ AnimClock) Sprite { return wallSprite(wx, wy, v) }),
+	animated(posVariantTile("water", 1, func(wx, wy int, _ uint, clk AnimClock) Sprite { return waterSprite(wx, wy, clk) })),
+	animated(tallVariantTile("tree", 4, func(v uint, clk AnimClock) TileSprites { return tallTreeSprite(v, clk) })),
+	variantTile("path", 4, func(v uint, _ AnimClock) Sprite { return pathSprite(v) }),
+	variantTile("door", 1, func(_ uint, _ AnimClock) Sprite { return doorSprite() }),
+	variantTile("door_open", 1, func(_ uint, _ AnimClock) Sprite { return doorOpenSprite() }),
+	variantTile("lever", 1, func(_ uint, _ AnimClock) Sprite { return leverSprite(false) }),
+	variantTile("lever_pulled", 1, func(_ uint, _ AnimClock) Sprite { return leverSprite(true) }),
+	variantTile("floor", 4, func(v uint, _ AnimClock) Sprite { return floorSprite(v) }),
+	animated(connectedTile("fence", 2, func(mask uint8, v uint, clk AnimClock) Sprite { return fenceSprite(mask, v, clk) })),
+	variantTile("flowers", 6, func(v uint, _ AnimClock) Sprite { return flowerSprite(v) }),
+	animated(variantTile("sand", 4, func(v uint, clk AnimClock) Sprite { return sandSprite(v, clk) })),
+	animated(variantTile("tall_grass", 4, func(v uint, clk AnimClock) Sprite { return tallGrassSprite(v, clk) })),
+	posVariantTile("rock", 4, func(wx, wy int, v uint, _ AnimClock) Sprite { return rockSprite(wx, wy, v) }),
+	animated(posVariantTile("shallow_water", 1, func(wx, wy int, _ uint, clk AnimClock) Sprite { return shallowWaterSprite(wx, wy, clk) })),
+	variantTile("dirt", 4, func(v uint, _ AnimClock) Sprite { return dirtSprite(v) }),
+	variantTile("bridge", 2, func(v uint, _ AnimClock) Sprite { return bridgeSprite(v) }),
 }
 
 // tileIndex maps tile names to entries for O(1) lookup. Built in init().
@@ -147,17 +157,24 @@ func init() {
 	}
 }
 
-// TileSprite returns the sprites for a tile at world position (wx,wy) at the given tick.
-func TileSprite(tile maps.TileDef, wx, wy int, tick uint64, m *maps.Map) TileSprites {
+// TileSprite returns the sprites for a tile at world position (wx,wy) at the given clock.
+func TileSprite(tile maps.TileDef, wx, wy int, clk AnimClock, m *maps.Map) TileSprites {
+	ts, _ := tileSprite(tile, wx, wy, clk, m)
+	return ts
+}
+
+// tileSprite is TileSprite that also reports whether the sprites stay the
+// same at every clock, and so may be cached until the tile changes.
+func tileSprite(tile maps.TileDef, wx, wy int, clk AnimClock, m *maps.Map) (TileSprites, bool) {
 	if e, ok := tileIndex[tile.Name]; ok {
-		return e.fn(wx, wy, tick, m)
+		return e.fn(wx, wy, clk, m), !e.animated
 	}
-	return TileSprites{Base: fallbackSprite(tile)}
+	return TileSprites{Base: fallbackSprite(tile)}, true
 }
 
 // --- Grass ---
 
-func grassSprite(v uint, tick uint64) Sprite {
+func grassSprite(v uint, clk AnimClock) Sprite {
 	bgR, bgG, bgB := uint8(28), uint8(65), uint8(28)
 	bgG += uint8(v * 3)
 
@@ -182,7 +199,7 @@ func grassSprite(v uint, tick uint64) Sprite {
 		{{3, 0}, {7, 2}, {1, 4}, {5, 1}, {9, 3}},
 	}
 
-	frame := int(tick/uint64(max(8, 1))) % 2
+	frame := clk.frame(clk.Grass, 2)
 
 	for i, p := range patterns[v] {
 		b := blades[i%len(blades)]
@@ -232,11 +249,11 @@ func wallSprite(wx, wy int, v uint) Sprite {
 
 // --- Water ---
 
-func waterSprite(wx, wy int, tick uint64) Sprite {
+func waterSprite(wx, wy int, clk AnimClock) Sprite {
 	bgR, bgG, bgB := uint8(15), uint8(38), uint8(95)
 	fgR, fgG, fgB := uint8(70), uint8(130), uint8(210)
 
-	frame := int(tick/uint64(max(8, 1))) % 4
+	frame := clk.frame(clk.Water, 4)
 
 	s := FillSprite(' ', fgR, fgG, fgB, bgR, bgG, bgB)
 
@@ -248,7 +265,7 @@ func waterSprite(wx, wy int, tick uint64) Sprite {
 			charIdx := (x + rowPhase + frame*3) % len(waveChars)
 			ch := waveChars[charIdx]
 
-			shimmer := uint8(((x + y*2 + int(tick/6)) % 3) * 12)
+			shimmer := uint8(((x + y*2 + clk.frame(clk.WaterShimmer, 3)) % 3) * 12)
 			cellFgB := fgB + shimmer
 			if cellFgB < fgB {
 				cellFgB = 255
@@ -274,8 +291,8 @@ func waterSprite(wx, wy int, tick uint64) Sprite {
 
 // dimmedGrass returns a grass sprite with all colors scaled by pct/100.
 // pct=100 is full brightness (no change), pct=85 is 15% darker, etc.
-func dimmedGrass(v uint, tick uint64, pct uint16) Sprite {
-	s := grassSprite(v, tick)
+func dimmedGrass(v uint, clk AnimClock, pct uint16) Sprite {
+	s := grassSprite(v, clk)
 	if pct >= 100 {
 		return s
 	}
@@ -297,7 +314,7 @@ func dimmedGrass(v uint, tick uint64, pct uint16) Sprite {
 
 // tallTreeSprite returns a TileSprites with a trunk base and two canopy overlays
 // at DY=1 (lower canopy) and DY=2 (upper canopy), making the tree 3 tiles tall.
-func tallTreeSprite(v uint, tick uint64) TileSprites {
+func tallTreeSprite(v uint, clk AnimClock) TileSprites {
 	// Match grass/flower base green: (28, 65, 28)
 	grassR, grassG, grassB := uint8(28), uint8(65), uint8(28)
 	// Canopy leaf bg matches grass
@@ -311,7 +328,7 @@ func tallTreeSprite(v uint, tick uint64) TileSprites {
 	T := TransparentCell
 
 	// --- Base: grass sprite with a subtle shadow tint, trunk on top ---
-	base := dimmedGrass(v, tick, 85)
+	base := dimmedGrass(v, clk, 85)
 
 	dim := func(c uint8) uint8 { return uint8(uint16(c) * 85 / 100) }
 	sbgR, sbgG, sbgB := dim(grassR), dim(grassG+uint8(v*3)), dim(grassB)
@@ -454,6 +471,74 @@ func doorSprite() Sprite {
 	return s
 }
 
+// doorOpenSprite is the door swung inward against the left pillar, with
+// the floor showing through the doorway.
+func doorOpenSprite() Sprite {
+	bgR, bgG, bgB := uint8(40), uint8(28), uint8(16)
+	frameR, frameG, frameB := uint8(80), uint8(55), uint8(20)
+	plankR, plankG, plankB := uint8(140), uint8(100), uint8(40)
+
+	s := FillSprite(' ', 0, 0, 0, bgR, bgG, bgB)
+
+	// Header beam (row 0)
+	for x := 0; x < TileWidth; x++ {
+		s[0][x] = SCBold('▀', frameR+30, frameG+20, frameB+10, frameR, frameG, frameB)
+	}
+
+	// Frame pillars (cols 0 and 9)
+	for y := 1; y < TileHeight; y++ {
+		s[y][0] = SC('║', frameR+20, frameG+15, frameB+5, frameR, frameG, frameB)
+		s[y][9] = SC('║', frameR+20, frameG+15, frameB+5, frameR, frameG, frameB)
+	}
+
+	// The door leaf seen edge-on (cols 1-2)
+	for y := 1; y < TileHeight; y++ {
+		s[y][1] = SC('▌', plankR, plankG, plankB, bgR, bgG, bgB)
+		s[y][2] = SC('│', plankR-30, plankG-25, plankB-15, bgR, bgG, bgB)
+	}
+
+	// Floorboards through the doorway
+	for x := 3; x < 9; x++ {
+		s[TileHeight-1][x] = SC('─', bgR+40, bgG+30, bgB+18, bgR, bgG, bgB)
+	}
+
+	return s
+}
+
+// --- Lever ---
+
+// leverSprite is a lever on a stone block, thrown to the right once pulled.
+func leverSprite(pulled bool) Sprite {
+	bgR, bgG, bgB := uint8(80), uint8(80), uint8(85)
+	stoneR, stoneG, stoneB := uint8(110), uint8(110), uint8(115)
+	rodR, rodG, rodB := uint8(150), uint8(110), uint8(60)
+	knobR, knobG, knobB := uint8(200), uint8(50), uint8(40)
+
+	s := FillSprite('░', bgR+15, bgG+15, bgB+10, bgR, bgG, bgB)
+
+	// Stone block (rows 3-4, cols 2-7)
+	for y := 3; y < TileHeight; y++ {
+		for x := 2; x < 8; x++ {
+			s[y][x] = SC('▓', stoneR, stoneG, stoneB, bgR, bgG, bgB)
+		}
+	}
+	s[3][4] = SCBold('▄', rodR, rodG, rodB, stoneR, stoneG, stoneB)
+	s[3][5] = SCBold('▄', rodR, rodG, rodB, stoneR, stoneG, stoneB)
+
+	// Rod and knob, leaning left or right
+	if pulled {
+		s[2][5] = SCBold('╱', rodR, rodG, rodB, bgR, bgG, bgB)
+		s[1][6] = SCBold('╱', rodR, rodG, rodB, bgR, bgG, bgB)
+		s[0][7] = SCBold('●', knobR, knobG, knobB, bgR, bgG, bgB)
+	} else {
+		s[2][4] = SCBold('╲', rodR, rodG, rodB, bgR, bgG, bgB)
+		s[1][3] = SCBold('╲', rodR, rodG, rodB, bgR, bgG, bgB)
+		s[0][2] = SCBold('●', knobR, knobG, knobB, bgR, bgG, bgB)
+	}
+
+	return s
+}
+
 // --- Floor ---
 
 func floorSprite(v uint) Sprite {
@@ -485,11 +570,11 @@ func floorSprite(v uint) Sprite {
 
 // --- Fence ---
 
-func fenceSprite(mask uint8, v uint, tick uint64) Sprite {
+func fenceSprite(mask uint8, v uint, clk AnimClock) Sprite {
 	fgR, fgG, fgB := uint8(155), uint8(115), uint8(55)
 	railR, railG, railB := fgR-10, fgG-10, fgB-5
 
-	s := dimmedGrass(v, tick, 95)
+	s := dimmedGrass(v, clk, 95)
 
 	// Read the bg color from the center cell for fence element backgrounds
 	bgR, bgG, bgB := s[2][5].Cell.BgR, s[2][5].Cell.BgG, s[2][5].Cell.BgB
@@ -587,7 +672,7 @@ func flowerSprite(v uint) Sprite {
 
 // --- Sand ---
 
-func sandSprite(v uint, tick uint64) Sprite {
+func sandSprite(v uint, clk AnimClock) Sprite {
 	bgR, bgG, bgB := uint8(194), uint8(178), uint8(128)
 	bgG += uint8(v * 2)
 	bgB -= uint8(v * 3)
@@ -613,7 +698,7 @@ func sandSprite(v uint, tick uint64) Sprite {
 		{{0, 2}, {5, 4}, {8, 0}, {3, 3}},
 	}
 
-	frame := int(tick/10) % 2
+	frame := clk.frame(clk.Sand, 2)
 
 	for i, p := range patterns[v] {
 		g := grains[i%len(grains)]
@@ -626,7 +711,7 @@ func sandSprite(v uint, tick uint64) Sprite {
 
 // --- Tall Grass ---
 
-func tallGrassSprite(v uint, tick uint64) Sprite {
+func tallGrassSprite(v uint, clk AnimClock) Sprite {
 	bgR, bgG, bgB := uint8(24), uint8(58), uint8(24)
 	bgG += uint8(v * 2)
 
@@ -651,7 +736,7 @@ func tallGrassSprite(v uint, tick uint64) Sprite {
 		{{2, 0}, {4, 1}, {6, 0}, {9, 2}, {1, 3}, {3, 4}, {7, 0}},
 	}
 
-	frame := int(tick/7) % 3
+	frame := clk.frame(clk.TallGrass, 3)
 
 	for i, p := range patterns[v] {
 		b := blades[i%len(blades)]
@@ -700,11 +785,11 @@ func rockSprite(wx, wy int, v uint) Sprite {
 
 // --- Shallow Water ---
 
-func shallowWaterSprite(wx, wy int, tick uint64) Sprite {
+func shallowWaterSprite(wx, wy int, clk AnimClock) Sprite {
 	bgR, bgG, bgB := uint8(25), uint8(60), uint8(120)
 	fgR, fgG, fgB := uint8(80), uint8(170), uint8(210)
 
-	frame := int(tick/10) % 4
+	frame := clk.frame(clk.Shallow, 4)
 
 	s := FillSprite(' ', fgR, fgG, fgB, bgR, bgG, bgB)
 
@@ -716,7 +801,7 @@ func shallowWaterSprite(wx, wy int, tick uint64) Sprite {
 			charIdx := (x + rowPhase + frame*2) % len(waveChars)
 			ch := waveChars[charIdx]
 
-			shimmer := uint8(((x + y*2 + int(tick/8)) % 3) * 8)
+			shimmer := uint8(((x + y*2 + clk.frame(clk.ShallowShimmer, 3)) % 3) * 8)
 			cellFgB := fgB + shimmer
 			if cellFgB < fgB {
 				cellFgB = 255
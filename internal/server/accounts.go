@@ -0,0 +1,151 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gliderlabs/ssh"
+	gossh "golang.org/x/crypto/ssh"
+
+	"happy-place-2/internal/store"
+)
+
+var (
+	errKeyNotRegistered = errors.New("key is not registered for this name")
+	errLastKey          = errors.New("cannot revoke the only key on an account")
+)
+
+// accountManager binds character names to SSH public keys. The first key to
+// log in under a name claims it; later logins must present a registered key.
+// Names are case-insensitive, so claiming "alice" claims "Alice" too.
+type accountManager struct {
+	mu    sync.Mutex // serializes claim and key edits
+	store store.Store
+}
+
+func newAccountManager(st store.Store) *accountManager {
+	return &accountManager{store: st}
+}
+
+// fingerprint returns the SHA256 fingerprint ssh-keygen -l would print.
+func fingerprint(key ssh.PublicKey) string {
+	return gossh.FingerprintSHA256(key)
+}
+
+// allows reports whether key may authenticate as name. Unclaimed names accept
+// any key; the claim itself happens in login once the session starts.
+func (am *accountManager) allows(name string, key ssh.PublicKey) bool {
+	acc, err := am.store.LoadAccount(name)
+	if errors.Is(err, store.ErrNotFound) {
+		return true
+	}
+	if err != nil {
+		return false
+	}
+	return acc.HasKey(fingerprint(key))
+}
+
+// hasKey reports whether the fingerprint is registered on the account.
+func (am *accountManager) hasKey(name, fp string) bool {
+	acc, err := am.store.LoadAccount(name)
+	return err == nil && acc.HasKey(fp)
+}
+
+// login claims name for key if it is unclaimed, or verifies key against the
+// registered set. It returns the account's name as first claimed, whatever
+// case the login used, and reports whether this login created the account.
+func (am *accountManager) login(name string, key ssh.PublicKey) (string, bool, error) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	fp := fingerprint(key)
+	acc, err := am.store.LoadAccount(name)
+	if errors.Is(err, store.ErrNotFound) {
+		now := time.Now()
+		acc = &store.Account{
+			Name:      name,
+			CreatedAt: now,
+			Keys:      []store.AccountKey{newAccountKey(key, now)},
+		}
+		if err := am.store.SaveAccount(acc); err != nil {
+			return "", false, err
+		}
+		return acc.Name, true, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if !acc.HasKey(fp) {
+		return "", false, errKeyNotRegistered
+	}
+	return acc.Name, false, nil
+}
+
+// keys returns the keys registered on the account.
+func (am *accountManager) keys(name string) ([]store.AccountKey, error) {
+	acc, err := am.store.LoadAccount(name)
+	if err != nil {
+		return nil, err
+	}
+	return acc.Keys, nil
+}
+
+// addKey parses an authorized_keys line and registers it on the account.
+func (am *accountManager) addKey(name, line string) (store.AccountKey, error) {
+	key, _, _, _, err := gossh.ParseAuthorizedKey([]byte(line))
+	if err != nil {
+		return store.AccountKey{}, fmt.Errorf("not a valid public key: %w", err)
+	}
+
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	acc, err := am.store.LoadAccount(name)
+	if err != nil {
+		return store.AccountKey{}, err
+	}
+	if acc.HasKey(fingerprint(key)) {
+		return store.AccountKey{}, fmt.Errorf("key %s is already registered", fingerprint(key))
+	}
+	ak := newAccountKey(key, time.Now())
+	acc.Keys = append(acc.Keys, ak)
+	if err := am.store.SaveAccount(acc); err != nil {
+		return store.AccountKey{}, err
+	}
+	return ak, nil
+}
+
+// revokeKey removes the n-th (1-based) key from the account. The last
+// remaining key cannot be revoked, so an account is never locked out.
+func (am *accountManager) revokeKey(name string, n int) (store.AccountKey, error) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	acc, err := am.store.LoadAccount(name)
+	if err != nil {
+		return store.AccountKey{}, err
+	}
+	if n < 1 || n > len(acc.Keys) {
+		return store.AccountKey{}, fmt.Errorf("no key #%d (account has %d)", n, len(acc.Keys))
+	}
+	if len(acc.Keys) == 1 {
+		return store.AccountKey{}, errLastKey
+	}
+	removed := acc.Keys[n-1]
+	acc.Keys = append(acc.Keys[:n-1], acc.Keys[n:]...)
+	if err := am.store.SaveAccount(acc); err != nil {
+		return store.AccountKey{}, err
+	}
+	return removed, nil
+}
+
+func newAccountKey(key ssh.PublicKey, now time.Time) store.AccountKey {
+	return store.AccountKey{
+		Fingerprint: fingerprint(key),
+		PublicKey:   strings.TrimSpace(string(gossh.MarshalAuthorizedKey(key))),
+		AddedAt:     now,
+	}
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"crypto/ed25519"
+	"net/netip"
+	"testing"
+
+	"github.com/gliderlabs/ssh"
+	gossh "golang.org/x/crypto/ssh"
+
+	"happy-place-2/internal/store"
+)
+
+var testIP = netip.MustParseAddr("192.0.2.1")
+
+// newTestServer builds a server with an in-memory store and no game loop,
+// enough for logins, admission and admin checks.
+func newTestServer(t *testing.T, opts Options) *SSHServer {
+	t.Helper()
+	s, err := NewSSHServer("", "", nil, store.NewMemoryStore(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// newKey returns a fresh public key.
+func newKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := gossh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestCaseVariantLoginWithAnotherKey(t *testing.T) {
+	s := newTestServer(t, Options{})
+	owner, stranger := newKey(t), newKey(t)
+	alice, err := s.identify("alice", owner, testIP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.accounts.allows("Alice", stranger) {
+		t.Fatal("a different key may authenticate as Alice while alice is claimed")
+	}
+	if _, err := s.identify("Alice", stranger, testIP); err == nil {
+		t.Fatal("a different key logged in as Alice")
+	}
+
+	// The owner's key works in any case and plays the same account
+	c, err := s.identify("ALICE", owner, testIP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.name != "alice" {
+		t.Fatalf("logged in as %q, want the account's own name alice", c.name)
+	}
+
+	// A session that somehow isn't on the same account never gets the player
+	alice.join("alice")
+	impostor := &client{name: "Alice", fingerprint: fingerprint(stranger), kick: make(chan string, 1), done: make(chan struct{})}
+	if _, _, ok := s.takeOver(impostor, []*client{alice}); ok {
+		t.Fatal("an impostor took over alice's player")
+	}
+	if !alice.leave() {
+		t.Fatal("alice's player was handed off to an impostor")
+	}
+}
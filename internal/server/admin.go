@@ -0,0 +1,246 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// isAdmin reports whether a logged-in session holds the admin role, either
+// through its account name or the key it connected with. A name only counts
+// if the session's key is registered on that account.
+func (s *SSHServer) isAdmin(c *client) bool {
+	if c.guest || c.fingerprint == "" {
+		return false
+	}
+	for _, a := range s.opts.Admins {
+		if strings.HasPrefix(a, "SHA256:") {
+			if a == c.fingerprint {
+				return true
+			}
+		} else if strings.EqualFold(a, c.name) && s.accounts.hasKey(c.name, c.fingerprint) {
+			return true
+		}
+	}
+	return false
+}
+
+// adminHelp lists the admin commands for /help.
+var adminHelp = []string{
+	"Admin: /tp <name> <map> <x> <y> | /tp <name> <other>",
+	"/kick <name> [why]  /ban <name|key|ip/cidr> [why]",
+	"/unban <name|key|ip/cidr>  /bans",
+	"/give <name> <item> [qty]  /exp <name> <amount>",
+	"/spawn <name> [enemy…]  /inspect <name>  /fights",
+	"/broadcast <msg>  /reload",
+}
+
+// runAdminCommand handles the admin console. It reports false for commands
+// it doesn't know so the caller can answer as for any unknown command.
+func (s *SSHServer) runAdminCommand(c *client, fields []string, line string) bool {
+	cmd, args := strings.ToLower(fields[0]), fields[1:]
+	switch cmd {
+	case "tp", "kick", "ban", "unban", "bans", "give", "exp", "spawn", "inspect", "fights", "broadcast", "reload":
+	default:
+		return false
+	}
+	log.Printf("Admin %s: %s", c.name, line)
+
+	switch cmd {
+	case "tp":
+		s.adminTeleport(c, args)
+	case "kick":
+		if len(args) == 0 {
+			c.notify("Usage: /kick <name> [reason]")
+			return true
+		}
+		reason := strings.Join(args[1:], " ")
+		kicked := s.kickWhere(func(o *client) bool {
+			return strings.EqualFold(o.name, args[0]) || strings.EqualFold(o.character, args[0])
+		}, "You were kicked"+withReason(reason))
+		if kicked == 0 {
+			c.notify(fmt.Sprintf("No player named %q is online", args[0]))
+			return true
+		}
+		c.notify("Kicked " + args[0])
+	case "ban":
+		if len(args) == 0 {
+			c.notify("Usage: /ban <name | SHA256:key | ip | cidr> [reason]")
+			return true
+		}
+		if b, err := parseBanTarget(args[0]); err == nil && banMatches(b, c.name, c.fingerprint, c.ip) {
+			c.notify("That ban would cover you.")
+			return true
+		}
+		b, err := s.bans.add(args[0], strings.Join(args[1:], " "), c.name)
+		if err != nil {
+			c.notify("Ban failed: " + err.Error())
+			return true
+		}
+		kicked := s.kickWhere(func(o *client) bool {
+			return banMatches(b, o.name, o.fingerprint, o.ip)
+		}, "You have been banned"+withReason(b.Reason))
+		c.notify(fmt.Sprintf("Banned %s %s (%d session(s) closed)", b.Kind, b.Value, kicked))
+	case "unban":
+		if len(args) != 1 {
+			c.notify("Usage: /unban <name | SHA256:key | ip | cidr>")
+			return true
+		}
+		ok, err := s.bans.remove(args[0])
+		switch {
+		case err != nil:
+			c.notify("Unban failed: " + err.Error())
+		case !ok:
+			c.notify(args[0] + " is not banned")
+		default:
+			c.notify("Unbanned " + args[0])
+		}
+	case "bans":
+		bans := s.bans.list()
+		if len(bans) == 0 {
+			c.notify("No bans")
+			return true
+		}
+		for _, b := range bans {
+			c.notify(fmt.Sprintf("%s %s by %s on %s%s", b.Kind, b.Value, b.By, b.At.Format("2006-01-02"), withReason(b.Reason)))
+		}
+	case "give":
+		if len(args) < 2 || len(args) > 3 {
+			c.notify("Usage: /give <name> <item id> [qty]")
+			return true
+		}
+		qty := 1
+		if len(args) == 3 {
+			n, err := strconv.Atoi(args[2])
+			if err != nil || n < 1 {
+				c.notify("Usage: /give <name> <item id> [qty]")
+				return true
+			}
+			qty = n
+		}
+		got, err := s.gameLoop.GiveItem(args[0], args[1], qty)
+		switch {
+		case err != nil:
+			c.notify("Give failed: " + err.Error())
+		case got < qty:
+			c.notify(fmt.Sprintf("Gave %d× %s to %s (bag full, %d didn't fit)", got, args[1], args[0], qty-got))
+		default:
+			c.notify(fmt.Sprintf("Gave %d× %s to %s", got, args[1], args[0]))
+		}
+	case "exp":
+		if len(args) != 2 {
+			c.notify("Usage: /exp <name> <amount>")
+			return true
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			c.notify("Usage: /exp <name> <amount>")
+			return true
+		}
+		ups, err := s.gameLoop.GiveEXP(args[0], n)
+		if err != nil {
+			c.notify("EXP failed: " + err.Error())
+			return true
+		}
+		c.notify(fmt.Sprintf("Gave %d EXP to %s (%d level(s) gained)", n, args[0], len(ups)))
+	case "spawn":
+		if len(args) == 0 {
+			c.notify("Usage: /spawn <name> [enemy id…]")
+			return true
+		}
+		if err := s.gameLoop.SpawnEncounter(args[0], args[1:]); err != nil {
+			c.notify("Spawn failed: " + err.Error())
+			return true
+		}
+		c.notify("Started a fight for " + args[0])
+	case "inspect":
+		if len(args) != 1 {
+			c.notify("Usage: /inspect <name>")
+			return true
+		}
+		lines, err := s.gameLoop.Inspect(args[0])
+		if err != nil {
+			c.notify("Inspect failed: " + err.Error())
+			return true
+		}
+		c.notify(lines...)
+	case "fights":
+		lines := s.gameLoop.Fights()
+		if len(lines) == 0 {
+			c.notify("No fights in progress")
+			return true
+		}
+		c.notify(lines...)
+	case "broadcast":
+		text := strings.TrimSpace(line[len("/broadcast"):])
+		if text == "" {
+			c.notify("Usage: /broadcast <message>")
+			return true
+		}
+		s.gameLoop.Broadcast(text)
+	case "reload":
+		relocated, err := s.gameLoop.ReloadMaps(s.opts.MapsDir)
+		if err != nil {
+			log.Printf("Map reload failed, keeping current maps: %v", err)
+			c.notify("Reload failed: " + err.Error())
+			return true
+		}
+		log.Printf("Maps reloaded from %s (%d player(s) moved to spawn)", s.opts.MapsDir, relocated)
+		c.notify(fmt.Sprintf("Maps reloaded (%d player(s) moved to spawn)", relocated))
+	}
+	return true
+}
+
+// adminTeleport handles both forms of /tp. Map names may contain spaces,
+// so the coordinates are taken from the end of the line.
+func (s *SSHServer) adminTeleport(c *client, args []string) {
+	const usage = "Usage: /tp <name> <map> <x> <y>  or  /tp <name> <other player>"
+	switch {
+	case len(args) == 2:
+		if err := s.gameLoop.TeleportToPlayer(args[0], args[1]); err != nil {
+			c.notify("Teleport failed: " + err.Error())
+			return
+		}
+		c.notify(fmt.Sprintf("Moved %s to %s", args[0], args[1]))
+	case len(args) >= 4:
+		n := len(args)
+		x, errX := strconv.Atoi(args[n-2])
+		y, errY := strconv.Atoi(args[n-1])
+		if errX != nil || errY != nil {
+			c.notify(usage)
+			return
+		}
+		mapName := strings.Join(args[1:n-2], " ")
+		if err := s.gameLoop.Teleport(args[0], mapName, x, y); err != nil {
+			c.notify("Teleport failed: " + err.Error())
+			return
+		}
+		c.notify(fmt.Sprintf("Moved %s to %s (%d,%d)", args[0], mapName, x, y))
+	default:
+		c.notify(usage)
+	}
+}
+
+// kickWhere disconnects every session match accepts and returns how many
+// there were.
+func (s *SSHServer) kickWhere(match func(*client) bool, reason string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for c := range s.clients {
+		if match(c) {
+			c.disconnect(reason)
+			n++
+		}
+	}
+	return n
+}
+
+// withReason formats an optional reason as a farewell suffix.
+func withReason(reason string) string {
+	if reason == "" {
+		return "."
+	}
+	return ": " + reason
+}
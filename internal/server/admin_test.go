@@ -0,0 +1,23 @@
+package server
+
+import "testing"
+
+func TestCaseVariantIsNotAdmin(t *testing.T) {
+	s := newTestServer(t, Options{Admins: []string{"alice"}})
+	owner, stranger := newKey(t), newKey(t)
+	alice, err := s.identify("alice", owner, testIP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.isAdmin(alice) {
+		t.Fatal("alice isn't an admin")
+	}
+
+	if _, err := s.identify("ALICE", stranger, testIP); err == nil {
+		t.Fatal("a different key logged in as ALICE")
+	}
+	variant := &client{name: "ALICE", fingerprint: fingerprint(stranger)}
+	if s.isAdmin(variant) {
+		t.Fatal("a session named ALICE without alice's key is an admin")
+	}
+}
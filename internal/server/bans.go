@@ -0,0 +1,154 @@
+package server
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"happy-place-2/internal/store"
+)
+
+// banList holds the names, key fingerprints and address ranges that may not
+// connect. Every change is written through to the store.
+type banList struct {
+	mu    sync.Mutex
+	store store.Store
+	bans  []store.Ban
+}
+
+// newBanList loads the saved bans from st.
+func newBanList(st store.Store) (*banList, error) {
+	bans, err := st.LoadBans()
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range bans {
+		if _, err := parseBanTarget(b.Value); err != nil {
+			return nil, fmt.Errorf("ban %q: %w", b.Value, err)
+		}
+	}
+	return &banList{store: st, bans: bans}, nil
+}
+
+// parseBanTarget works out what kind of ban a target names: a key
+// fingerprint ("SHA256:…"), an IP address or CIDR range, or else a name.
+// Addresses are stored in canonical form.
+func parseBanTarget(target string) (store.Ban, error) {
+	switch {
+	case target == "":
+		return store.Ban{}, fmt.Errorf("empty ban target")
+	case strings.HasPrefix(target, "SHA256:"):
+		return store.Ban{Kind: store.BanKey, Value: target}, nil
+	case strings.Contains(target, "/"):
+		prefix, err := netip.ParsePrefix(target)
+		if err != nil {
+			return store.Ban{}, fmt.Errorf("bad CIDR range: %w", err)
+		}
+		return store.Ban{Kind: store.BanIP, Value: prefix.Masked().String()}, nil
+	}
+	if addr, err := netip.ParseAddr(target); err == nil {
+		return store.Ban{Kind: store.BanIP, Value: addr.Unmap().String()}, nil
+	}
+	return store.Ban{Kind: store.BanName, Value: target}, nil
+}
+
+// banMatches reports whether b applies to a connection.
+func banMatches(b store.Ban, name, fp string, ip netip.Addr) bool {
+	switch b.Kind {
+	case store.BanName:
+		return name != "" && strings.EqualFold(b.Value, name)
+	case store.BanKey:
+		return fp != "" && b.Value == fp
+	case store.BanIP:
+		if !ip.IsValid() {
+			return false
+		}
+		if prefix, err := netip.ParsePrefix(b.Value); err == nil {
+			return prefix.Contains(ip)
+		}
+		addr, err := netip.ParseAddr(b.Value)
+		return err == nil && addr == ip
+	}
+	return false
+}
+
+// add bans target and saves the list. Banning something already banned
+// replaces the earlier entry's reason.
+func (bl *banList) add(target, reason, by string) (store.Ban, error) {
+	b, err := parseBanTarget(target)
+	if err != nil {
+		return store.Ban{}, err
+	}
+	b.Reason, b.By, b.At = reason, by, time.Now()
+
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+	bans := make([]store.Ban, 0, len(bl.bans)+1)
+	for _, old := range bl.bans {
+		if !sameBan(old, b) {
+			bans = append(bans, old)
+		}
+	}
+	bans = append(bans, b)
+	if err := bl.store.SaveBans(bans); err != nil {
+		return store.Ban{}, err
+	}
+	bl.bans = bans
+	return b, nil
+}
+
+// remove lifts the ban on target and saves the list. It reports whether
+// there was one.
+func (bl *banList) remove(target string) (bool, error) {
+	b, err := parseBanTarget(target)
+	if err != nil {
+		return false, err
+	}
+
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+	bans := make([]store.Ban, 0, len(bl.bans))
+	for _, old := range bl.bans {
+		if !sameBan(old, b) {
+			bans = append(bans, old)
+		}
+	}
+	if len(bans) == len(bl.bans) {
+		return false, nil
+	}
+	if err := bl.store.SaveBans(bans); err != nil {
+		return false, err
+	}
+	bl.bans = bans
+	return true, nil
+}
+
+// check returns the first ban that applies to a connection. Empty name,
+// fingerprint or an invalid address are skipped, so the address alone can
+// be checked before the handshake.
+func (bl *banList) check(name, fp string, ip netip.Addr) (store.Ban, bool) {
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+	for _, b := range bl.bans {
+		if banMatches(b, name, fp, ip) {
+			return b, true
+		}
+	}
+	return store.Ban{}, false
+}
+
+// list returns a copy of the current bans, oldest first.
+func (bl *banList) list() []store.Ban {
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+	return append([]store.Ban(nil), bl.bans...)
+}
+
+func sameBan(a, b store.Ban) bool {
+	if a.Kind == store.BanName && b.Kind == store.BanName {
+		return strings.EqualFold(a.Value, b.Value)
+	}
+	return a.Kind == b.Kind && a.Value == b.Value
+}
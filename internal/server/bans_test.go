@@ -0,0 +1,95 @@
+package server
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+
+	"happy-place-2/internal/store"
+)
+
+func TestBanMatches(t *testing.T) {
+	v4 := netip.MustParseAddr("192.0.2.7")
+	v6 := netip.MustParseAddr("2001:db8::7")
+	for _, c := range []struct {
+		target string
+		name   string
+		fp     string
+		ip     netip.Addr
+		want   bool
+	}{
+		{"192.0.2.0/24", "", "", v4, true},
+		{"192.0.2.0/24", "", "", netip.MustParseAddr("192.0.3.7"), false},
+		{"192.0.2.99/24", "", "", v4, true}, // stored masked
+		{"2001:db8::/32", "", "", v6, true},
+		{"2001:db8::/32", "", "", v4, false},
+		{"192.0.2.7", "", "", v4, true},
+		{"192.0.2.7", "", "", netip.MustParseAddr("192.0.2.8"), false},
+		{"::ffff:192.0.2.7", "", "", v4, true}, // mapped targets are unmapped
+		{"192.0.2.7", "", "", netip.Addr{}, false},
+		{"SHA256:abc", "", "SHA256:abc", v4, true},
+		{"SHA256:abc", "", "SHA256:abd", v4, false},
+		{"SHA256:abc", "", "", v4, false},
+		{"Mallory", "mallory", "", v4, true},
+		{"mallory", "MALLORY", "", v4, true},
+		{"mallory", "mal", "", v4, false},
+		{"mallory", "", "", v4, false},
+	} {
+		b, err := parseBanTarget(c.target)
+		if err != nil {
+			t.Fatalf("parse %q: %v", c.target, err)
+		}
+		if got := banMatches(b, c.name, c.fp, c.ip); got != c.want {
+			t.Errorf("ban %q against name %q key %q ip %v: got %v, want %v", c.target, c.name, c.fp, c.ip, got, c.want)
+		}
+	}
+
+	for _, bad := range []string{"", "192.0.2.0/33", "not/a/range"} {
+		if _, err := parseBanTarget(bad); err == nil {
+			t.Errorf("ban target %q was accepted", bad)
+		}
+	}
+}
+
+func TestBanListAddAndRemove(t *testing.T) {
+	st := store.NewMemoryStore()
+	bl, err := newBanList(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bl.add("Mallory", "spam", "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bl.add("mallory", "more spam", "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if bans := bl.list(); len(bans) != 1 || bans[0].Reason != "more spam" {
+		t.Fatalf("bans %v, want the one ban with the newer reason", bans)
+	}
+	if _, banned := bl.check("MALLORY", "", netip.Addr{}); !banned {
+		t.Fatal("MALLORY is not banned")
+	}
+
+	// The list is saved and loads again
+	again, err := newBanList(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, banned := again.check("mallory", "", netip.Addr{}); !banned {
+		t.Fatal("the ban didn't survive a reload")
+	}
+
+	if ok, err := bl.remove("MALLORY"); err != nil || !ok {
+		t.Fatalf("remove: %v %v", ok, err)
+	}
+	if _, banned := bl.check("mallory", "", netip.Addr{}); banned {
+		t.Fatal("mallory is still banned")
+	}
+}
+
+func TestRemoteIPUnmaps(t *testing.T) {
+	addr := &net.TCPAddr{IP: net.ParseIP("::ffff:192.0.2.7"), Port: 2222}
+	if got := remoteIP(addr); got != netip.MustParseAddr("192.0.2.7") {
+		t.Fatalf("remote IP %v, want the IPv4 address", got)
+	}
+}
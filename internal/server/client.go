@@ -0,0 +1,174 @@
+package server
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"happy-place-2/internal/game"
+	"happy-place-2/internal/render"
+)
+
+const (
+	maxNotices = 10
+	noticeTTL  = 10 * time.Second
+)
+
+// notice is a line of command output shown to a single session.
+type notice struct {
+	text string
+	at   time.Time
+}
+
+// client holds the per-connection state shared between a session's input
+// and render goroutines.
+type client struct {
+	name        string // account name (or guest display name)
+	character   string // name of the character played; differs from name for extra characters
+	guest       bool
+	admin       bool
+	fingerprint string // key used for this login; empty for guests
+	ip          netip.Addr
+	playerID    string
+	kick        chan string   // receives the reason when the session should close
+	done        chan struct{} // closed once the session has left the game
+
+	mu        sync.Mutex
+	input     inputReader
+	notices   []notice
+	joined    bool // playerID is set
+	leaving   bool // the session is ending on its own
+	handedOff bool // a new login took over the player
+}
+
+// join records the player this session controls.
+func (c *client) join(playerID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.playerID = playerID
+	c.joined = true
+}
+
+// handOff claims this session's player for a new login and returns its
+// ID. It fails if the session hasn't joined the game yet or is already
+// leaving, in which case the player will be saved and removed as usual.
+// A take-over that then fails must call cancelHandOff.
+func (c *client) handOff() (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.joined || c.leaving {
+		return "", false
+	}
+	c.handedOff = true
+	return c.playerID, true
+}
+
+// cancelHandOff gives the player back to the session after a take-over
+// failed. It reports true if the session has meanwhile left without
+// removing its player, which the caller must then remove.
+func (c *client) cancelHandOff() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handedOff = false
+	return c.leaving
+}
+
+// leave marks the session as ending and reports whether its player is
+// still its own to remove.
+func (c *client) leave() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.leaving = true
+	return !c.handedOff
+}
+
+// feed runs raw input through the prompt-aware reader.
+func (c *client) feed(data []byte) ([]game.Action, []string, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.input.feed(data)
+}
+
+// disconnect asks the session to close, showing reason on the way out.
+// Only the first request is kept.
+func (c *client) disconnect(reason string) {
+	select {
+	case c.kick <- reason:
+	default:
+	}
+}
+
+// notify queues lines of feedback for this session.
+func (c *client) notify(lines ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for _, l := range lines {
+		c.notices = append(c.notices, notice{text: l, at: now})
+	}
+	if len(c.notices) > maxNotices {
+		c.notices = c.notices[len(c.notices)-maxNotices:]
+	}
+}
+
+// ui snapshots the session-local interface state for the renderer.
+// inCombat picks the default chat channel shown in the prompt label.
+func (c *client) ui(inCombat bool) *render.SessionUI {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cutoff := time.Now().Add(-noticeTTL)
+	live := c.notices[:0]
+	for _, n := range c.notices {
+		if n.at.After(cutoff) {
+			live = append(live, n)
+		}
+	}
+	c.notices = live
+
+	ui := &render.SessionUI{
+		PromptActive: c.input.promptOpen,
+		Prompt:       string(c.input.line),
+	}
+	switch {
+	case strings.HasPrefix(ui.Prompt, "/"):
+		ui.PromptLabel = "> "
+	case inCombat:
+		ui.PromptLabel = "Party: "
+	default:
+		ui.PromptLabel = "Say: "
+	}
+	for _, n := range c.notices {
+		ui.Notices = append(ui.Notices, n.text)
+	}
+	return ui
+}
+
+// chatLines formats the viewer's chat scrollback for the renderer. Messages
+// younger than fade ticks are shown as fresh.
+func chatLines(msgs []game.ChatMessage, tick uint64, fade int) []render.ChatLine {
+	lines := make([]render.ChatLine, len(msgs))
+	for i, m := range msgs {
+		var text string
+		switch m.Channel {
+		case game.ChatGlobal:
+			text = fmt.Sprintf("[G] %s: %s", m.From, m.Text)
+		case game.ChatParty:
+			text = fmt.Sprintf("[P] %s: %s", m.From, m.Text)
+		case game.ChatWhisper:
+			text = fmt.Sprintf("%s → %s: %s", m.From, m.To, m.Text)
+		case game.ChatSystem:
+			text = m.Text
+		default:
+			text = fmt.Sprintf("%s: %s", m.From, m.Text)
+		}
+		lines[i] = render.ChatLine{
+			Kind:  int(m.Channel),
+			Text:  text,
+			Fresh: tick-m.Tick < uint64(fade),
+		}
+	}
+	return lines
+}
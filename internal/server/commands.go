@@ -0,0 +1,123 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"happy-place-2/internal/game"
+)
+
+// runLine handles a line submitted at the session prompt: slash commands
+// run here, everything else is chat.
+func (s *SSHServer) runLine(c *client, line string) {
+	if !strings.HasPrefix(line, "/") {
+		s.sendChat(c, line)
+		return
+	}
+	s.runCommand(c, line)
+}
+
+// sendChat forwards a chat line (optionally with a channel prefix such as
+// /g or /w name) to the game loop.
+func (s *SSHServer) sendChat(c *client, line string) {
+	select {
+	case s.gameLoop.InputChan() <- game.InputEvent{PlayerID: c.playerID, Action: game.ActionChat, Text: line}:
+	default:
+		c.notify("Server busy — message not sent.")
+	}
+}
+
+// runCommand executes a slash command typed at the session prompt and
+// reports the result back to the client as notices.
+func (s *SSHServer) runCommand(c *client, line string) {
+	fields := strings.Fields(strings.TrimPrefix(line, "/"))
+	if len(fields) == 0 {
+		return
+	}
+
+	switch strings.ToLower(fields[0]) {
+	case "help":
+		c.notify(
+			"T or /m <msg>          chat with players on this map",
+			"/g <msg>  /p <msg>     global chat, battle party chat",
+			"/w <name> <msg>        whisper to one player",
+			"/keys [add <pubkey> | revoke <n>]   manage your SSH keys",
+		)
+		if c.admin {
+			c.notify(adminHelp...)
+		}
+	case "g", "global", "m", "map", "p", "party", "w", "whisper":
+		s.sendChat(c, line)
+	case "keys":
+		s.runKeysCommand(c, fields[1:], line)
+	default:
+		if !c.admin || !s.runAdminCommand(c, fields, line) {
+			c.notify(fmt.Sprintf("Unknown command /%s — try /help", fields[0]))
+		}
+	}
+}
+
+// runKeysCommand handles /keys, /keys add and /keys revoke.
+func (s *SSHServer) runKeysCommand(c *client, args []string, line string) {
+	if c.guest {
+		c.notify("Guests have no account. Connect with an SSH key to claim a name.")
+		return
+	}
+
+	if len(args) == 0 {
+		keys, err := s.accounts.keys(c.name)
+		if err != nil {
+			c.notify("Could not load keys: " + err.Error())
+			return
+		}
+		for i, k := range keys {
+			suffix := ""
+			if k.Fingerprint == c.fingerprint {
+				suffix = " (this session)"
+			}
+			c.notify(fmt.Sprintf("%d. %s%s", i+1, k.Fingerprint, suffix))
+		}
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "add":
+		// Keep the raw remainder so the key's comment survives intact
+		idx := strings.Index(strings.ToLower(line), "add")
+		keyLine := strings.TrimSpace(line[idx+len("add"):])
+		if keyLine == "" {
+			c.notify("Usage: /keys add <type> <base64> [comment]")
+			return
+		}
+		ak, err := s.accounts.addKey(c.name, keyLine)
+		if err != nil {
+			c.notify("Could not add key: " + err.Error())
+			return
+		}
+		c.notify("Added key " + ak.Fingerprint)
+	case "revoke":
+		if len(args) != 2 {
+			c.notify("Usage: /keys revoke <n>")
+			return
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil {
+			c.notify("Usage: /keys revoke <n>")
+			return
+		}
+		ak, err := s.accounts.revokeKey(c.name, n)
+		if errors.Is(err, errLastKey) {
+			c.notify("That is your only key — add another before revoking it.")
+			return
+		}
+		if err != nil {
+			c.notify("Could not revoke key: " + err.Error())
+			return
+		}
+		c.notify("Revoked key " + ak.Fingerprint)
+	default:
+		c.notify("Usage: /keys [add <pubkey> | revoke <n>]")
+	}
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"happy-place-2/internal/game"
+)
+
+// maxPromptLen caps the command prompt so a runaway paste can't grow it
+// forever. It leaves room to paste an RSA-8192 public key, about 1.4 KB,
+// into /keys add.
+const maxPromptLen = 4096
+
+// inputReader turns raw terminal bytes into game actions. Pressing '/'
+// (command) or 'T' (chat) opens a line-editing prompt; until Enter or Esc,
+// bytes are collected as text instead of being parsed as actions.
+type inputReader struct {
+	promptOpen bool
+	line       []rune
+	overflow   bool // the line outgrew maxPromptLen
+}
+
+// opensPrompt reports whether b opens the prompt, and the text it starts with.
+func opensPrompt(b byte) (bool, []rune) {
+	switch b {
+	case '/':
+		return true, []rune{'/'}
+	case 't', 'T':
+		return true, nil
+	}
+	return false, nil
+}
+
+// feed consumes one read's worth of bytes. It returns the game actions parsed
+// outside the prompt and any lines submitted with Enter. A line that grew
+// past maxPromptLen isn't submitted, since running a cut-off command or key
+// would only fail in a confusing way; tooLong counts those instead.
+func (r *inputReader) feed(data []byte) (actions []game.Action, lines []string, tooLong int) {
+	i := 0
+	for i < len(data) {
+		if !r.promptOpen {
+			j := i
+			var initial []rune
+			for ; j < len(data); j++ {
+				var open bool
+				if open, initial = opensPrompt(data[j]); open {
+					break
+				}
+			}
+			actions = append(actions, parseInput(data[i:j])...)
+			if j < len(data) {
+				r.promptOpen = true
+				r.line = append(r.line[:0], initial...)
+			}
+			i = j + 1
+			continue
+		}
+
+		// Prompt mode: line editing
+		b := data[i]
+		switch {
+		case b == 0x1b && i+2 < len(data) && data[i+1] == '[':
+			i += 3 // ignore arrow keys
+			continue
+		case b == 0x1b, b == 3: // Esc or Ctrl-C cancels
+			r.close()
+		case b == '\r' || b == '\n':
+			if r.overflow {
+				tooLong++
+			} else if len(r.line) > 0 {
+				lines = append(lines, string(r.line))
+			}
+			r.close()
+		case b == 0x7f || b == 0x08: // Backspace on an empty line closes the prompt
+			if len(r.line) == 0 {
+				r.close()
+			} else {
+				r.line = r.line[:len(r.line)-1]
+			}
+		default:
+			ch, size := utf8.DecodeRune(data[i:])
+			if unicode.IsPrint(ch) {
+				if len(r.line) < maxPromptLen {
+					r.line = append(r.line, ch)
+				} else {
+					r.overflow = true
+				}
+			}
+			i += size
+			continue
+		}
+		i++
+	}
+	return actions, lines, tooLong
+}
+
+func (r *inputReader) close() {
+	r.promptOpen = false
+	r.line = r.line[:0]
+	r.overflow = false
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func TestKeysAddTakesAnRSAKey(t *testing.T) {
+	s := newTestServer(t, Options{})
+	c, err := s.identify("alice", newKey(t), testIP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ssh-keygen's default RSA key is 3072 bits, over 550 bytes as a line
+	priv, err := rsa.GenerateKey(rand.Reader, 3072)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := gossh.NewPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorized := strings.TrimSpace(string(gossh.MarshalAuthorizedKey(pub))) + " alice@laptop"
+
+	var r inputReader
+	_, lines, tooLong := r.feed([]byte("/keys add " + authorized + "\r"))
+	if tooLong != 0 || len(lines) != 1 {
+		t.Fatalf("got %d lines and %d too long, want the command", len(lines), tooLong)
+	}
+	fields := strings.Fields(strings.TrimPrefix(lines[0], "/"))
+	s.runKeysCommand(c, fields[1:], lines[0])
+
+	if n := len(c.notices); n == 0 || !strings.HasPrefix(c.notices[n-1].text, "Added key") {
+		t.Fatalf("notices %v, want the key added", c.notices)
+	}
+	keys, err := s.accounts.keys("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[1].Fingerprint != gossh.FingerprintSHA256(pub) {
+		t.Fatalf("keys %v, want the RSA key second", keys)
+	}
+}
+
+func TestOverlongPromptLineIsNotSent(t *testing.T) {
+	var r inputReader
+	_, lines, tooLong := r.feed([]byte("/keys add " + strings.Repeat("A", maxPromptLen) + "\r"))
+	if len(lines) != 0 || tooLong != 1 {
+		t.Fatalf("got lines %d and %d too long, want the line held back", len(lines), tooLong)
+	}
+
+	// The next line starts fresh
+	_, lines, tooLong = r.feed([]byte("thello\r"))
+	if len(lines) != 1 || lines[0] != "hello" || tooLong != 0 {
+		t.Fatalf("got %q and %d too long after an overlong line", lines, tooLong)
+	}
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// connLimiter caps how many connections one address may open within a
+// sliding window. It runs before the SSH handshake, so a client hammering
+// the port costs little more than an accept.
+type connLimiter struct {
+	mu        sync.Mutex
+	limit     int // 0 disables the limit
+	window    time.Duration
+	recent    map[netip.Addr][]time.Time
+	lastSweep time.Time
+}
+
+func newConnLimiter(limit int, window time.Duration) *connLimiter {
+	return &connLimiter{limit: limit, window: window, recent: make(map[netip.Addr][]time.Time)}
+}
+
+// allow records a connection attempt from ip and reports whether it is
+// within the limit. Refused attempts count too, so a client has to back
+// off for a full window before it gets in again.
+func (l *connLimiter) allow(ip netip.Addr, now time.Time) bool {
+	if l.limit <= 0 {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := now.Add(-l.window)
+	if now.Sub(l.lastSweep) > l.window {
+		// Forget addresses that have gone quiet
+		for addr, times := range l.recent {
+			if !times[len(times)-1].After(cutoff) {
+				delete(l.recent, addr)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	times := l.recent[ip]
+	live := times[:0]
+	for _, t := range times {
+		if t.After(cutoff) {
+			live = append(live, t)
+		}
+	}
+	if len(live) < l.limit*2 { // cap memory for a flooding client
+		live = append(live, now)
+	}
+	l.recent[ip] = live
+	return len(live) <= l.limit
+}
+
+// remoteIP extracts the address from a connection's remote endpoint.
+// IPv4-mapped IPv6 addresses are unmapped so bans and limits see one form.
+func remoteIP(addr net.Addr) netip.Addr {
+	ap, err := netip.ParseAddrPort(addr.String())
+	if err != nil {
+		return netip.Addr{}
+	}
+	return ap.Addr().Unmap()
+}
+
+// DuplicatePolicy decides what happens when an account that is already
+// playing logs in again.
+type DuplicatePolicy string
+
+const (
+	DuplicateKick   DuplicatePolicy = "kick"   // close the old session and let the new one in
+	DuplicateReject DuplicatePolicy = "reject" // turn the new session away
+	DuplicateAllow  DuplicatePolicy = "allow"  // play another character, saved as name#2, name#3…
+)
+
+// admit registers a session if the duplicate-login policy and the
+// server-wide, per-address and per-account limits allow it. Otherwise it
+// returns the message to show the player. Under DuplicateKick it also
+// returns the sessions the new one replaces; see takeOver. Sessions share
+// an account only if both proved a key for it (see sameAccount), so no
+// other login can kick a player or use up their session allowance.
+// Admins are exempt from the server-wide cap.
+func (s *SSHServer) admit(c *client) (string, []*client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var same []*client   // other sessions on this account
+	var guests []*client // other guests using this name
+	for other := range s.clients {
+		switch {
+		case c.guest && other.guest && strings.EqualFold(other.name, c.name):
+			guests = append(guests, other)
+		case !c.guest && sameAccount(other, c):
+			same = append(same, other)
+		}
+	}
+	c.character = c.name
+	if len(guests) > 0 {
+		// Guests prove nothing, so none can replace another: each plays a
+		// character of its own
+		c.character = freeCharacter(c.name, guests)
+	}
+	var replaced []*client
+	if len(same) > 0 {
+		switch s.opts.DuplicateLogin {
+		case DuplicateReject:
+			return fmt.Sprintf("%s is already playing. Log out there first, or wait a moment if that connection just dropped.", c.name), nil
+		case DuplicateAllow:
+			if n := s.opts.MaxSessionsPerAccount; n > 0 && len(same) >= n {
+				return fmt.Sprintf("%s is already playing in %d session(s), the most allowed. Close one and try again.", c.name, len(same)), nil
+			}
+			c.character = freeCharacter(c.name, same)
+		default:
+			replaced = same
+		}
+	}
+
+	if n := s.opts.MaxPlayers; n > 0 && len(s.clients)-len(replaced) >= n && !c.admin {
+		return fmt.Sprintf("Sorry, the server is full (%d players). Please try again in a little while.", n), nil
+	}
+	perIP := 0
+	for other := range s.clients {
+		if other.ip == c.ip && !slices.Contains(replaced, other) {
+			perIP++
+		}
+	}
+	if n := s.opts.MaxSessionsPerIP; n > 0 && perIP >= n {
+		return fmt.Sprintf("Too many sessions from your address (limit %d). Close one and try again.", n), nil
+	}
+	s.clients[c] = struct{}{}
+	return "", replaced
+}
+
+// freeCharacter picks the character name for another session on an
+// account: the account name itself if no session is playing it, otherwise
+// the lowest free name#N. '#' can't appear in login names, so these never
+// clash with another account.
+func freeCharacter(name string, sessions []*client) string {
+	taken := make(map[string]bool, len(sessions))
+	for _, c := range sessions {
+		taken[strings.ToLower(c.character)] = true
+	}
+	candidate := name
+	for n := 2; taken[strings.ToLower(candidate)]; n++ {
+		candidate = fmt.Sprintf("%s#%d", name, n)
+	}
+	return candidate
+}
+
+// release unregisters a session admitted by admit and signals anyone
+// waiting to replace it.
+func (s *SSHServer) release(c *client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.clients, c)
+	close(c.done)
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"net/netip"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestAdmitMatchesVerifiedAccounts(t *testing.T) {
+	s := newTestServer(t, Options{})
+	owner := newKey(t)
+	alice, err := s.identify("alice", owner, testIP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refusal, _ := s.admit(alice); refusal != "" {
+		t.Fatal(refusal)
+	}
+
+	// A session that only shares the name up to case replaces nobody
+	other := &client{name: "Alice", fingerprint: fingerprint(newKey(t)), ip: testIP, kick: make(chan string, 1), done: make(chan struct{})}
+	if refusal, replaced := s.admit(other); refusal != "" || len(replaced) != 0 {
+		t.Fatalf("refusal %q replaced %d, want a session of its own", refusal, len(replaced))
+	}
+
+	// The owner logging in again in another case replaces the first session
+	again, err := s.identify("ALICE", owner, testIP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, replaced := s.admit(again); len(replaced) != 1 || replaced[0] != alice {
+		t.Fatalf("replaced %v, want alice's session", replaced)
+	}
+}
+
+func TestGuestsWithOneNamePlaySideBySide(t *testing.T) {
+	s := newTestServer(t, Options{DuplicateLogin: DuplicateKick})
+	var characters []string
+	for range 3 {
+		c := &client{name: "guest-bob", guest: true, ip: testIP, kick: make(chan string, 1), done: make(chan struct{})}
+		if refusal, replaced := s.admit(c); refusal != "" || len(replaced) != 0 {
+			t.Fatalf("refusal %q replaced %d, want a guest of its own", refusal, len(replaced))
+		}
+		characters = append(characters, c.character)
+	}
+	if want := []string{"guest-bob", "guest-bob#2", "guest-bob#3"}; !slices.Equal(characters, want) {
+		t.Fatalf("characters %v, want %v", characters, want)
+	}
+}
+
+func TestConnLimiter(t *testing.T) {
+	l := newConnLimiter(2, time.Minute)
+	other := netip.MustParseAddr("192.0.2.2")
+	t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i, want := range []bool{true, true, false, false} {
+		if got := l.allow(testIP, t0.Add(time.Duration(i)*time.Second)); got != want {
+			t.Fatalf("attempt %d allowed %v, want %v", i+1, got, want)
+		}
+	}
+	// Another address has its own allowance
+	if !l.allow(other, t0) {
+		t.Fatal("a second address was refused")
+	}
+
+	// Refused attempts count, so the way clears a window after the last one
+	if l.allow(testIP, t0.Add(time.Minute+1500*time.Millisecond)) {
+		t.Fatal("allowed again before the refused attempts aged out")
+	}
+	if !l.allow(testIP, t0.Add(2*time.Minute+2*time.Second)) {
+		t.Fatal("still refused a full window after the last attempt")
+	}
+	// The quiet address was swept
+	if _, ok := l.recent[other]; ok {
+		t.Fatal("an address quiet for a window is still tracked")
+	}
+}
+
+func TestConnLimiterCapsMemory(t *testing.T) {
+	l := newConnLimiter(3, time.Minute)
+	t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 100; i++ {
+		l.allow(testIP, t0.Add(time.Duration(i)*time.Millisecond))
+	}
+	if n := len(l.recent[testIP]); n > 6 {
+		t.Fatalf("%d attempts remembered for one address, want at most 6", n)
+	}
+	if unlimited := newConnLimiter(0, time.Minute); !unlimited.allow(testIP, t0) || len(unlimited.recent) != 0 {
+		t.Fatal("a limit of 0 should let everyone in without tracking")
+	}
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Name limits keep player names readable in name tags, chat and the HUD.
+const (
+	minNameLen = 2
+	maxNameLen = 16
+)
+
+// reservedNames can't be used to log in, so nobody can pose as the server
+// or staff. Names starting with "guest" are reserved too, so accounts can't
+// be mistaken for guests.
+var reservedNames = map[string]bool{
+	"admin": true, "administrator": true, "mod": true, "moderator": true,
+	"server": true, "system": true, "root": true, "staff": true,
+	"all": true, "everyone": true, "anonymous": true, "nobody": true,
+}
+
+// NameFilter rejects names that are allowed by the charset and length rules
+// but unwanted anyway, such as profanity. The error is shown to the player.
+type NameFilter func(name string) error
+
+// validateName checks a login name: 2–16 ASCII letters, digits, '_' or '-',
+// starting with a letter.
+func validateName(name string) error {
+	if len(name) < minNameLen || len(name) > maxNameLen {
+		return fmt.Errorf("names must be %d–%d characters long", minNameLen, maxNameLen)
+	}
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && (r >= '0' && r <= '9' || r == '_' || r == '-'):
+		case i == 0:
+			return fmt.Errorf("names must start with a letter")
+		default:
+			return fmt.Errorf("names may only use letters, digits, '_' and '-'")
+		}
+	}
+	return nil
+}
+
+// reservedName reports whether an account may not use name. Guests may:
+// they always play as "guest-<name>".
+func reservedName(name string) bool {
+	lower := strings.ToLower(name)
+	return reservedNames[lower] || strings.HasPrefix(lower, "guest")
+}
+
+// checkName applies the name rules and filter to a login name. Guests skip
+// the reserved list.
+func (s *SSHServer) checkName(name string, guest bool) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+	if !guest && reservedName(name) {
+		return fmt.Errorf("%q is reserved", name)
+	}
+	if s.opts.NameFilter != nil {
+		return s.opts.NameFilter(name)
+	}
+	return nil
+}
+
+// LoadWordFilter reads a list of blocked words, one per line ('#' starts a
+// comment), and returns a filter rejecting any name that contains one.
+// Case, '_' and '-' are ignored when matching, so "Bad_Word" is caught too.
+func LoadWordFilter(path string) (NameFilter, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		if w := strings.ToLower(strings.TrimSpace(line)); w != "" {
+			words = append(words, w)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	filter := func(name string) error {
+		folded := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+		for _, w := range words {
+			if strings.Contains(folded, w) {
+				return fmt.Errorf("%q isn't allowed", name)
+			}
+		}
+		return nil
+	}
+	return filter, len(words), nil
+}
@@ -1,41 +1,118 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net"
+	"net/netip"
 	"sync"
+	"time"
 	"unicode/utf8"
 
 	"github.com/gliderlabs/ssh"
+	gossh "golang.org/x/crypto/ssh"
 
 	"happy-place-2/internal/game"
 	"happy-place-2/internal/render"
+	"happy-place-2/internal/store"
 )
 
 // SSHServer wraps the SSH listener and game loop integration.
 type SSHServer struct {
 	gameLoop *game.GameLoop
+	accounts *accountManager
 	addr     string
 	hostKey  string
+	opts     Options
+	bans     *banList
+	limiter  *connLimiter
+
+	mu      sync.Mutex
+	srv     *ssh.Server          // set once Start has configured the listener
+	clients map[*client]struct{} // admitted sessions
+}
+
+// Options configures the server beyond its address and host key. Zero
+// limits are disabled.
+type Options struct {
+	// Admins lists the account names and key fingerprints ("SHA256:…")
+	// allowed to use admin commands. Guests are never admins.
+	Admins []string
+	// MapsDir is where /reload reads maps from.
+	MapsDir string
+
+	// DuplicateLogin decides what happens when an account that is already
+	// playing logs in again. The zero value means DuplicateKick.
+	DuplicateLogin DuplicatePolicy
+	// NameFilter, if set, vets login names after the built-in rules.
+	NameFilter NameFilter
+
+	MaxPlayers            int // sessions server-wide; admins may exceed it
+	MaxSessionsPerIP      int // concurrent sessions from one address
+	MaxSessionsPerAccount int // characters on one account under DuplicateAllow
+	ConnRateLimit         int // new connections per address per ConnRateWindow
+	ConnRateWindow        time.Duration
 }
 
 // NewSSHServer creates a new SSH server bound to the given address.
-func NewSSHServer(addr string, hostKey string, gl *game.GameLoop) *SSHServer {
+// Accounts (name → registered keys) and the ban list are kept in st.
+func NewSSHServer(addr string, hostKey string, gl *game.GameLoop, st store.Store, opts Options) (*SSHServer, error) {
+	bans, err := newBanList(st)
+	if err != nil {
+		return nil, fmt.Errorf("load bans: %w", err)
+	}
 	return &SSHServer{
 		gameLoop: gl,
+		accounts: newAccountManager(st),
 		addr:     addr,
 		hostKey:  hostKey,
-	}
+		opts:     opts,
+		bans:     bans,
+		limiter:  newConnLimiter(opts.ConnRateLimit, opts.ConnRateWindow),
+		clients:  make(map[*client]struct{}),
+	}, nil
 }
 
-// Start begins listening for SSH connections.
+// Start begins listening for SSH connections. Blocks until the server is
+// shut down, in which case it returns nil.
 func (s *SSHServer) Start() error {
 	server := &ssh.Server{
 		Addr: s.addr,
 		Handler: func(sess ssh.Session) {
 			s.handleSession(sess)
 		},
+		// Banned and over-eager addresses are dropped before the handshake
+		ConnCallback: func(ctx ssh.Context, conn net.Conn) net.Conn {
+			ip := remoteIP(conn.RemoteAddr())
+			if b, banned := s.bans.check("", "", ip); banned {
+				log.Printf("Connection refused: %s is banned (%s)", ip, b.Value)
+				return nil
+			}
+			if !s.limiter.allow(ip, time.Now()) {
+				log.Printf("Connection refused: %s is connecting too often", ip)
+				return nil
+			}
+			return conn
+		},
+		// Key auth binds the login to an account. Any key is accepted for an
+		// unclaimed name; claimed names require one of their registered keys.
+		PublicKeyHandler: func(ctx ssh.Context, key ssh.PublicKey) bool {
+			return s.accounts.allows(ctx.User(), key)
+		},
+		// Password and keyboard-interactive logins always succeed as guests.
+		// Clear any key accepted during an earlier probe so the session can't
+		// inherit an identity it never proved.
+		PasswordHandler: func(ctx ssh.Context, _ string) bool {
+			ctx.SetValue(ssh.ContextKeyPublicKey, nil)
+			return true
+		},
+		KeyboardInteractiveHandler: func(ctx ssh.Context, _ gossh.KeyboardInteractiveChallenge) bool {
+			ctx.SetValue(ssh.ContextKeyPublicKey, nil)
+			return true
+		},
 	}
 
 	// Set host key
@@ -43,8 +120,61 @@ func (s *SSHServer) Start() error {
 		return fmt.Errorf("set host key: %w", err)
 	}
 
+	s.mu.Lock()
+	s.srv = server
+	s.mu.Unlock()
+
 	log.Printf("SSH server listening on %s", s.addr)
-	return server.ListenAndServe()
+	if err := server.ListenAndServe(); !errors.Is(err, ssh.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting new connections immediately, then waits for
+// active sessions to end. If ctx expires first, remaining connections are
+// closed forcibly.
+func (s *SSHServer) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	server := s.srv
+	s.mu.Unlock()
+	if server == nil {
+		return nil
+	}
+
+	err := server.Shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		log.Printf("SSH sessions did not close in time — forcing")
+		server.Close()
+	}
+	return err
+}
+
+// identify resolves who is logging in. A verified key binds the session to
+// its account, named as the account was first claimed whatever case the
+// login used; anything else is a guest.
+func (s *SSHServer) identify(username string, key ssh.PublicKey, ip netip.Addr) (*client, error) {
+	c := &client{
+		name: username,
+		ip:   ip,
+		kick: make(chan string, 1),
+		done: make(chan struct{}),
+	}
+	if key == nil {
+		c.guest = true
+		c.name = "guest-" + username
+		return c, nil
+	}
+	name, claimed, err := s.accounts.login(username, key)
+	if err != nil {
+		return nil, err
+	}
+	c.name = name
+	c.fingerprint = fingerprint(key)
+	if claimed {
+		log.Printf("Account claimed: %s (%s)", name, c.fingerprint)
+	}
+	return c, nil
 }
 
 func (s *SSHServer) handleSession(sess ssh.Session) {
@@ -59,14 +189,65 @@ func (s *SSHServer) handleSession(sess ssh.Session) {
 	if username == "" {
 		username = "Anonymous"
 	}
+	key := sess.PublicKey()
+	if err := s.checkName(username, key == nil); err != nil {
+		fmt.Fprintf(sess, "Sorry, you can't log in as %q: %v.\r\n", username, err)
+		log.Printf("Login rejected: name %q: %v", username, err)
+		return
+	}
 
-	// Register with game loop (username = identity)
-	playerID, renderCh := s.gameLoop.AddPlayer(username)
+	c, err := s.identify(username, key, remoteIP(sess.RemoteAddr()))
+	if err != nil {
+		fmt.Fprintf(sess, "Cannot log in as %s: %v\r\n", username, err)
+		log.Printf("Login rejected: %s: %v", username, err)
+		return
+	}
+	if b, banned := s.bans.check(c.name, c.fingerprint, c.ip); banned {
+		fmt.Fprintf(sess, "You are banned from this server%s\r\n", withReason(b.Reason))
+		log.Printf("Login rejected: %s is banned (%s)", c.name, b.Value)
+		return
+	}
+	c.admin = s.isAdmin(c)
+	refusal, replaced := s.admit(c)
+	if refusal != "" {
+		fmt.Fprintf(sess, "%s\r\n", refusal)
+		log.Printf("Login rejected: %s from %s: %s", c.name, c.ip, refusal)
+		return
+	}
+	defer s.release(c)
 
-	log.Printf("Player connected: %s (%s)", username, playerID)
+	// Take over the player of a replaced session, or join afresh once the
+	// old sessions have saved and gone
+	playerID, renderCh, resumed := s.takeOver(c, replaced)
+	if !resumed {
+		if !awaitClosed(replaced) {
+			fmt.Fprint(sess, "Your previous session is still closing — please try again in a moment.\r\n")
+			return
+		}
+		playerID, renderCh = s.gameLoop.AddPlayer(c.character, game.JoinOptions{Guest: c.guest, Admin: c.admin})
+	}
+	c.join(playerID)
+
+	if c.guest {
+		c.notify("Playing as a guest — progress will not be saved.",
+			"Connect with an SSH key to claim a name. Type /help for commands.")
+	}
+
+	switch {
+	case resumed:
+		log.Printf("Player reconnected: %s (%s)", c.character, playerID)
+	case c.admin:
+		log.Printf("Player connected: %s (%s, admin)", c.character, playerID)
+	default:
+		log.Printf("Player connected: %s (%s)", c.character, playerID)
+	}
 	defer func() {
+		if !c.leave() {
+			log.Printf("Session handed over: %s (%s)", c.character, playerID)
+			return
+		}
 		s.gameLoop.RemovePlayer(playerID)
-		log.Printf("Player disconnected: %s (%s)", username, playerID)
+		log.Printf("Player disconnected: %s (%s)", c.character, playerID)
 	}()
 
 	// Terminal dimensions
@@ -75,15 +256,20 @@ func (s *SSHServer) handleSession(sess ssh.Session) {
 	var termMu sync.Mutex
 
 	// Create renderer
-	engine := render.NewEngine(termW, termH)
+	timing := s.gameLoop.Timing()
+	engine := render.NewEngine(termW, termH, tileAnim(timing))
 
 	// Setup terminal
 	io.WriteString(sess, render.EnableAltScreen())
 	io.WriteString(sess, render.HideCursor())
 	io.WriteString(sess, render.ClearScreen())
+	farewell := ""
 	defer func() {
 		io.WriteString(sess, render.ShowCursor())
 		io.WriteString(sess, render.DisableAltScreen())
+		if farewell != "" {
+			io.WriteString(sess, farewell+"\r\n")
+		}
 	}()
 
 	inputCh := s.gameLoop.InputChan()
@@ -98,7 +284,13 @@ func (s *SSHServer) handleSession(sess ssh.Session) {
 				close(quitCh)
 				return
 			}
-			actions := parseInput(buf[:n])
+			actions, lines, tooLong := c.feed(buf[:n])
+			if tooLong > 0 {
+				c.notify(fmt.Sprintf("That line was over %d characters and was not sent.", maxPromptLen))
+			}
+			for _, line := range lines {
+				s.runLine(c, line)
+			}
 			for _, action := range actions {
 				if action == game.ActionQuit {
 					close(quitCh)
@@ -127,8 +319,14 @@ func (s *SSHServer) handleSession(sess ssh.Session) {
 		select {
 		case <-quitCh:
 			return
+		case reason := <-c.kick:
+			farewell = reason
+			log.Printf("Session closed: %s (%s): %s", c.character, playerID, reason)
+			return
 		case state, ok := <-renderCh:
 			if !ok {
+				// The game loop only closes a live channel when draining
+				farewell = "The server is restarting. Your progress has been saved — see you soon!"
 				return
 			}
 
@@ -158,8 +356,17 @@ func (s *SSHServer) handleSession(sess ssh.Session) {
 					MaxMP:     p.MaxMP,
 					EXP:       p.EXP,
 					Level:     p.Level,
+					EXPInLevel:   p.EXPInLevel,
+					EXPToNext:    p.EXPToNext,
+					StatPoints:   p.StatPoints,
+					LevelUpFlash: p.LevelUpFlash,
+					RegenHP:      p.Regen.HP,
+					RegenStamina: p.Regen.Stamina,
+					RegenMP:      p.Regen.MP,
+					Speech:    p.Speech,
 					InCombat:  p.FightID != 0,
 					CombatTransition: p.CombatTransition,
+					Fade:      p.Fade,
 				}
 				if p.ID == playerID && p.ActiveInteraction != nil {
 					pi.ActiveInteraction = &render.InteractionPopup{
@@ -171,6 +378,11 @@ func (s *SSHServer) handleSession(sess ssh.Session) {
 				players[i] = pi
 			}
 
+			npcs := make([]render.NPCInfo, len(state.Map.NPCs))
+			for i, n := range state.Map.NPCs {
+				npcs[i] = render.NPCInfo{ID: n.ID, Name: n.Name, X: n.X, Y: n.Y, Dir: int(n.Dir), Color: n.Color}
+			}
+
 			// Convert combat state if present
 			var combatData *render.CombatRenderData
 			if state.Combat != nil {
@@ -183,6 +395,8 @@ func (s *SSHServer) handleSession(sess ssh.Session) {
 						MaxHP: e.MaxHP,
 						ID:    e.ID,
 						Alive: e.Alive,
+						Art:   e.Art,
+						Color: e.Color,
 					}
 				}
 				cPlayers := make([]render.CombatPlayer, len(c.Players))
@@ -195,6 +409,7 @@ func (s *SSHServer) handleSession(sess ssh.Session) {
 						Alive:    cp.Alive,
 						Color:    cp.Color,
 						IsViewer: cp.IsViewer,
+						Away:     cp.Away,
 					}
 				}
 				combatData = &render.CombatRenderData{
@@ -209,10 +424,78 @@ func (s *SSHServer) handleSession(sess ssh.Session) {
 					Transitioning: c.Transitioning,
 					ViewerAction:  c.ViewerAction,
 					ViewerTarget:  c.ViewerTarget,
+					EXPGained:     c.EXPGained,
+					LevelUps:      c.LevelUps,
 				}
 			}
 
-			output := engine.Render(playerID, state.Map.Map, players, w, h, state.World.Tick, state.World.TotalPlayers, combatData)
+			ui := c.ui(state.Combat != nil)
+			ui.Chat = chatLines(state.Chat, state.World.Tick, timing.ChatFadeTime)
+			if state.World.ShutdownTicks > 0 {
+				secs := timing.Secs(state.World.ShutdownTicks)
+				ui.Banner = fmt.Sprintf("Server restarting in %ds — your progress will be saved", secs)
+			}
+
+			var panels render.Panels
+			if inv := state.Inventory; inv != nil {
+				items := make([]render.InventoryItem, len(inv.Items))
+				for i, it := range inv.Items {
+					items[i] = render.InventoryItem{
+						Name:        it.Name,
+						Description: it.Description,
+						Qty:         it.Qty,
+						Usable:      it.Usable,
+						Slot:        it.Slot,
+						Bonus:       it.Bonus,
+					}
+				}
+				panels.Inventory = &render.InventoryPanel{
+					Items:    items,
+					Cursor:   inv.Cursor,
+					Slots:    game.InventorySlots,
+					InCombat: inv.InCombat,
+				}
+			}
+			if d := state.Dialogue; d != nil {
+				panels.Dialogue = &render.DialogueBox{Name: d.Name, Color: d.Color, Text: d.Text, Choices: d.Choices}
+			}
+			if js := state.Journal; js != nil {
+				quests := make([]render.JournalQuest, len(js.Quests))
+				for i, q := range js.Quests {
+					objs := make([]render.JournalObjective, len(q.Objectives))
+					for k, o := range q.Objectives {
+						objs[k] = render.JournalObjective{Text: o.Text, Done: o.Done}
+					}
+					quests[i] = render.JournalQuest{Name: q.Name, Description: q.Description, Done: q.Done, Objectives: objs}
+				}
+				panels.Journal = &render.JournalPanel{Quests: quests, Cursor: js.Cursor}
+			}
+			if cs := state.Character; cs != nil {
+				slots := make([]render.EquipSlotInfo, len(cs.Slots))
+				for i, sl := range cs.Slots {
+					slots[i] = render.EquipSlotInfo{Slot: sl.Slot, Name: sl.Name, Bonus: sl.Bonus}
+				}
+				stats := make([]render.StatRow, len(cs.Stats))
+				for i, st := range cs.Stats {
+					stats[i] = render.StatRow{Label: st.Label, Value: st.Value, Gain: st.Gain}
+				}
+				panels.Character = &render.CharacterSheet{
+					Name:       cs.Name,
+					Level:      cs.Level,
+					Slots:      slots,
+					Stats:      stats,
+					StatPoints: cs.StatPoints,
+					Cursor:     cs.Cursor,
+					Attack:     cs.Attack,
+					Defense:    cs.Defense,
+					Melee:      cs.Melee,
+					Ranged:     cs.Ranged,
+					Magic:      cs.Magic,
+					Guard:      cs.Guard,
+				}
+			}
+
+			output := engine.Render(playerID, state.Map.Map, players, npcs, w, h, state.World.Tick, state.World.TotalPlayers, combatData, panels, ui)
 			if len(output) > 0 {
 				io.WriteString(sess, render.SyncStart+output+render.SyncEnd)
 			}
@@ -220,6 +503,19 @@ func (s *SSHServer) handleSession(sess ssh.Session) {
 	}
 }
 
+// tileAnim takes the tile animation intervals from the game's timing model.
+func tileAnim(t *game.Timing) render.TileAnim {
+	return render.TileAnim{
+		Grass:          t.GrassAnimInterval,
+		TallGrass:      t.TallGrassAnimInterval,
+		Sand:           t.SandAnimInterval,
+		Water:          t.WaterAnimInterval,
+		WaterShimmer:   t.WaterShimmerInterval,
+		Shallow:        t.ShallowAnimInterval,
+		ShallowShimmer: t.ShallowShimmerInterval,
+	}
+}
+
 // parseInput converts raw bytes into player actions.
 // Handles WASD, arrow key escape sequences, Q, and Ctrl-C.
 func parseInput(data []byte) []game.Action {
@@ -267,6 +563,14 @@ func parseInput(data []byte) []game.Action {
 			actions = append(actions, game.ActionDebugPage3)
 		case '4':
 			actions = append(actions, game.ActionDefend)
+		case '5':
+			actions = append(actions, game.ActionItem)
+		case 'i', 'I':
+			actions = append(actions, game.ActionInventory)
+		case 'c', 'C':
+			actions = append(actions, game.ActionCharacter)
+		case 'j', 'J':
+			actions = append(actions, game.ActionJournal)
 		case '\r', '\n': // Enter key
 			actions = append(actions, game.ActionConfirm)
 		case 3: // Ctrl-C
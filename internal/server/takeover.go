@@ -0,0 +1,69 @@
+package server
+
+import (
+	"log"
+	"time"
+
+	"happy-place-2/internal/game"
+)
+
+// replaceTimeout bounds how long a new login waits for the sessions it
+// replaces to save and close.
+const replaceTimeout = 5 * time.Second
+
+// takeOver closes the sessions a new login replaces. If one of them is in
+// the game on the same account, its live player moves to c with position, fight and all
+// other state intact, so a flaky connection doesn't drop anyone out of a
+// fight. ok is false when nothing could be taken over; the caller should
+// then wait for the replaced sessions with awaitClosed and join afresh.
+func (s *SSHServer) takeOver(c *client, replaced []*client) (playerID string, ch game.RenderChan, ok bool) {
+	for _, old := range replaced {
+		if ok {
+			break
+		}
+		if !sameAccount(old, c) {
+			continue
+		}
+		id, claimed := old.handOff()
+		if !claimed {
+			continue
+		}
+		ch, ok = s.gameLoop.TakeOver(id, game.JoinOptions{Guest: c.guest, Admin: c.admin})
+		if !ok {
+			// The player is already gone from the loop; make sure the old
+			// session's cleanup still runs
+			if old.cancelHandOff() {
+				s.gameLoop.RemovePlayer(id)
+			}
+			continue
+		}
+		playerID = id
+		c.character = old.character
+		log.Printf("Session of %s (%s) taken over by a new login from %s", old.character, id, c.ip)
+	}
+	for _, old := range replaced {
+		old.disconnect("You logged in from somewhere else.")
+	}
+	return playerID, ch, ok
+}
+
+// sameAccount reports whether two sessions logged in to the same account
+// with verified keys. Only then may one take over the other's player.
+func sameAccount(a, b *client) bool {
+	return !a.guest && !b.guest && a.fingerprint != "" && b.fingerprint != "" && a.name == b.name
+}
+
+// awaitClosed waits for replaced sessions to leave the game, so their
+// progress is saved before it is loaded again. It reports false if one
+// doesn't finish within replaceTimeout.
+func awaitClosed(replaced []*client) bool {
+	timeout := time.After(replaceTimeout)
+	for _, old := range replaced {
+		select {
+		case <-old.done:
+		case <-timeout:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"testing"
+
+	"happy-place-2/internal/game"
+	"happy-place-2/internal/maps"
+	"happy-place-2/internal/store"
+)
+
+func TestFailedTakeOverLeavesThePlayerToItsSession(t *testing.T) {
+	world := game.NewWorld(map[string]*maps.Map{"Default": maps.DefaultMap()}, nil, nil, nil, nil, "Default")
+	loop := game.NewGameLoop(world, store.NewMemoryStore(), game.LoopOptions{})
+	go loop.Run()
+	defer loop.Stop()
+
+	s := newTestServer(t, Options{})
+	s.gameLoop = loop
+	owner := newKey(t)
+	old, err := s.identify("alice", owner, testIP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := s.identify("alice", owner, testIP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The old session's player has already left the loop
+	old.join("alice")
+	if _, _, ok := s.takeOver(c, []*client{old}); ok {
+		t.Fatal("took over a player that isn't in the game")
+	}
+	if !old.leave() {
+		t.Fatal("the old session no longer removes its own player")
+	}
+}
+
+func TestCancelHandOffAfterLeaving(t *testing.T) {
+	c := &client{}
+	c.join("alice")
+	if _, ok := c.handOff(); !ok {
+		t.Fatal("hand-off refused")
+	}
+	if c.leave() {
+		t.Fatal("session still owns a handed-off player")
+	}
+	if !c.cancelHandOff() {
+		t.Fatal("cancelling after the session left didn't ask for a remove")
+	}
+}
@@ -0,0 +1,222 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// FileStore keeps one JSON file per player under <dir>/players, one per
+// account under <dir>/accounts, and the ban list in <dir>/bans.json.
+// Every write goes to a temp file that is synced and renamed into place,
+// so a crash mid-save never leaves a truncated record behind.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore opens (creating if needed) a file-backed store rooted at dir.
+func NewFileStore(dir string) (*FileStore, error) {
+	for _, sub := range []string{"players", "accounts"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, fmt.Errorf("create store directory: %w", err)
+		}
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// LoadPlayer reads and migrates the record for name.
+func (s *FileStore) LoadPlayer(name string) (*PlayerRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.playerPath(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read player %q: %w", name, err)
+	}
+	rec, err := decodePlayer(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode player %q: %w", name, err)
+	}
+	return rec, nil
+}
+
+// SavePlayer atomically writes the record at the current schema version.
+func (s *FileStore) SavePlayer(rec *PlayerRecord) error {
+	out := *rec
+	out.Version = CurrentVersion
+	data, err := json.MarshalIndent(&out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode player %q: %w", rec.Name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := writeFileAtomic(s.playerPath(rec.Name), data); err != nil {
+		return fmt.Errorf("write player %q: %w", rec.Name, err)
+	}
+	return nil
+}
+
+// LoadAccount reads the account for name, ignoring case.
+func (s *FileStore) LoadAccount(name string) (*Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.accountPath(name))
+	if errors.Is(err, os.ErrNotExist) {
+		data, err = s.readLegacyAccount(name)
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read account %q: %w", name, err)
+	}
+	var acc Account
+	if err := json.Unmarshal(data, &acc); err != nil {
+		return nil, fmt.Errorf("decode account %q: %w", name, err)
+	}
+	return &acc, nil
+}
+
+// SaveAccount atomically writes the account under its case-folded name.
+func (s *FileStore) SaveAccount(acc *Account) error {
+	data, err := json.MarshalIndent(acc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode account %q: %w", acc.Name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := writeFileAtomic(s.accountPath(acc.Name), data); err != nil {
+		return fmt.Errorf("write account %q: %w", acc.Name, err)
+	}
+	if legacy, ok := s.legacyAccountPath(acc.Name); ok {
+		os.Remove(legacy) // superseded by the file just written
+	}
+	return nil
+}
+
+// readLegacyAccount reads an account file saved before account names were
+// case-folded, which is named with the spelling the account was claimed
+// with.
+func (s *FileStore) readLegacyAccount(name string) ([]byte, error) {
+	path, ok := s.legacyAccountPath(name)
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return os.ReadFile(path)
+}
+
+// legacyAccountPath finds an account file for name whose file name isn't
+// case-folded.
+func (s *FileStore) legacyAccountPath(name string) (string, bool) {
+	entries, err := os.ReadDir(filepath.Join(s.dir, "accounts"))
+	if err != nil {
+		return "", false
+	}
+	want := fileName(name) + ".json"
+	folded := filepath.Base(s.accountPath(name))
+	for _, e := range entries {
+		if e.Name() != folded && strings.EqualFold(e.Name(), want) {
+			return filepath.Join(s.dir, "accounts", e.Name()), true
+		}
+	}
+	return "", false
+}
+
+// LoadBans reads the ban list. A missing file means no bans.
+func (s *FileStore) LoadBans() ([]Ban, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.bansPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read bans: %w", err)
+	}
+	var bans []Ban
+	if err := json.Unmarshal(data, &bans); err != nil {
+		return nil, fmt.Errorf("decode bans: %w", err)
+	}
+	return bans, nil
+}
+
+// SaveBans atomically writes the ban list.
+func (s *FileStore) SaveBans(bans []Ban) error {
+	if bans == nil {
+		bans = []Ban{}
+	}
+	data, err := json.MarshalIndent(bans, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode bans: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := writeFileAtomic(s.bansPath(), data); err != nil {
+		return fmt.Errorf("write bans: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) playerPath(name string) string {
+	return filepath.Join(s.dir, "players", fileName(name)+".json")
+}
+
+func (s *FileStore) accountPath(name string) string {
+	return filepath.Join(s.dir, "accounts", fileName(strings.ToLower(name))+".json")
+}
+
+func (s *FileStore) bansPath() string {
+	return filepath.Join(s.dir, "bans.json")
+}
+
+// fileName escapes a player name into a safe file name. Letters, digits,
+// '-' and '_' pass through; everything else becomes %XX.
+func fileName(name string) string {
+	var sb strings.Builder
+	for _, b := range []byte(name) {
+		switch {
+		case b >= 'a' && b <= 'z', b >= 'A' && b <= 'Z', b >= '0' && b <= '9', b == '-', b == '_':
+			sb.WriteByte(b)
+		default:
+			fmt.Fprintf(&sb, "%%%02X", b)
+		}
+	}
+	return sb.String()
+}
+
+// writeFileAtomic writes data to a temp file beside path, syncs it, and
+// renames it over path.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, path)
+}
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// playerMigrations upgrades a raw record from version N to N+1, keyed by N.
+// Migrations operate on the decoded JSON object so they can rename or
+// reinterpret fields that no longer exist on PlayerRecord.
+var playerMigrations = map[int]func(raw map[string]any) error{
+	1: migrateLevels,
+}
+
+// migrateLevels upgrades v1 records, whose level was derived from EXP and
+// never changed any stats. Their stats are level 1 stats, so the record
+// starts at level 1 and the game applies the levels its EXP has earned.
+func migrateLevels(raw map[string]any) error {
+	raw["level"] = 1
+	return nil
+}
+
+// decodePlayer parses a saved record, running any migrations needed to bring
+// it up to CurrentVersion.
+func decodePlayer(data []byte) (*PlayerRecord, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	version := 0
+	if v, ok := raw["version"].(float64); ok {
+		version = int(v)
+	}
+	if version > CurrentVersion {
+		return nil, fmt.Errorf("record version %d is newer than supported %d", version, CurrentVersion)
+	}
+	for version < CurrentVersion {
+		migrate, ok := playerMigrations[version]
+		if !ok {
+			return nil, fmt.Errorf("no migration from version %d", version)
+		}
+		if err := migrate(raw); err != nil {
+			return nil, fmt.Errorf("migrate from version %d: %w", version, err)
+		}
+		version++
+		raw["version"] = version
+	}
+
+	migrated, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var rec PlayerRecord
+	if err := json.Unmarshal(migrated, &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
@@ -0,0 +1,204 @@
+package store
+
+import (
+	"errors"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CurrentVersion is the player record schema version written by this build.
+// Bump it whenever the meaning of a persisted field changes, and register a
+// migration from the previous version in playerMigrations.
+const CurrentVersion = 2
+
+// ErrNotFound is returned when no record exists for the requested name.
+var ErrNotFound = errors.New("record not found")
+
+// PlayerRecord is the persisted state of a single character.
+type PlayerRecord struct {
+	Version int       `json:"version"`
+	Name    string    `json:"name"`
+	SavedAt time.Time `json:"saved_at"`
+
+	MapName string `json:"map"`
+	X       int    `json:"x"`
+	Y       int    `json:"y"`
+	Color   int    `json:"color"`
+
+	HP         int `json:"hp"`
+	MaxHP      int `json:"max_hp"`
+	Stamina    int `json:"stamina"`
+	MaxStamina int `json:"max_stamina"`
+	MP         int `json:"mp"`
+	MaxMP      int `json:"max_mp"`
+	Attack     int `json:"attack"`
+	Defense    int `json:"defense"`
+	EXP        int `json:"exp"`
+	Level      int `json:"level"`                 // levels whose stat growth is included above
+	StatPoints int `json:"stat_points,omitempty"` // unspent
+
+	Inventory []ItemRecord      `json:"inventory,omitempty"`
+	Equipment map[string]string `json:"equipment,omitempty"` // slot name → item ID
+	Flags     []string          `json:"flags,omitempty"`     // story flags that are set
+	Quests    []QuestRecord     `json:"quests,omitempty"`    // taken quests, in the order they were started
+}
+
+// ItemRecord is one persisted inventory stack.
+type ItemRecord struct {
+	ID  string `json:"id"`
+	Qty int    `json:"qty"`
+}
+
+// QuestRecord is the persisted progress on one quest.
+type QuestRecord struct {
+	ID    string `json:"id"`
+	Stage int    `json:"stage,omitempty"` // index of the current objective
+	Count int    `json:"count,omitempty"` // kills toward the current objective
+	Done  bool   `json:"done,omitempty"`
+}
+
+// Account binds a character name to the SSH keys allowed to play it.
+// Account names are case-insensitive: "Alice" and "alice" are the same
+// account, and Name keeps the spelling it was first claimed with.
+type Account struct {
+	Name      string       `json:"name"`
+	CreatedAt time.Time    `json:"created_at"`
+	Keys      []AccountKey `json:"keys"`
+}
+
+// AccountKey is one registered public key.
+type AccountKey struct {
+	Fingerprint string    `json:"fingerprint"` // SHA256:... as printed by ssh-keygen -l
+	PublicKey   string    `json:"public_key"`  // authorized_keys format
+	AddedAt     time.Time `json:"added_at"`
+}
+
+// HasKey reports whether the fingerprint is registered on the account.
+func (a *Account) HasKey(fingerprint string) bool {
+	for _, k := range a.Keys {
+		if k.Fingerprint == fingerprint {
+			return true
+		}
+	}
+	return false
+}
+
+// Ban kinds.
+const (
+	BanName = "name" // an account or guest name, compared case-insensitively
+	BanKey  = "key"  // a key fingerprint
+	BanIP   = "ip"   // a single address or a CIDR range
+)
+
+// Ban bars a name, key or address range from connecting.
+type Ban struct {
+	Kind   string    `json:"kind"`
+	Value  string    `json:"value"`
+	Reason string    `json:"reason,omitempty"`
+	By     string    `json:"by,omitempty"` // admin who added it
+	At     time.Time `json:"at"`
+}
+
+// Store persists player records and accounts between sessions and server restarts.
+type Store interface {
+	// LoadPlayer returns the saved record for name, or ErrNotFound.
+	LoadPlayer(name string) (*PlayerRecord, error)
+	// SavePlayer writes the record, replacing any previous save.
+	SavePlayer(rec *PlayerRecord) error
+
+	// LoadAccount returns the account for name, ignoring case, or
+	// ErrNotFound.
+	LoadAccount(name string) (*Account, error)
+	// SaveAccount writes the account, replacing any previous version
+	// under any spelling of its name.
+	SaveAccount(acc *Account) error
+
+	// LoadBans returns the saved ban list, empty if none was saved.
+	LoadBans() ([]Ban, error)
+	// SaveBans replaces the saved ban list.
+	SaveBans(bans []Ban) error
+}
+
+// MemoryStore is an in-process Store. Nothing survives a restart; it is
+// used as a fallback when no data directory is available.
+type MemoryStore struct {
+	mu       sync.Mutex
+	players  map[string]PlayerRecord
+	accounts map[string]Account
+	bans     []Ban
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		players:  make(map[string]PlayerRecord),
+		accounts: make(map[string]Account),
+	}
+}
+
+// LoadPlayer returns a copy of the stored record.
+func (s *MemoryStore) LoadPlayer(name string) (*PlayerRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.players[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	rec.Inventory = append([]ItemRecord(nil), rec.Inventory...)
+	rec.Equipment = maps.Clone(rec.Equipment)
+	rec.Flags = append([]string(nil), rec.Flags...)
+	rec.Quests = append([]QuestRecord(nil), rec.Quests...)
+	return &rec, nil
+}
+
+// SavePlayer stores a copy of the record.
+func (s *MemoryStore) SavePlayer(rec *PlayerRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cp := *rec
+	cp.Inventory = append([]ItemRecord(nil), rec.Inventory...)
+	cp.Equipment = maps.Clone(rec.Equipment)
+	cp.Flags = append([]string(nil), rec.Flags...)
+	cp.Quests = append([]QuestRecord(nil), rec.Quests...)
+	s.players[rec.Name] = cp
+	return nil
+}
+
+// LoadAccount returns a copy of the stored account.
+func (s *MemoryStore) LoadAccount(name string) (*Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	acc, ok := s.accounts[strings.ToLower(name)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	acc.Keys = append([]AccountKey(nil), acc.Keys...)
+	return &acc, nil
+}
+
+// SaveAccount stores a copy of the account.
+func (s *MemoryStore) SaveAccount(acc *Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cp := *acc
+	cp.Keys = append([]AccountKey(nil), acc.Keys...)
+	s.accounts[strings.ToLower(acc.Name)] = cp
+	return nil
+}
+
+// LoadBans returns a copy of the stored ban list.
+func (s *MemoryStore) LoadBans() ([]Ban, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Ban(nil), s.bans...), nil
+}
+
+// SaveBans stores a copy of the ban list.
+func (s *MemoryStore) SaveBans(bans []Ban) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bans = append([]Ban(nil), bans...)
+	return nil
+}